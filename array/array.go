@@ -1,43 +1,73 @@
 package array
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"sort"
+	"sync/atomic"
 
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/iterator"
 	"github.com/lazybabe/gods/internal/rwmutex"
 )
 
+var _ gods.Collection[int] = (*Array[int])(nil)
+
+// Array is an array of items of type T, which supports all the options, see gods.Option.
 type Array[T comparable] struct {
 	mu    rwmutex.RWMutex
 	array []T
+	// Copy of array published to the readers in copy-on-write usage.
+	view       atomic.Pointer[[]T]
+	comparator func(a, b T) int
+	hooks      gods.Hooks[T]
+	// Whether iterating over a snapshot of array without holding the lock.
+	snapshot bool
 }
 
 // New creates and returns an empty array.
-// The parameter `safe` is used to specify whether using array in concurrent-safety,
-// which is false in default.
-func New[T comparable](safe ...bool) *Array[T] {
-	return NewSize[T](0, 0, safe...)
+// The parameter `options` is used to configure the array, see gods.Option.
+// It also takes the former `safe` bool, which is deprecated in favor of gods.WithSafe.
+// It is not concurrent-safe in default.
+func New[T comparable](options ...any) *Array[T] {
+	return NewSize[T](0, 0, options...)
 }
 
 // NewSize create and returns an array with given size and cap.
-// The parameter `safe` is used to specify whether using array in concurrent-safety,
-// which is false in default.
-func NewSize[T comparable](size int, cap int, safe ...bool) *Array[T] {
-	return &Array[T]{
-		mu:    rwmutex.Create(safe...),
-		array: make([]T, size, cap),
-	}
+// The parameter `options` is used to configure the array, see gods.Option.
+// It also takes the former `safe` bool, which is deprecated in favor of gods.WithSafe.
+// It is not concurrent-safe in default.
+func NewSize[T comparable](size int, cap int, options ...any) *Array[T] {
+	return NewFrom(make([]T, size, cap), options...)
 }
 
 // NewFrom creates and returns an array with given slice `array`.
-// The parameter `safe` is used to specify whether using array in concurrent-safety,
-// which is false in default.
-func NewFrom[T comparable](array []T, safe ...bool) *Array[T] {
-	return &Array[T]{
-		mu:    rwmutex.Create(safe...),
-		array: array,
+// The parameter `options` is used to configure the array, see gods.Option.
+// It also takes the former `safe` bool, which is deprecated in favor of gods.WithSafe.
+// It is not concurrent-safe in default.
+func NewFrom[T comparable](array []T, options ...any) *Array[T] {
+	return newFrom(array, rwmutex.OptionsOf("array.Array", options))
+}
+
+// newFrom creates and returns an array with given slice `array` configured by `options`.
+func newFrom[T comparable](array []T, options []gods.Option) *Array[T] {
+	o := gods.NewOptions(options...)
+	if o.Capacity > cap(array) {
+		grown := make([]T, len(array), o.Capacity)
+		copy(grown, array)
+		array = grown
 	}
+	a := &Array[T]{
+		mu:         rwmutex.CreateWith(o),
+		array:      array,
+		comparator: gods.ComparatorOf[T](o),
+		hooks:      gods.HooksOf[T](o),
+		snapshot:   o.Snapshot,
+	}
+	a.publish()
+	return a
 }
 
 // Index returns the value by the specified index.
@@ -50,29 +80,72 @@ func (a *Array[T]) Index(index int) T {
 // Get returns the value by the specified index.
 // If the given `index` is out of range of the array, the `found` is false.
 func (a *Array[T]) Get(index int) (value T, found bool) {
-	a.mu.RLock()
+	a.mu.Count("Get")
+	array := a.rlock()
 	defer a.mu.RUnlock()
-	if index < 0 || index >= len(a.array) {
+	if index < 0 || index >= len(array) {
 		return
 	}
-	return a.array[index], true
+	return array[index], true
+}
+
+// GetCtx is like Get, but it returns ctx.Err() if `ctx` is done before the array is locked.
+func (a *Array[T]) GetCtx(ctx context.Context, index int) (value T, found bool, err error) {
+	a.mu.Count("GetCtx")
+	array, err := a.rlockContext(ctx)
+	if err != nil {
+		return value, false, err
+	}
+	defer a.mu.RUnlock()
+	if index < 0 || index >= len(array) {
+		return value, false, nil
+	}
+	return array[index], true, nil
 }
 
 // Set sets value to specified index.
 func (a *Array[T]) Set(index int, value T) error {
+	a.mu.Count("Set")
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	defer a.unlock()
+	return a.doSetWithoutLock(index, value)
+}
+
+// SetCtx is like Set, but it returns ctx.Err() if `ctx` is done before the array is locked.
+func (a *Array[T]) SetCtx(ctx context.Context, index int, value T) error {
+	a.mu.Count("SetCtx")
+	if err := a.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer a.unlock()
+	return a.doSetWithoutLock(index, value)
+}
+
+// doSetWithoutLock sets value to specified index without lock.
+func (a *Array[T]) doSetWithoutLock(index int, value T) error {
 	if index < 0 || index >= len(a.array) {
 		return fmt.Errorf("index %d out of array range %d", index, len(a.array))
 	}
+	a.removed(a.array[index])
 	a.array[index] = value
+	a.inserted(value)
 	return nil
 }
 
-// SortFunc sorts the array by custom function `less`.
+// Sort sorts the array by custom function `less`.
+// If `less` is nil, it sorts the array by the comparator given by gods.WithComparator,
+// or leaves the array unchanged if there is no comparator.
 func (a *Array[T]) Sort(less func(v1, v2 T) bool) {
+	a.mu.Count("Sort")
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	defer a.unlock()
+	if less == nil {
+		if a.comparator == nil {
+			return
+		}
+		comparator := a.comparator
+		less = func(v1, v2 T) bool { return comparator(v1, v2) < 0 }
+	}
 	sort.Slice(a.array, func(i, j int) bool {
 		return less(a.array[i], a.array[j])
 	})
@@ -80,35 +153,40 @@ func (a *Array[T]) Sort(less func(v1, v2 T) bool) {
 
 // InsertBefore inserts the `value` to the front of `index`.
 func (a *Array[T]) InsertBefore(index int, value T) error {
+	a.mu.Count("InsertBefore")
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	defer a.unlock()
 	if index < 0 || index >= len(a.array) {
 		return fmt.Errorf("index %d out of array range %d", index, len(a.array))
 	}
 	rear := append([]T{}, a.array[index:]...)
 	a.array = append(a.array[0:index], value)
 	a.array = append(a.array, rear...)
+	a.inserted(value)
 	return nil
 }
 
 // InsertAfter inserts the `value` to the back of `index`.
 func (a *Array[T]) InsertAfter(index int, value T) error {
+	a.mu.Count("InsertAfter")
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	defer a.unlock()
 	if index < 0 || index >= len(a.array) {
 		return fmt.Errorf("index %d out of array range %d", index, len(a.array))
 	}
 	rear := append([]T{}, a.array[index+1:]...)
 	a.array = append(a.array[0:index+1], value)
 	a.array = append(a.array, rear...)
+	a.inserted(value)
 	return nil
 }
 
 // Remove removes an item by index.
 // If the given `index` is out of range of the array, the `found` is false.
 func (a *Array[T]) Remove(index int) (value T, found bool) {
+	a.mu.Count("Remove")
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	defer a.unlock()
 	return a.doRemoveWithoutLock(index)
 }
 
@@ -118,20 +196,18 @@ func (a *Array[T]) doRemoveWithoutLock(index int) (value T, found bool) {
 		return value, false
 	}
 	// Determine array boundaries when deleting to improve deletion efficiency.
+	value = a.array[index]
 	if index == 0 {
-		value := a.array[0]
 		a.array = a.array[1:]
-		return value, true
 	} else if index == len(a.array)-1 {
-		value := a.array[index]
 		a.array = a.array[:index]
-		return value, true
+	} else {
+		// If it is a non-boundary delete,
+		// it will involve the creation of an array,
+		// then the deletion is less efficient.
+		a.array = append(a.array[:index], a.array[index+1:]...)
 	}
-	// If it is a non-boundary delete,
-	// it will involve the creation of an array,
-	// then the deletion is less efficient.
-	value = a.array[index]
-	a.array = append(a.array[:index], a.array[index+1:]...)
+	a.removed(value)
 	return value, true
 }
 
@@ -146,74 +222,127 @@ func (a *Array[T]) RemoveValue(value T) bool {
 }
 
 // PushLeft pushes one or multiple items to the beginning of array.
+// The given `value` slice is copied, so it is never aliased or grown by the array.
 func (a *Array[T]) PushLeft(value ...T) *Array[T] {
+	a.mu.Count("PushLeft")
 	a.mu.Lock()
-	defer a.mu.Unlock()
-	a.array = append(value, a.array...)
+	defer a.unlock()
+	a.doPushLeftWithoutLock(value...)
 	return a
 }
 
+// PushLeftCtx is like PushLeft, but it returns ctx.Err() if `ctx` is done before the array is locked.
+func (a *Array[T]) PushLeftCtx(ctx context.Context, value ...T) error {
+	a.mu.Count("PushLeftCtx")
+	if err := a.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer a.unlock()
+	a.doPushLeftWithoutLock(value...)
+	return nil
+}
+
+// doPushLeftWithoutLock pushes items to the beginning of array without lock.
+func (a *Array[T]) doPushLeftWithoutLock(value ...T) {
+	array := make([]T, len(value)+len(a.array))
+	copy(array, value)
+	copy(array[len(value):], a.array)
+	a.array = array
+	a.inserted(value...)
+}
+
 // PushRight pushes one or multiple items to the end of array.
 // It equals to Append.
 func (a *Array[T]) PushRight(value ...T) *Array[T] {
+	a.mu.Count("PushRight")
 	a.mu.Lock()
-	defer a.mu.Unlock()
-	a.array = append(a.array, value...)
+	defer a.unlock()
+	a.doPushRightWithoutLock(value...)
 	return a
 }
 
+// PushRightCtx is like PushRight, but it returns ctx.Err() if `ctx` is done before the array is locked.
+func (a *Array[T]) PushRightCtx(ctx context.Context, value ...T) error {
+	a.mu.Count("PushRightCtx")
+	if err := a.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer a.unlock()
+	a.doPushRightWithoutLock(value...)
+	return nil
+}
+
+// doPushRightWithoutLock pushes items to the end of array without lock.
+func (a *Array[T]) doPushRightWithoutLock(value ...T) {
+	a.array = append(a.array, value...)
+	a.inserted(value...)
+}
+
 // PopLeft pops and returns an item from the beginning of array.
 // Note that if the array is empty, the `found` is false.
 func (a *Array[T]) PopLeft() (value T, found bool) {
+	a.mu.Count("PopLeft")
 	a.mu.Lock()
-	defer a.mu.Unlock()
-	if len(a.array) == 0 {
-		return value, false
+	defer a.unlock()
+	return a.doRemoveWithoutLock(0)
+}
+
+// PopLeftCtx is like PopLeft, but it returns ctx.Err() if `ctx` is done before the array is locked.
+func (a *Array[T]) PopLeftCtx(ctx context.Context) (value T, found bool, err error) {
+	a.mu.Count("PopLeftCtx")
+	if err = a.mu.LockContext(ctx); err != nil {
+		return value, false, err
 	}
-	value = a.array[0]
-	a.array = a.array[1:]
-	return value, true
+	defer a.unlock()
+	value, found = a.doRemoveWithoutLock(0)
+	return value, found, nil
 }
 
 // PopRight pops and returns an item from the end of array.
 // Note that if the array is empty, the `found` is false.
 func (a *Array[T]) PopRight() (value T, found bool) {
+	a.mu.Count("PopRight")
 	a.mu.Lock()
-	defer a.mu.Unlock()
-	index := len(a.array) - 1
-	if index < 0 {
-		return value, false
+	defer a.unlock()
+	return a.doRemoveWithoutLock(len(a.array) - 1)
+}
+
+// PopRightCtx is like PopRight, but it returns ctx.Err() if `ctx` is done before the array is locked.
+func (a *Array[T]) PopRightCtx(ctx context.Context) (value T, found bool, err error) {
+	a.mu.Count("PopRightCtx")
+	if err = a.mu.LockContext(ctx); err != nil {
+		return value, false, err
 	}
-	value = a.array[index]
-	a.array = a.array[:index]
-	return value, true
+	defer a.unlock()
+	value, found = a.doRemoveWithoutLock(len(a.array) - 1)
+	return value, found, nil
 }
 
-// SubSlice returns a slice of elements from the array as specified
-// by the `offset` and `size` parameters.
-// If in concurrent safe usage, it returns a copy of the slice; else a pointer.
+// SubSlice returns a copy of the elements from the array as specified
+// by the `offset` and `length` parameters.
 //
 // If offset is non-negative, the sequence will start at that offset in the array.
 // If offset is negative, the sequence will start that far from the end of the array.
 //
 // If length is given and is positive, then the sequence will have up to that many elements in it.
 // If the array is shorter than the length, then only the available array elements will be present.
-// If length is given and is negative then the sequence will stop that many elements from the end of the array.
+// If length is given and is negative, then the sequence will have the -length elements before the offset.
 // If it is omitted, then the sequence will have everything from offset up until the end of the array.
 //
-// Any possibility crossing the left border of array, it will fail.
+// Any possibility crossing the left border of array, it will fail and return nil.
 func (a *Array[T]) SubSlice(offset int, length ...int) []T {
-	a.mu.RLock()
+	a.mu.Count("SubSlice")
+	array := a.rlock()
 	defer a.mu.RUnlock()
-	size := len(a.array)
+	size := len(array)
 	if len(length) > 0 {
 		size = length[0]
 	}
-	if offset > len(a.array) {
+	if offset > len(array) {
 		return nil
 	}
 	if offset < 0 {
-		offset = len(a.array) + offset
+		offset = len(array) + offset
 		if offset < 0 {
 			return nil
 		}
@@ -225,11 +354,11 @@ func (a *Array[T]) SubSlice(offset int, length ...int) []T {
 			return nil
 		}
 	}
-	if offset+size > len(a.array) {
-		size = len(a.array) - offset
+	if size > len(array)-offset {
+		size = len(array) - offset
 	}
 	s := make([]T, size)
-	copy(s, a.array[offset:])
+	copy(s, array[offset:])
 	return s
 }
 
@@ -241,41 +370,61 @@ func (a *Array[T]) Append(value ...T) *Array[T] {
 
 // Size returns the length of array.
 func (a *Array[T]) Size() int {
-	a.mu.RLock()
+	a.mu.Count("Size")
+	array := a.rlock()
 	defer a.mu.RUnlock()
-	return len(a.array)
+	return len(array)
+}
+
+// IsEmpty checks whether the array is empty.
+func (a *Array[T]) IsEmpty() bool {
+	return a.Size() == 0
 }
 
 // Slice returns the underlying data of array.
 // Note that, if it's in concurrent-safe usage, it returns a copy of underlying data,
 // or else a pointer to the underlying data.
 func (a *Array[T]) Slice() []T {
-	a.mu.RLock()
+	a.mu.Count("Slice")
+	array := a.rlock()
 	defer a.mu.RUnlock()
-	array := make([]T, len(a.array))
-	copy(array, a.array)
-	return array
+	items := make([]T, len(array))
+	copy(items, array)
+	return items
 }
 
 // Clone returns a new array, which is a copy of current array.
 func (a *Array[T]) Clone() (newArray *Array[T]) {
-	a.mu.RLock()
+	a.mu.Count("Clone")
+	array := a.rlock()
 	defer a.mu.RUnlock()
-	array := make([]T, len(a.array))
-	copy(array, a.array)
-	return NewFrom(array, a.mu.IsSafe())
+	items := make([]T, len(array))
+	copy(items, array)
+	return newFrom(items, a.options())
 }
 
 // Clear deletes all items of current array.
 func (a *Array[T]) Clear() *Array[T] {
+	a.mu.Count("Clear")
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	defer a.unlock()
 	if len(a.array) > 0 {
+		a.removed(a.array...)
 		a.array = make([]T, 0)
 	}
 	return a
 }
 
+// Reset deletes all items of the array like Clear, it implements gods.Container.
+func (a *Array[T]) Reset() {
+	a.Clear()
+}
+
+// Copy returns a new array like Clone, it implements gods.Container.
+func (a *Array[T]) Copy() gods.Container[T] {
+	return a.Clone()
+}
+
 // Contains checks whether a value exists in the array.
 func (a *Array[T]) Contains(value T) bool {
 	return a.Search(value) != -1
@@ -284,10 +433,11 @@ func (a *Array[T]) Contains(value T) bool {
 // Search searches array by `value`, returns the index of `value`,
 // or returns -1 if not exists.
 func (a *Array[T]) Search(value T) int {
-	a.mu.RLock()
+	a.mu.Count("Search")
+	array := a.rlock()
 	defer a.mu.RUnlock()
 	result := -1
-	for index, v := range a.array {
+	for index, v := range array {
 		if v == value {
 			result = index
 			break
@@ -299,13 +449,15 @@ func (a *Array[T]) Search(value T) int {
 // Unique uniques the array, clear repeated items.
 // Example: [2, 3, 1, 2, 1, 4] -> [2, 3, 1, 4]
 func (a *Array[T]) Unique() *Array[T] {
+	a.mu.Count("Unique")
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	defer a.unlock()
 	result := make([]T, 0, len(a.array))
 	seen := make(map[T]struct{}, len(a.array))
 	for i := 0; i < len(a.array); i++ {
 		item := a.array[i]
 		if _, ok := seen[item]; ok {
+			a.removed(item)
 			continue
 		}
 		seen[item] = struct{}{}
@@ -316,49 +468,56 @@ func (a *Array[T]) Unique() *Array[T] {
 }
 
 // Fill fills an array with num entries of the value `value`,
-// keys starting at the `startIndex` parameter.
+// keys starting at the `startIndex` parameter. It does nothing if num is not positive.
 func (a *Array[T]) Fill(startIndex int, num int, value T) error {
+	a.mu.Count("Fill")
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	defer a.unlock()
 	if startIndex < 0 || startIndex > len(a.array) {
 		return fmt.Errorf("index %d out of array range %d", startIndex, len(a.array))
 	}
+	if num > math.MaxInt-startIndex {
+		return fmt.Errorf("num %d out of range from index %d", num, startIndex)
+	}
 	for i := startIndex; i < startIndex+num; i++ {
 		if i > len(a.array)-1 {
 			a.array = append(a.array, value)
 		} else {
+			a.removed(a.array[i])
 			a.array[i] = value
 		}
+		a.inserted(value)
 	}
 	return nil
 }
 
 // Chunk returns an array of elements split into groups the length of size.
 // If array can't be split evenly, the final chunk will be the remaining elements.
+// The chunks are copies, which never alias the array.
 func (a *Array[T]) Chunk(size int) [][]T {
+	a.mu.Count("Chunk")
 	if size < 1 {
 		return nil
 	}
-	a.mu.RLock()
+	array := a.rlock()
 	defer a.mu.RUnlock()
-	length := len(a.array)
-	chunks := int(math.Ceil(float64(length) / float64(size)))
 	var result [][]T
-	for i, end := 0, 0; chunks > 0; chunks-- {
-		end = (i + 1) * size
-		if end > length {
-			end = length
+	for len(array) > 0 {
+		n := size
+		if n > len(array) {
+			n = len(array)
 		}
-		result = append(result, a.array[i*size:end])
-		i++
+		result = append(result, append([]T(nil), array[:n]...))
+		array = array[n:]
 	}
 	return result
 }
 
 // Reverse makes array with elements in reverse order.
 func (a *Array[T]) Reverse() *Array[T] {
+	a.mu.Count("Reverse")
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	defer a.unlock()
 	for i, j := 0, len(a.array)-1; i < j; i, j = i+1, j-1 {
 		a.array[i], a.array[j] = a.array[j], a.array[i]
 	}
@@ -367,19 +526,144 @@ func (a *Array[T]) Reverse() *Array[T] {
 
 // Each calls 'fn' on every item in the array in ascending order.
 // If `f` returns true, then it continues iterating; or false to stop.
+//
+// The array is locked for reading while `f` runs, so `f` must not modify the array,
+// unless the array is created with gods.WithSnapshot(true) or in copy-on-write usage,
+// where `f` runs without holding the lock over the items as of the moment Each is called.
 func (a *Array[T]) Each(f func(k int, v T) bool) {
-	a.mu.RLock()
+	a.mu.Count("Each")
+	if a.snapshot || a.mu.IsCopyOnWrite() {
+		for k, v := range a.snapshotItems() {
+			if !f(k, v) {
+				break
+			}
+		}
+		return
+	}
+	array := a.rlock()
 	defer a.mu.RUnlock()
-	for k, v := range a.array {
+	for k, v := range array {
 		if !f(k, v) {
 			break
 		}
 	}
 }
 
+// Iterator returns an iterator over the items of the array in ascending order,
+// which are the ones as of the moment Iterator is called.
+func (a *Array[T]) Iterator() gods.Iterator[T] {
+	a.mu.Count("Iterator")
+	return iterator.New(a.snapshotItems())
+}
+
+// ReverseIterator returns an iterator over the items of the array in descending order,
+// which are the ones as of the moment ReverseIterator is called.
+func (a *Array[T]) ReverseIterator() gods.ReverseIterator[T] {
+	a.mu.Count("ReverseIterator")
+	return iterator.NewReverse(a.snapshotItems())
+}
+
+// Stats returns a snapshot of the metrics of the array created with gods.WithMetrics(true).
+func (a *Array[T]) Stats() gods.Stats {
+	return a.mu.Stats()
+}
+
 // String returns current array as a string, which implements like json.Marshal does.
 func (a *Array[T]) String() string {
 	out := make([]string, 0, a.Size())
 	a.Each(func(_ int, v T) bool { out = append(out, fmt.Sprintf(`%v`, v)); return true })
 	return fmt.Sprintf("%v", out)
 }
+
+// MarshalJSON implements the interface MarshalJSON for json.Marshal.
+func (a *Array[T]) MarshalJSON() ([]byte, error) {
+	array := a.rlock()
+	defer a.mu.RUnlock()
+	return json.Marshal(array)
+}
+
+// UnmarshalJSON implements the interface UnmarshalJSON for json.Unmarshal,
+// which replaces all items of the array with the decoded ones.
+func (a *Array[T]) UnmarshalJSON(b []byte) error {
+	var array []T
+	if err := json.Unmarshal(b, &array); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.unlock()
+	a.removed(a.array...)
+	a.array = array
+	a.inserted(array...)
+	return nil
+}
+
+// rlock locks the array for reading, and returns the items to read.
+// In copy-on-write usage, it returns the items published by the last writer without locking.
+func (a *Array[T]) rlock() []T {
+	a.mu.RLock()
+	if a.mu.IsCopyOnWrite() {
+		return *a.view.Load()
+	}
+	return a.array
+}
+
+// rlockContext is like rlock, but it returns ctx.Err() if `ctx` is done before the array is locked.
+func (a *Array[T]) rlockContext(ctx context.Context) ([]T, error) {
+	if err := a.mu.RLockContext(ctx); err != nil {
+		return nil, err
+	}
+	if a.mu.IsCopyOnWrite() {
+		return *a.view.Load(), nil
+	}
+	return a.array, nil
+}
+
+// snapshotItems returns the items as of now, which are never modified by later writes.
+// In copy-on-write usage, it returns the published items without copying.
+func (a *Array[T]) snapshotItems() []T {
+	array := a.rlock()
+	defer a.mu.RUnlock()
+	if a.mu.IsCopyOnWrite() {
+		return array
+	}
+	return append([]T(nil), array...)
+}
+
+// unlock publishes the items in copy-on-write usage, and unlocks the array for writing.
+func (a *Array[T]) unlock() {
+	a.publish()
+	a.mu.Unlock()
+}
+
+// publish publishes a copy of the items to the readers in copy-on-write usage.
+func (a *Array[T]) publish() {
+	if a.mu.IsCopyOnWrite() {
+		view := make([]T, len(a.array))
+		copy(view, a.array)
+		a.view.Store(&view)
+	}
+}
+
+// options returns the options which configure a copy of the array.
+func (a *Array[T]) options() []gods.Option {
+	return append(a.mu.Options(),
+		gods.WithComparator(a.comparator), gods.WithHooks(a.hooks), gods.WithSnapshot(a.snapshot))
+}
+
+// inserted calls the OnInsert hook with every item of `values`.
+func (a *Array[T]) inserted(values ...T) {
+	if a.hooks.OnInsert != nil {
+		for _, v := range values {
+			a.hooks.OnInsert(v)
+		}
+	}
+}
+
+// removed calls the OnRemove hook with every item of `values`.
+func (a *Array[T]) removed(values ...T) {
+	if a.hooks.OnRemove != nil {
+		for _, v := range values {
+			a.hooks.OnRemove(v)
+		}
+	}
+}
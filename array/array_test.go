@@ -1,12 +1,19 @@
 package array_test
 
 import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sync"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/lazybabe/gods"
 	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/containertest"
 )
 
 func TestArray(t *testing.T) {
@@ -78,6 +85,9 @@ var _ = Describe("Array", func() {
 		a2 := array.NewFrom([]string{"c", "a", "b", "a"})
 		a2.Sort(func(v1, v2 string) bool { return v1 < v2 })
 		Expect(a2.Slice()).To(Equal([]string{"a", "a", "b", "c"}))
+		a3 := array.NewFrom([]int{3, 1, 2})
+		a3.Sort(nil)
+		Expect(a3.Slice()).To(Equal([]int{3, 1, 2}))
 	})
 
 	It("InsertBefore", func() {
@@ -143,6 +153,15 @@ var _ = Describe("Array", func() {
 		a := array.NewFrom([]int{1, 2, 3})
 		Expect(a.PushLeft(0).Slice()).To(Equal([]int{0, 1, 2, 3}))
 		Expect(a.PushLeft(-1).Slice()).To(Equal([]int{-1, 0, 1, 2, 3}))
+
+		values := make([]int, 2, 8)
+		values[0], values[1] = -3, -2
+		a.PushLeft(values...)
+		Expect(a.Slice()).To(Equal([]int{-3, -2, -1, 0, 1, 2, 3}))
+		Expect(values).To(Equal([]int{-3, -2}))
+		Expect(values[:cap(values)][2:]).To(Equal(make([]int, 6)))
+		values[0] = 100
+		Expect(a.Index(0)).To(Equal(-3))
 	})
 
 	It("PushRight|Append", func() {
@@ -273,4 +292,358 @@ var _ = Describe("Array", func() {
 		Expect(array.NewFrom([]int{1, 2, 3}).String()).To(Equal(`[1 2 3]`))
 		Expect(array.NewFrom([]string{"c", "b", "a"}).String()).To(Equal(`[c b a]`))
 	})
+
+	DescribeTable("Locker",
+		func(locker gods.Locker) {
+			a := array.New[int](gods.WithLocker(locker))
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						a.PushRight(i)
+						a.Size()
+						a.Contains(i)
+						a.Each(func(_, _ int) bool { return true })
+					}
+				}(i)
+			}
+			wg.Wait()
+			Expect(a.Size()).To(Equal(800))
+
+			clone := a.Clone()
+			Expect(clone.Set(0, 100)).To(Succeed())
+			Expect(clone.Index(0)).To(Equal(100))
+			Expect(a.Index(0)).NotTo(Equal(100))
+			Expect(a.Clear().Size()).To(BeZero())
+			Expect(a.PushLeft(1, 2).Slice()).To(Equal([]int{1, 2}))
+		},
+		Entry("mutex", gods.LockMutex),
+		Entry("rwmutex", gods.LockRWMutex),
+		Entry("spin", gods.LockSpin),
+		Entry("copy-on-write", gods.LockCopyOnWrite),
+	)
+
+	It("Copy-on-write readers", func() {
+		a := array.NewFrom([]int{1, 2, 3}, gods.WithLocker(gods.LockCopyOnWrite))
+		var seen []int
+		a.Each(func(_, v int) bool {
+			// Readers never block, so writing while iterating doesn't deadlock,
+			// and the iteration keeps reading the items published before.
+			a.PushRight(v)
+			seen = append(seen, v)
+			return true
+		})
+		Expect(seen).To(Equal([]int{1, 2, 3}))
+		Expect(a.Slice()).To(Equal([]int{1, 2, 3, 1, 2, 3}))
+		chunks := a.Chunk(4)
+		a.Reverse()
+		Expect(chunks).To(Equal([][]int{{1, 2, 3, 1}, {2, 3}}))
+	})
+
+	It("Options", func() {
+		a1 := array.New[int](gods.WithCapacity(16), gods.WithSafe(true))
+		Expect(a1.Size()).To(BeZero())
+		Expect(a1.PushRight(1).Slice()).To(Equal([]int{1}))
+		Expect(array.NewSize[int](2, 2, gods.WithCapacity(8)).Slice()).To(Equal([]int{0, 0}))
+
+		a2 := array.NewFrom([]int{3, 1, 2}, gods.WithComparator(func(a, b int) int { return b - a }))
+		a2.Sort(nil)
+		Expect(a2.Slice()).To(Equal([]int{3, 2, 1}))
+		clone := a2.Clone()
+		clone.PushRight(4)
+		clone.Sort(nil)
+		Expect(clone.Slice()).To(Equal([]int{4, 3, 2, 1}))
+	})
+
+	It("Hooks", func() {
+		var inserted, removed []int
+		a := array.NewFrom([]int{1, 2}, gods.WithHooks(gods.Hooks[int]{
+			OnInsert: func(v int) { inserted = append(inserted, v) },
+			OnRemove: func(v int) { removed = append(removed, v) },
+		}))
+		a.PushRight(3, 4)
+		a.PushLeft(0)
+		Expect(a.InsertBefore(0, -1)).To(Succeed())
+		Expect(a.InsertAfter(0, -2)).To(Succeed())
+		Expect(a.Set(0, 5)).To(Succeed())
+		Expect(inserted).To(Equal([]int{3, 4, 0, -1, -2, 5}))
+		Expect(removed).To(Equal([]int{-1}))
+
+		inserted, removed = nil, nil
+		a.PopLeft()
+		a.PopRight()
+		a.Remove(1)
+		a.RemoveValue(2)
+		Expect(removed).To(Equal([]int{5, 4, 0, 2}))
+		Expect(a.Slice()).To(Equal([]int{-2, 1, 3}))
+
+		removed = nil
+		Expect(a.Fill(2, 2, 1)).To(Succeed())
+		Expect(inserted).To(Equal([]int{1, 1}))
+		a.Unique()
+		Expect(removed).To(Equal([]int{3, 1, 1}))
+		a.Clear()
+		Expect(removed).To(Equal([]int{3, 1, 1, -2, 1}))
+		Expect(a.Clone().PushRight(6).Size()).To(Equal(1))
+		Expect(inserted).To(Equal([]int{1, 1, 6}))
+	})
+
+	It("Stats", func() {
+		a := array.New[int](gods.WithSafe(true), gods.WithMetrics(true))
+		a.PushRight(1, 2)
+		a.Contains(2)
+		a.Index(0)
+		stats := a.Stats()
+		Expect(stats.WriteLocks).To(Equal(uint64(1)))
+		Expect(stats.ReadLocks).To(Equal(uint64(2)))
+		Expect(stats.Operations).To(Equal(map[string]uint64{"PushRight": 1, "Search": 1, "Get": 1}))
+		Expect(a.Clone().Stats().Operations).To(BeEmpty())
+		Expect(array.New[int]().Stats()).To(Equal(gods.Stats{}))
+	})
+
+	It("Debug", func() {
+		a := array.NewFrom([]int{1, 2}, gods.WithSafe(true), gods.WithDebug(true))
+		Expect(func() {
+			a.Each(func(k int, v int) bool {
+				a.Size()
+				return true
+			})
+		}).To(PanicWith(ContainSubstring("for reading while holding the lock for reading")))
+		Expect(func() {
+			a.Each(func(k int, v int) bool {
+				_ = a.Set(k, v*2)
+				return true
+			})
+		}).To(PanicWith(ContainSubstring("for writing while holding the lock for reading")))
+		Expect(a.Slice()).To(Equal([]int{1, 2}))
+
+		var b *array.Array[int]
+		b = array.New[int](gods.WithSafe(true), gods.WithDebug(true),
+			gods.WithHooks(gods.Hooks[int]{OnInsert: func(int) { b.Size() }}))
+		Expect(func() { b.PushRight(1) }).To(PanicWith(ContainSubstring("for reading while holding the lock for writing")))
+		c := a.Clone()
+		Expect(func() { c.Each(func(int, int) bool { return c.Size() > 0 }) }).To(Panic())
+		Expect(func() { array.New[int](gods.WithDebug(true)).Each(func(int, int) bool { return true }) }).NotTo(Panic())
+	})
+
+	It("Ctx", func() {
+		held, release := make(chan struct{}), make(chan struct{})
+		a := array.NewFrom([]int{1, 2, 3}, gods.WithSafe(true), gods.WithHooks(gods.Hooks[int]{
+			OnInsert: func(v int) {
+				if v < 0 {
+					held <- struct{}{}
+					<-release
+				}
+			},
+		}))
+		go a.PushRight(-1)
+		<-held
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		Expect(a.PushLeftCtx(ctx, 0)).To(MatchError(context.DeadlineExceeded))
+		Expect(a.PushRightCtx(ctx, 4)).To(MatchError(context.DeadlineExceeded))
+		Expect(a.SetCtx(ctx, 0, 0)).To(MatchError(context.DeadlineExceeded))
+		_, _, err := a.PopLeftCtx(ctx)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		_, _, err = a.PopRightCtx(ctx)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		_, _, err = a.GetCtx(ctx, 0)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		close(release)
+
+		ctx = context.Background()
+		Expect(a.PushLeftCtx(ctx, 0)).To(Succeed())
+		Expect(a.PushRightCtx(ctx, 4)).To(Succeed())
+		Expect(a.SetCtx(ctx, 1, 10)).To(Succeed())
+		Expect(a.SetCtx(ctx, 10, 10)).To(HaveOccurred())
+		Expect(a.Slice()).To(Equal([]int{0, 10, 2, 3, -1, 4}))
+		value, found, err := a.GetCtx(ctx, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal(10))
+		value, found, err = a.PopLeftCtx(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal(0))
+		value, found, err = a.PopRightCtx(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal(4))
+		_, found, err = a.GetCtx(ctx, 10)
+		Expect(found, err).To(BeFalse())
+
+		empty := array.New[int](gods.WithLocker(gods.LockCopyOnWrite))
+		_, found, err = empty.PopRightCtx(ctx)
+		Expect(found, err).To(BeFalse())
+		_, found, err = empty.PopLeftCtx(ctx)
+		Expect(found, err).To(BeFalse())
+	})
+
+	It("Each snapshot", func() {
+		a := array.NewFrom([]int{1, 2, 3}, gods.WithSafe(true), gods.WithDebug(true), gods.WithSnapshot(true))
+		var seen []int
+		a.Each(func(k int, v int) bool {
+			seen = append(seen, v)
+			a.PushRight(v * 10)
+			_ = a.Set(k, -v)
+			return a.Size() < 6
+		})
+		Expect(seen).To(Equal([]int{1, 2, 3}))
+		Expect(a.Slice()).To(Equal([]int{-1, -2, -3, 10, 20, 30}))
+
+		// Writers are not blocked by a slow callback.
+		entered, pushed := make(chan struct{}), make(chan struct{})
+		go a.Each(func(k int, v int) bool {
+			if k == 0 {
+				close(entered)
+				<-pushed
+			}
+			return true
+		})
+		<-entered
+		a.PushRight(40)
+		close(pushed)
+		Expect(a.Size()).To(Equal(7))
+
+		Expect(a.Clone().String()).To(Equal("[-1 -2 -3 10 20 30 40]"))
+		cow := array.NewFrom([]int{1, 2}, gods.WithLocker(gods.LockCopyOnWrite), gods.WithDebug(true))
+		cow.Each(func(k int, v int) bool {
+			cow.PushRight(v)
+			return true
+		})
+		Expect(cow.Slice()).To(Equal([]int{1, 2, 1, 2}))
+	})
+})
+
+var _ = Describe("COWArray", func() {
+	It("Basic", func() {
+		var zero array.COWArray[int]
+		Expect(zero.IsEmpty()).To(BeTrue())
+		Expect(zero.Slice()).To(BeEmpty())
+		_, found := zero.PopLeft()
+		Expect(found).To(BeFalse())
+		_, found = zero.PopRight()
+		Expect(found).To(BeFalse())
+
+		source := []int{1, 2, 3}
+		a := array.NewCOWFrom(source)
+		source[0] = 0
+		Expect(a.Slice()).To(Equal([]int{1, 2, 3}))
+		a.PushRight(4, 5).PushLeft(-1, 0)
+		Expect(a.Slice()).To(Equal([]int{-1, 0, 1, 2, 3, 4, 5}))
+		Expect(a.Size()).To(Equal(7))
+		Expect(a.Index(2)).To(Equal(1))
+		Expect(a.Index(7)).To(BeZero())
+		Expect(a.Search(4)).To(Equal(5))
+		Expect(a.Contains(6)).To(BeFalse())
+
+		Expect(a.Set(0, 10)).To(Succeed())
+		Expect(a.Set(7, 10)).To(HaveOccurred())
+		value, found := a.PopLeft()
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal(10))
+		value, found = a.PopRight()
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal(5))
+		value, found = a.Remove(2)
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal(2))
+		_, found = a.Remove(5)
+		Expect(found).To(BeFalse())
+		Expect(a.String()).To(Equal("[0 1 3 4]"))
+
+		a.Update(func(array []int) []int {
+			return append(append([]int{}, array...), 5)
+		})
+		Expect(a.Slice()).To(Equal([]int{0, 1, 3, 4, 5}))
+		var seen []int
+		a.Each(func(k int, v int) bool {
+			a.PushRight(v)
+			seen = append(seen, v)
+			return k < 2
+		})
+		Expect(seen).To(Equal([]int{0, 1, 3}))
+		Expect(a.Clear().IsEmpty()).To(BeTrue())
+		Expect(array.NewCOW[int]().Size()).To(BeZero())
+
+		stored := []int{1, 2}
+		a.Store(stored)
+		stored[0] = 0
+		Expect(a.Slice()).To(Equal([]int{1, 2}))
+		var decoded array.COWArray[int]
+		Expect(json.Unmarshal([]byte("[3,4]"), &decoded)).To(Succeed())
+		Expect(decoded.Slice()).To(Equal([]int{3, 4}))
+	})
+
+	It("Immutable views", func() {
+		a := array.NewCOWFrom([]int{1, 2, 3})
+		view := a.Slice()
+		Expect(&a.Slice()[0]).To(BeIdenticalTo(&view[0]))
+
+		clone := a.Clone()
+		Expect(&clone.Slice()[0]).To(BeIdenticalTo(&view[0]))
+		clone.PushRight(4)
+		a.PopRight()
+		a.PushRight(5)
+		Expect(a.Set(0, 0)).To(Succeed())
+		Expect(view).To(Equal([]int{1, 2, 3}))
+		Expect(clone.Slice()).To(Equal([]int{1, 2, 3, 4}))
+		Expect(a.Slice()).To(Equal([]int{0, 2, 5}))
+	})
+
+	It("Concurrent", func() {
+		a := array.NewCOW[int]()
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var torn bool
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 500; j++ {
+					a.PushRight(j)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 500; j++ {
+					for _, v := range a.Slice() {
+						if v < 0 || v >= 500 {
+							mu.Lock()
+							torn = true
+							mu.Unlock()
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		Expect(torn).To(BeFalse())
+		Expect(a.Size()).To(Equal(2000))
+	})
+})
+
+var _ = containertest.Describe(containertest.Subject[int, *array.Array[int]]{
+	Name:     "Array",
+	New:      func(safe bool) *array.Array[int] { return array.New[int](gods.WithSafe(safe)) },
+	Add:      func(a *array.Array[int], item int) { a.PushRight(item) },
+	Remove:   func(a *array.Array[int], item int) { a.RemoveValue(item) },
+	Generate: func(r *rand.Rand) int { return r.Intn(16) },
+	Ordered:  true,
+})
+
+var _ = containertest.Describe(containertest.Subject[int, *array.COWArray[int]]{
+	Name: "COWArray",
+	New:  func(bool) *array.COWArray[int] { return array.NewCOW[int]() },
+	Add:  func(a *array.COWArray[int], item int) { a.PushRight(item) },
+	Remove: func(a *array.COWArray[int], item int) {
+		if i := a.Search(item); i >= 0 {
+			a.Remove(i)
+		}
+	},
+	Generate: func(r *rand.Rand) int { return r.Intn(16) },
+	Ordered:  true,
 })
@@ -0,0 +1,201 @@
+package array
+
+import (
+	"sync"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+var _ gods.Collection[int] = (*COWArray[int])(nil)
+
+// COWArray is a copy-on-write array for read-mostly usage, which is always concurrent-safe.
+// Readers load the current immutable slice and never lock nor block,
+// and writers copy the slice, modify the copy and publish it under a mutex,
+// so every write costs a full copy.
+//
+// It is an Array in copy-on-write usage, see gods.LockCopyOnWrite, with the methods of this usage:
+// Slice returns the published items without copying, and Store and Update replace all of them at once.
+// Use an Array created with gods.WithLocker(gods.LockCopyOnWrite) instead for its other methods and options.
+//
+// The zero value is an empty array ready to use.
+type COWArray[T comparable] struct {
+	once  sync.Once
+	array *Array[T]
+}
+
+// NewCOW creates and returns an empty copy-on-write array.
+func NewCOW[T comparable]() *COWArray[T] {
+	return new(COWArray[T])
+}
+
+// NewCOWFrom creates and returns a copy-on-write array with a copy of the given slice `array`.
+func NewCOWFrom[T comparable](array []T) *COWArray[T] {
+	return &COWArray[T]{array: NewFrom(append([]T(nil), array...), gods.WithLocker(gods.LockCopyOnWrite))}
+}
+
+// Index returns the value by the specified index.
+// If the given `index` is out of range of the array, it returns the zero value.
+func (a *COWArray[T]) Index(index int) T {
+	return a.items().Index(index)
+}
+
+// Get returns the value by the specified index.
+// If the given `index` is out of range of the array, the `found` is false.
+func (a *COWArray[T]) Get(index int) (value T, found bool) {
+	return a.items().Get(index)
+}
+
+// Set sets value to specified index.
+func (a *COWArray[T]) Set(index int, value T) error {
+	return a.items().Set(index, value)
+}
+
+// Remove removes an item by index.
+// If the given `index` is out of range of the array, the `found` is false.
+func (a *COWArray[T]) Remove(index int) (value T, found bool) {
+	return a.items().Remove(index)
+}
+
+// PushLeft pushes one or multiple items to the beginning of array.
+func (a *COWArray[T]) PushLeft(value ...T) *COWArray[T] {
+	a.items().PushLeft(value...)
+	return a
+}
+
+// PushRight pushes one or multiple items to the end of array.
+func (a *COWArray[T]) PushRight(value ...T) *COWArray[T] {
+	a.items().PushRight(value...)
+	return a
+}
+
+// PopLeft pops and returns an item from the beginning of array.
+// Note that if the array is empty, the `found` is false.
+func (a *COWArray[T]) PopLeft() (value T, found bool) {
+	return a.items().PopLeft()
+}
+
+// PopRight pops and returns an item from the end of array.
+// Note that if the array is empty, the `found` is false.
+func (a *COWArray[T]) PopRight() (value T, found bool) {
+	return a.items().PopRight()
+}
+
+// Store replaces all items of the array with a copy of `array`.
+func (a *COWArray[T]) Store(array []T) {
+	a.Update(func([]T) []T { return array })
+}
+
+// Update replaces all items of the array with a copy of the ones returned by `f` atomically,
+// which is called with the current items under the lock of writers.
+// Note that `f` must not modify the given slice, as it is shared with the readers,
+// and must not use the writing methods of the array, or else it deadlocks.
+func (a *COWArray[T]) Update(f func(array []T) []T) {
+	array := a.items()
+	array.mu.Lock()
+	defer array.unlock()
+	// The items are private to the writers, as the readers load their published copy.
+	array.array = append(array.array[:0], f(*array.view.Load())...)
+}
+
+// Clear deletes all items of current array.
+func (a *COWArray[T]) Clear() *COWArray[T] {
+	a.items().Clear()
+	return a
+}
+
+// Reset deletes all items of the array like Clear, it implements gods.Container.
+func (a *COWArray[T]) Reset() {
+	a.Clear()
+}
+
+// Copy returns a new array like Clone, it implements gods.Container.
+func (a *COWArray[T]) Copy() gods.Container[T] {
+	return a.Clone()
+}
+
+// Size returns the length of array.
+func (a *COWArray[T]) Size() int {
+	return a.items().Size()
+}
+
+// IsEmpty checks whether the array is empty.
+func (a *COWArray[T]) IsEmpty() bool {
+	return a.items().IsEmpty()
+}
+
+// Slice returns the current items of the array without copying.
+// Note that the returned slice is shared and immutable, it must not be modified.
+func (a *COWArray[T]) Slice() []T {
+	return a.items().snapshotItems()
+}
+
+// Clone returns a new array with the items of current array.
+// The new array shares the published items with current array, so it costs a single copy.
+func (a *COWArray[T]) Clone() *COWArray[T] {
+	array := a.items()
+	view := array.snapshotItems()
+	c := &Array[T]{
+		mu:    rwmutex.CreateWith(gods.NewOptions(array.mu.Options()...)),
+		array: append([]T(nil), view...),
+	}
+	c.view.Store(&view)
+	return &COWArray[T]{array: c}
+}
+
+// Contains checks whether a value exists in the array.
+func (a *COWArray[T]) Contains(value T) bool {
+	return a.items().Contains(value)
+}
+
+// Search searches array by `value`, returns the index of `value`,
+// or returns -1 if not exists.
+func (a *COWArray[T]) Search(value T) int {
+	return a.items().Search(value)
+}
+
+// Each calls `f` on every item in the array in ascending order.
+// If `f` returns true, then it continues iterating; or false to stop.
+// It iterates over the items as of the moment Each is called without locking,
+// so `f` may use or modify the array.
+func (a *COWArray[T]) Each(f func(k int, v T) bool) {
+	a.items().Each(f)
+}
+
+// Iterator returns an iterator over the items of the array in ascending order,
+// which are the ones as of the moment Iterator is called.
+func (a *COWArray[T]) Iterator() gods.Iterator[T] {
+	return a.items().Iterator()
+}
+
+// ReverseIterator returns an iterator over the items of the array in descending order,
+// which are the ones as of the moment ReverseIterator is called.
+func (a *COWArray[T]) ReverseIterator() gods.ReverseIterator[T] {
+	return a.items().ReverseIterator()
+}
+
+// String returns current array as a string.
+func (a *COWArray[T]) String() string {
+	return a.items().String()
+}
+
+// MarshalJSON implements the interface MarshalJSON for json.Marshal.
+func (a *COWArray[T]) MarshalJSON() ([]byte, error) {
+	return a.items().MarshalJSON()
+}
+
+// UnmarshalJSON implements the interface UnmarshalJSON for json.Unmarshal,
+// which replaces all items of the array with the decoded ones.
+func (a *COWArray[T]) UnmarshalJSON(b []byte) error {
+	return a.items().UnmarshalJSON(b)
+}
+
+// items returns the underlying array, which is created on first use for the zero value.
+func (a *COWArray[T]) items() *Array[T] {
+	a.once.Do(func() {
+		if a.array == nil {
+			a.array = NewFrom([]T(nil), gods.WithLocker(gods.LockCopyOnWrite))
+		}
+	})
+	return a.array
+}
@@ -0,0 +1,219 @@
+package array_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+)
+
+// maxFill is the largest number of items filled by FuzzFill, so it does not run out of memory.
+const maxFill = 1 << 12
+
+// items returns the items of the fuzzed array.
+func items(data []byte) []int {
+	s := make([]int, len(data))
+	for i, b := range data {
+		s[i] = int(b)
+	}
+	return s
+}
+
+// newFuzzArrays returns the arrays of `data` in every usage, which must behave the same.
+func newFuzzArrays(data []byte) []*array.Array[int] {
+	return []*array.Array[int]{
+		array.NewFrom(items(data)),
+		array.NewFrom(items(data), gods.WithSafe(true)),
+		array.NewFrom(items(data), gods.WithLocker(gods.LockCopyOnWrite)),
+	}
+}
+
+// equal checks whether `got` has the same items as `want`, where nil equals empty.
+func equal(got, want []int) bool {
+	if len(got) == 0 && len(want) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+func FuzzGet(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte, index int) {
+		want := items(data)
+		for _, a := range newFuzzArrays(data) {
+			value, found := a.Get(index)
+			if index >= 0 && index < len(want) {
+				if !found || value != want[index] {
+					t.Fatalf("Get(%d) of %v = %d, %t", index, want, value, found)
+				}
+			} else if found || value != 0 {
+				t.Fatalf("Get(%d) of %v = %d, %t, want not found", index, want, value, found)
+			}
+		}
+	})
+}
+
+func FuzzSet(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte, index int, value int) {
+		want := items(data)
+		inRange := index >= 0 && index < len(want)
+		if inRange {
+			want[index] = value
+		}
+		for _, a := range newFuzzArrays(data) {
+			err := a.Set(index, value)
+			if (err == nil) != inRange || !equal(a.Slice(), want) {
+				t.Fatalf("Set(%d, %d) of %v = %v, %v, want %v", index, value, items(data), a.Slice(), err, want)
+			}
+		}
+	})
+}
+
+func FuzzInsert(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte, index int, value int, after bool) {
+		inRange := index >= 0 && index < len(data)
+		want := items(data)
+		if inRange {
+			at := index
+			if after {
+				at++
+			}
+			want = append(want[:at:at], append([]int{value}, want[at:]...)...)
+		}
+		for _, a := range newFuzzArrays(data) {
+			var err error
+			if after {
+				err = a.InsertAfter(index, value)
+			} else {
+				err = a.InsertBefore(index, value)
+			}
+			if (err == nil) != inRange || !equal(a.Slice(), want) {
+				t.Fatalf("Insert(%d, %d, after %t) of %v = %v, %v, want %v",
+					index, value, after, items(data), a.Slice(), err, want)
+			}
+		}
+	})
+}
+
+func FuzzRemove(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte, index int) {
+		want := items(data)
+		inRange := index >= 0 && index < len(want)
+		var removed int
+		if inRange {
+			removed = want[index]
+			want = append(want[:index:index], want[index+1:]...)
+		}
+		for _, a := range newFuzzArrays(data) {
+			value, found := a.Remove(index)
+			if found != inRange || value != removed || !equal(a.Slice(), want) {
+				t.Fatalf("Remove(%d) of %v = %d, %t, %v, want %v", index, items(data), value, found, a.Slice(), want)
+			}
+		}
+	})
+}
+
+// subSlice is the reference implementation of SubSlice, which returns nil if it fails.
+func subSlice(s []int, offset int, length int, hasLength bool) []int {
+	if offset < 0 {
+		// Start that far from the end, failing if crossing the left border.
+		if offset < -len(s) {
+			return nil
+		}
+		offset += len(s)
+	}
+	if offset > len(s) {
+		return nil
+	}
+	end := len(s)
+	if hasLength {
+		if length < 0 {
+			// Take the -length items before the offset, failing if crossing the left border.
+			if length < -offset {
+				return nil
+			}
+			offset, end = offset+length, offset
+		} else if length < len(s)-offset {
+			end = offset + length
+		}
+	}
+	return append([]int{}, s[offset:end]...)
+}
+
+func FuzzSubSlice(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte, offset int, length int, hasLength bool) {
+		want := subSlice(items(data), offset, length, hasLength)
+		for _, a := range newFuzzArrays(data) {
+			var got []int
+			if hasLength {
+				got = a.SubSlice(offset, length)
+			} else {
+				got = a.SubSlice(offset)
+			}
+			if (got == nil) != (want == nil) || !equal(got, want) {
+				t.Fatalf("SubSlice(%d, %d, %t) of %v = %v, want %v", offset, length, hasLength, items(data), got, want)
+			}
+			if len(got) > 0 {
+				got[0]++
+				if !equal(a.Slice(), items(data)) {
+					t.Fatalf("SubSlice(%d, %d, %t) of %v aliases the array", offset, length, hasLength, items(data))
+				}
+			}
+		}
+	})
+}
+
+func FuzzFill(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte, startIndex int, num int, value int) {
+		want := items(data)
+		inRange := startIndex >= 0 && startIndex <= len(want) && num <= math.MaxInt-startIndex
+		if inRange && num > maxFill {
+			t.Skip()
+		}
+		if inRange {
+			for i := 0; i < num; i++ {
+				if startIndex+i < len(want) {
+					want[startIndex+i] = value
+				} else {
+					want = append(want, value)
+				}
+			}
+		}
+		for _, a := range newFuzzArrays(data) {
+			err := a.Fill(startIndex, num, value)
+			if (err == nil) != inRange || !equal(a.Slice(), want) {
+				t.Fatalf("Fill(%d, %d, %d) of %v = %v, %v, want %v", startIndex, num, value, items(data), a.Slice(), err, want)
+			}
+		}
+	})
+}
+
+func FuzzChunk(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte, size int) {
+		s := items(data)
+		var want [][]int
+		if size > 0 {
+			for len(s) > 0 {
+				n := size
+				if n > len(s) {
+					n = len(s)
+				}
+				want = append(want, s[:n])
+				s = s[n:]
+			}
+		}
+		for _, a := range newFuzzArrays(data) {
+			got := a.Chunk(size)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Chunk(%d) of %v = %v, want %v", size, items(data), got, want)
+			}
+			if len(got) > 0 && a.Size() > 0 {
+				got[0][0]++
+				if !equal(a.Slice(), items(data)) {
+					t.Fatalf("Chunk(%d) of %v aliases the array", size, items(data))
+				}
+			}
+		}
+	})
+}
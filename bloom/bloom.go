@@ -0,0 +1,334 @@
+// Package bloom implements Bloom filters, which test whether a key is in a set in constant memory,
+// at the cost of false positives: a key never added may be reported as added, at a configured rate,
+// but a key added is always reported as added.
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+var (
+	// ErrIncompatible is returned when combining filters of different sizes or numbers of hashes.
+	ErrIncompatible = errors.New("incompatible filters")
+	// ErrInvalidData is returned when decoding invalid binary data into a filter.
+	ErrInvalidData = errors.New("invalid binary data of filter")
+)
+
+// Kinds of filters in the binary encoding.
+const (
+	kindFilter   byte = 'B'
+	kindCounting byte = 'C'
+)
+
+// headerSize is the size of the binary encoding before the bits or counters:
+// the kind, the number of hashes in 4 bytes, and the number of bits or counters in 8 bytes.
+const headerSize = 13
+
+// maxHashes is the largest number of hashes of a filter,
+// which is enough for a false positive rate of 2^-64.
+const maxHashes = 64
+
+// params are the size and the number of hashes of a filter.
+type params struct {
+	// Number of bits or counters.
+	m uint64
+	// Number of hashes, which is the number of bits or counters of every key.
+	k uint32
+}
+
+// newParams returns the optimal parameters of a filter holding `expectedItems` items
+// with the false positive rate `falsePositiveRate`.
+func newParams(expectedItems int, falsePositiveRate float64) params {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	if !(falsePositiveRate > 0) {
+		falsePositiveRate = math.SmallestNonzeroFloat64
+	}
+	if falsePositiveRate >= 1 {
+		falsePositiveRate = 0.5
+	}
+	n := float64(expectedItems)
+	m := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / n * math.Ln2)
+	return newSizedParams(uint64(m), uint32(k))
+}
+
+// newSizedParams returns the parameters of `m` bits or counters and `k` hashes, which are at least 1,
+// and `k` is at most maxHashes.
+func newSizedParams(m uint64, k uint32) params {
+	if m < 1 {
+		m = 1
+	}
+	if k < 1 {
+		k = 1
+	}
+	if k > maxHashes {
+		k = maxHashes
+	}
+	return params{m: m, k: k}
+}
+
+// each calls `fn` on the k indexes of the key of hash `h`, if `fn` returns true then continue; or false to stop.
+// It reports whether `fn` returns true for all the indexes.
+// The indexes are derived from `h` by double hashing.
+func (p params) each(h uint64, fn func(i uint64) bool) bool {
+	h2 := bits.RotateLeft64(h, 32) | 1
+	for i := uint32(0); i < p.k; i++ {
+		if !fn((h + uint64(i)*h2) % p.m) {
+			return false
+		}
+	}
+	return true
+}
+
+// estimatedCount returns the estimated number of distinct keys added, given `set` bits or counters not zero.
+func (p params) estimatedCount(set uint64) float64 {
+	if set >= p.m {
+		return math.Inf(1)
+	}
+	m := float64(p.m)
+	return -m / float64(p.k) * math.Log(1-float64(set)/m)
+}
+
+// words returns the number of 64-bit words holding `m` bits.
+func words(m uint64) uint64 {
+	return m/64 + (m%64+63)/64
+}
+
+// marshal returns the binary encoding header of a filter of kind `kind` with room for `size` more bytes.
+func (p params) marshal(kind byte, size int) []byte {
+	b := make([]byte, headerSize, headerSize+size)
+	b[0] = kind
+	binary.BigEndian.PutUint32(b[1:], p.k)
+	binary.BigEndian.PutUint64(b[5:], p.m)
+	return b
+}
+
+// unmarshalParams decodes the header of the binary encoding `b` of a filter of kind `kind`,
+// it returns the parameters and the bytes after the header.
+func unmarshalParams(kind byte, b []byte) (params, []byte, error) {
+	if len(b) < headerSize || b[0] != kind {
+		return params{}, nil, ErrInvalidData
+	}
+	p := params{k: binary.BigEndian.Uint32(b[1:]), m: binary.BigEndian.Uint64(b[5:])}
+	if p.k == 0 || p.k > maxHashes || p.m == 0 {
+		return params{}, nil, ErrInvalidData
+	}
+	return p, b[headerSize:], nil
+}
+
+// Filter is a Bloom filter of keys of type T.
+type Filter[T any] struct {
+	mu     rwmutex.RWMutex
+	params params
+	bits   []uint64
+	hasher Hasher[T]
+}
+
+// New creates and returns an empty filter sized for `expectedItems` keys with the false positive rate
+// `falsePositiveRate`, which is exceeded if more keys are added. The keys are hashed by `hasher`,
+// or by Default[T] if it is nil.
+// The parameter `options` is used to configure the filter, see gods.Option.
+// It is not concurrent-safe in default.
+func New[T any](expectedItems int, falsePositiveRate float64, hasher Hasher[T], options ...gods.Option) *Filter[T] {
+	return newFilter(newParams(expectedItems, falsePositiveRate), hasher, options)
+}
+
+// NewWithSize creates and returns an empty filter of `m` bits and `k` hashes, which are at least 1,
+// and `k` is at most 64.
+// The keys are hashed by `hasher`, or by Default[T] if it is nil.
+// The parameter `options` is used to configure the filter, see gods.Option.
+// It is not concurrent-safe in default.
+func NewWithSize[T any](m uint64, k uint32, hasher Hasher[T], options ...gods.Option) *Filter[T] {
+	return newFilter(newSizedParams(m, k), hasher, options)
+}
+
+// newFilter creates and returns an empty filter of the parameters `p`.
+func newFilter[T any](p params, hasher Hasher[T], opts []gods.Option) *Filter[T] {
+	if hasher == nil {
+		hasher = Default[T]()
+	}
+	return &Filter[T]{
+		mu:     rwmutex.CreateWithoutCOW("bloom.Filter", opts),
+		params: p,
+		bits:   make([]uint64, words(p.m)),
+		hasher: hasher,
+	}
+}
+
+// M returns the number of bits of the filter.
+func (f *Filter[T]) M() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.params.m
+}
+
+// K returns the number of hashes of the filter, which is the number of bits set by every key.
+func (f *Filter[T]) K() uint32 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.params.k
+}
+
+// Add adds one or multiple keys to the filter.
+func (f *Filter[T]) Add(keys ...T) {
+	f.mu.Count("Add")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range keys {
+		f.params.each(f.hasher(key), func(i uint64) bool {
+			f.bits[i/64] |= 1 << (i % 64)
+			return true
+		})
+	}
+}
+
+// Test checks whether `key` may be in the filter. If it returns false, `key` is never added,
+// and if it returns true, `key` is added, or not with a probability of the false positive rate.
+func (f *Filter[T]) Test(key T) bool {
+	f.mu.Count("Test")
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.params.each(f.hasher(key), func(i uint64) bool {
+		return f.bits[i/64]&(1<<(i%64)) != 0
+	})
+}
+
+// Clear removes all keys of the filter.
+func (f *Filter[T]) Clear() {
+	f.mu.Count("Clear")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.bits {
+		f.bits[i] = 0
+	}
+}
+
+// FillRatio returns the ratio of the bits set, which is about 0.5 when the filter is
+// at the expected number of keys, and the false positive rate grows along with it.
+func (f *Filter[T]) FillRatio() float64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return float64(f.setBits()) / float64(f.params.m)
+}
+
+// EstimatedCount returns the estimated number of distinct keys added to the filter,
+// which is infinity if all the bits are set.
+func (f *Filter[T]) EstimatedCount() float64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.params.estimatedCount(f.setBits())
+}
+
+// FalsePositiveRate returns the estimated false positive rate of the filter as of now.
+func (f *Filter[T]) FalsePositiveRate() float64 {
+	return math.Pow(f.FillRatio(), float64(f.K()))
+}
+
+// Clone returns a new filter, which is a copy of current filter.
+func (f *Filter[T]) Clone() *Filter[T] {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	clone := newFilter(f.params, f.hasher, f.mu.Options())
+	copy(clone.bits, f.bits)
+	return clone
+}
+
+// Union returns a new filter of the keys added to current filter or any of `others`,
+// which must not be nil and have the same size and number of hashes, or else it returns ErrIncompatible.
+// It tests the keys like a filter they are all added to.
+func (f *Filter[T]) Union(others ...*Filter[T]) (*Filter[T], error) {
+	return f.combine(others, func(word, other uint64) uint64 { return word | other })
+}
+
+// Intersect returns a new filter of the keys added to current filter and all of `others`,
+// which must not be nil and have the same size and number of hashes, or else it returns ErrIncompatible.
+// Note that its false positive rate is higher than the one of a filter only the common keys are added to.
+func (f *Filter[T]) Intersect(others ...*Filter[T]) (*Filter[T], error) {
+	return f.combine(others, func(word, other uint64) uint64 { return word & other })
+}
+
+// combine returns a copy of current filter whose bits are combined with the ones of `others` by `fn`.
+func (f *Filter[T]) combine(others []*Filter[T], fn func(word, other uint64) uint64) (*Filter[T], error) {
+	newFilter := f.Clone()
+	for _, other := range others {
+		if err := newFilter.doCombineWithoutLock(other, fn); err != nil {
+			return nil, err
+		}
+	}
+	return newFilter, nil
+}
+
+// doCombineWithoutLock combines the bits of `other` into the ones of the filter by `fn`,
+// where the filter is not shared yet, so it is not locked.
+func (f *Filter[T]) doCombineWithoutLock(other *Filter[T], fn func(word, other uint64) uint64) error {
+	if other == nil {
+		return ErrIncompatible
+	}
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	if other.params != f.params {
+		return ErrIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] = fn(f.bits[i], other.bits[i])
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the metrics of the filter created with gods.WithMetrics(true).
+func (f *Filter[T]) Stats() gods.Stats {
+	return f.mu.Stats()
+}
+
+// MarshalBinary implements the interface encoding.BinaryMarshaler,
+// which encodes the size, the number of hashes and the bits of the filter, but not its hasher.
+func (f *Filter[T]) MarshalBinary() ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	b := f.params.marshal(kindFilter, len(f.bits)*8)
+	for _, word := range f.bits {
+		b = binary.BigEndian.AppendUint64(b, word)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the interface encoding.BinaryUnmarshaler,
+// which replaces the size, the number of hashes and the bits of the filter with the decoded ones.
+// The filter keeps its hasher, which must be the one of the encoded filter, or Default[T] if it has none.
+func (f *Filter[T]) UnmarshalBinary(b []byte) error {
+	p, b, err := unmarshalParams(kindFilter, b)
+	if err != nil {
+		return err
+	}
+	if len(b)%8 != 0 || uint64(len(b)/8) != words(p.m) {
+		return ErrInvalidData
+	}
+	data := make([]uint64, len(b)/8)
+	for i := range data {
+		data[i] = binary.BigEndian.Uint64(b[i*8:])
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.params, f.bits = p, data
+	if f.hasher == nil {
+		f.hasher = Default[T]()
+	}
+	return nil
+}
+
+// setBits returns the number of bits set.
+func (f *Filter[T]) setBits() uint64 {
+	var set int
+	for _, word := range f.bits {
+		set += bits.OnesCount64(word)
+	}
+	return uint64(set)
+}
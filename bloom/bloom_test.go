@@ -0,0 +1,276 @@
+package bloom_test
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/bloom"
+)
+
+func TestBloom(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bloom Suite")
+}
+
+// filter is the API shared by Filter and CountingFilter.
+type filter[T any] interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	M() uint64
+	K() uint32
+	Add(keys ...T)
+	Test(key T) bool
+	Clear()
+	FillRatio() float64
+	EstimatedCount() float64
+	FalsePositiveRate() float64
+}
+
+// falsePositives returns the ratio of the keys in [from, to) tested as false positives.
+func falsePositives(f filter[int], from, to int) float64 {
+	var positives int
+	for i := from; i < to; i++ {
+		if f.Test(i) {
+			positives++
+		}
+	}
+	return float64(positives) / float64(to-from)
+}
+
+var _ = DescribeTable("Filter API",
+	func(newFilter func(expectedItems int, falsePositiveRate float64, options ...gods.Option) filter[int],
+		newEmpty func() filter[int]) {
+		f := newFilter(10000, 0.01)
+		Expect(f.M()).To(BeNumerically("~", 95851, 1))
+		Expect(f.K()).To(Equal(uint32(7)))
+		Expect(f.Test(1)).To(BeFalse())
+		Expect(f.FillRatio()).To(BeZero())
+		Expect(f.EstimatedCount()).To(BeZero())
+
+		for i := 0; i < 10000; i++ {
+			f.Add(i)
+		}
+		for i := 0; i < 10000; i++ {
+			Expect(f.Test(i)).To(BeTrue())
+		}
+		Expect(falsePositives(f, 10000, 110000)).To(BeNumerically("<", 0.015))
+		Expect(f.FillRatio()).To(BeNumerically("~", 0.5, 0.02))
+		Expect(f.EstimatedCount()).To(BeNumerically("~", 10000, 300))
+		Expect(f.FalsePositiveRate()).To(BeNumerically("~", 0.01, 0.003))
+
+		b, err := f.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		decoded := newEmpty()
+		Expect(decoded.UnmarshalBinary(b)).To(Succeed())
+		Expect(decoded.M()).To(Equal(f.M()))
+		Expect(decoded.K()).To(Equal(f.K()))
+		for i := 0; i < 10000; i++ {
+			Expect(decoded.Test(i)).To(BeTrue())
+		}
+		Expect(decoded.FillRatio()).To(Equal(f.FillRatio()))
+
+		Expect(decoded.UnmarshalBinary(nil)).To(MatchError(bloom.ErrInvalidData))
+		Expect(decoded.UnmarshalBinary(b[:len(b)-1])).To(MatchError(bloom.ErrInvalidData))
+		Expect(decoded.UnmarshalBinary(append([]byte{'X'}, b[1:]...))).To(MatchError(bloom.ErrInvalidData))
+		tooManyHashes := append([]byte(nil), b...)
+		binary.BigEndian.PutUint32(tooManyHashes[1:], 65)
+		Expect(decoded.UnmarshalBinary(tooManyHashes)).To(MatchError(bloom.ErrInvalidData))
+		Expect(decoded.M()).To(Equal(f.M()))
+
+		f.Clear()
+		Expect(f.Test(1)).To(BeFalse())
+		Expect(f.FillRatio()).To(BeZero())
+
+		safe := newFilter(1000, 0.01, gods.WithSafe(true))
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 250; i++ {
+					safe.Add(w*250 + i)
+					Expect(safe.Test(w*250 + i)).To(BeTrue())
+				}
+			}(w)
+		}
+		wg.Wait()
+		Expect(falsePositives(safe, 0, 1000)).To(Equal(1.0))
+	},
+	Entry("Filter", func(n int, p float64, options ...gods.Option) filter[int] {
+		return bloom.New[int](n, p, nil, options...)
+	}, func() filter[int] { return new(bloom.Filter[int]) }),
+	Entry("CountingFilter", func(n int, p float64, options ...gods.Option) filter[int] {
+		return bloom.NewCounting[int](n, p, nil, options...)
+	}, func() filter[int] { return new(bloom.CountingFilter[int]) }),
+)
+
+var _ = Describe("Filter", func() {
+	It("Sizes", func() {
+		f := bloom.NewWithSize[int](100, 3, nil)
+		Expect(f.M()).To(Equal(uint64(100)))
+		Expect(f.K()).To(Equal(uint32(3)))
+		Expect(bloom.NewWithSize[int](100, 1000, nil).K()).To(Equal(uint32(64)))
+		f = bloom.NewWithSize[int](0, 0, nil)
+		Expect(f.M()).To(Equal(uint64(1)))
+		Expect(f.K()).To(Equal(uint32(1)))
+		f.Add(1)
+		Expect(f.Test(2)).To(BeTrue())
+		Expect(f.EstimatedCount()).To(BeNumerically(">", 1e300))
+
+		f = bloom.New[int](0, 0, nil)
+		Expect(f.M()).To(BeNumerically(">", 0))
+		f = bloom.New[int](100, 1, nil)
+		Expect(f.K()).To(Equal(uint32(1)))
+	})
+
+	It("Union and Intersect", func() {
+		f1 := bloom.New[int](1000, 0.01, nil)
+		f2 := bloom.New[int](1000, 0.01, nil)
+		for i := 0; i < 600; i++ {
+			f1.Add(i)
+			f2.Add(i + 400)
+		}
+		union, err := f1.Union(f2)
+		Expect(err).NotTo(HaveOccurred())
+		intersection, err := f1.Intersect(f2)
+		Expect(err).NotTo(HaveOccurred())
+		for i := 0; i < 1000; i++ {
+			Expect(union.Test(i)).To(BeTrue())
+		}
+		for i := 400; i < 600; i++ {
+			Expect(intersection.Test(i)).To(BeTrue())
+		}
+		Expect(falsePositives(intersection, 0, 400)).To(BeNumerically("<", 0.1))
+		Expect(f1.Test(999)).To(BeFalse())
+
+		_, err = f1.Union(bloom.New[int](1000, 0.1, nil))
+		Expect(err).To(MatchError(bloom.ErrIncompatible))
+		_, err = f1.Intersect(nil)
+		Expect(err).To(MatchError(bloom.ErrIncompatible))
+	})
+
+	It("Hashers", func() {
+		bytes := bloom.New(100, 0.01, bloom.Bytes)
+		bytes.Add([]byte("a"), []byte("b"))
+		Expect(bytes.Test([]byte("a"))).To(BeTrue())
+		Expect(bytes.Test([]byte("c"))).To(BeFalse())
+
+		type point struct{ x, y int }
+		points := bloom.New[point](100, 0.01, nil)
+		points.Add(point{1, 2})
+		Expect(points.Test(point{1, 2})).To(BeTrue())
+		Expect(points.Test(point{2, 1})).To(BeFalse())
+
+		custom := bloom.New(100, 0.01, func(key string) uint64 { return bloom.Uint64(uint64(len(key))) })
+		custom.Add("abc")
+		Expect(custom.Test("xyz")).To(BeTrue())
+
+		floats := bloom.New[float64](100, 0.01, nil)
+		floats.Add(0)
+		Expect(floats.Test(math.Copysign(0, -1))).To(BeTrue())
+
+		Expect(bloom.Default[string]()("abc")).To(Equal(bloom.String("abc")))
+		Expect(bloom.Default[[]byte]()([]byte("abc"))).To(Equal(bloom.String("abc")))
+		Expect(bloom.Default[int]()(1)).To(Equal(bloom.Uint64(1)))
+		Expect(bloom.Default[int8]()(-1)).To(Equal(bloom.Uint64(1<<64 - 1)))
+		Expect(bloom.Default[float64]()(1)).NotTo(Equal(bloom.Default[float64]()(2)))
+		Expect(bloom.Default[float64]()(math.Copysign(0, -1))).To(Equal(bloom.Default[float64]()(0)))
+		Expect(bloom.Default[float32]()(float32(math.Copysign(0, -1)))).To(Equal(bloom.Default[float32]()(0)))
+		Expect(bloom.Default[float32]()(0)).To(Equal(bloom.Default[float64]()(0)))
+		Expect(bloom.Default[bool]()(true)).NotTo(Equal(bloom.Default[bool]()(false)))
+
+		strs := bloom.New[string](1000, 0.01, nil)
+		for i := 0; i < 1000; i++ {
+			strs.Add(strconv.Itoa(i))
+		}
+		var positives int
+		for i := 1000; i < 11000; i++ {
+			if strs.Test(fmt.Sprint(i)) {
+				positives++
+			}
+		}
+		Expect(positives).To(BeNumerically("<", 200))
+	})
+
+	It("Stats", func() {
+		f := bloom.New[int](100, 0.01, nil, gods.WithMetrics(true), gods.WithLocker(gods.LockSpin))
+		f.Add(1)
+		f.Test(1)
+		Expect(f.Stats().Operations).To(HaveKeyWithValue("Add", uint64(1)))
+		Expect(f.Stats().Operations).To(HaveKeyWithValue("Test", uint64(1)))
+		Expect(f.Clone().Stats().Operations).To(BeEmpty())
+		Expect(func() { bloom.New[int](100, 0.01, nil, gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: bloom.Filter does not support LockCopyOnWrite"))
+		Expect(func() { bloom.NewCounting[int](100, 0.01, nil, gods.WithCapacity(100)) }).
+			To(PanicWith("gods: bloom.CountingFilter does not support WithCapacity"))
+	})
+})
+
+var _ = Describe("CountingFilter", func() {
+	It("Remove", func() {
+		f := bloom.NewCounting[int](1000, 0.01, nil)
+		for i := 0; i < 1000; i++ {
+			f.Add(i)
+		}
+		for i := 0; i < 500; i++ {
+			Expect(f.Remove(i)).To(BeTrue())
+		}
+		for i := 500; i < 1000; i++ {
+			Expect(f.Test(i)).To(BeTrue())
+		}
+		Expect(falsePositives(f, 0, 500)).To(BeNumerically("<", 0.02))
+		Expect(f.EstimatedCount()).To(BeNumerically("~", 500, 50))
+
+		f.Add(2000, 2000)
+		Expect(f.Remove(2000)).To(BeTrue())
+		Expect(f.Test(2000)).To(BeTrue())
+		Expect(f.Remove(2000)).To(BeTrue())
+		Expect(f.Test(2000)).To(BeFalse())
+		Expect(f.Remove(2000)).To(BeFalse())
+	})
+
+	It("Saturates", func() {
+		f := bloom.NewCountingWithSize[int](64, 1, nil)
+		for i := 0; i < 300; i++ {
+			f.Add(1)
+		}
+		for i := 0; i < 300; i++ {
+			Expect(f.Remove(1)).To(BeTrue())
+		}
+		Expect(f.Test(1)).To(BeTrue())
+	})
+
+	It("Union and Intersect", func() {
+		f1 := bloom.NewCounting[int](100, 0.01, nil)
+		f2 := bloom.NewCounting[int](100, 0.01, nil)
+		f1.Add(1, 2)
+		f2.Add(2, 3)
+		union, err := f1.Union(f2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(union.Test(1) && union.Test(2) && union.Test(3)).To(BeTrue())
+		Expect(union.Remove(2)).To(BeTrue())
+		Expect(union.Test(2)).To(BeTrue())
+
+		intersection, err := f1.Intersect(f2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(intersection.Test(2)).To(BeTrue())
+		Expect(intersection.Remove(2)).To(BeTrue())
+		Expect(intersection.Test(2)).To(BeFalse())
+		Expect(f1.Test(2)).To(BeTrue())
+
+		_, err = f1.Union(bloom.NewCountingWithSize[int](10, 1, nil))
+		Expect(err).To(MatchError(bloom.ErrIncompatible))
+		_, err = f1.Intersect(nil)
+		Expect(err).To(MatchError(bloom.ErrIncompatible))
+	})
+})
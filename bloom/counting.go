@@ -0,0 +1,255 @@
+package bloom
+
+import (
+	"math"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// CountingFilter is a Bloom filter of keys of type T which supports removing keys,
+// at the cost of a byte instead of a bit per slot, as every slot counts the keys it belongs to.
+//
+// A counter saturates at 255 and is never decremented from there, so a key is never lost,
+// but the slot stays set until the filter is cleared.
+type CountingFilter[T any] struct {
+	mu       rwmutex.RWMutex
+	params   params
+	counters []uint8
+	hasher   Hasher[T]
+}
+
+// NewCounting creates and returns an empty counting filter sized for `expectedItems` keys
+// with the false positive rate `falsePositiveRate`, which is exceeded if more keys are added.
+// The keys are hashed by `hasher`, or by Default[T] if it is nil.
+// The parameter `options` is used to configure the filter, see gods.Option.
+// It is not concurrent-safe in default.
+func NewCounting[T any](expectedItems int, falsePositiveRate float64, hasher Hasher[T],
+	options ...gods.Option) *CountingFilter[T] {
+	return newCountingFilter(newParams(expectedItems, falsePositiveRate), hasher, options)
+}
+
+// NewCountingWithSize creates and returns an empty counting filter of `m` counters and `k` hashes,
+// which are at least 1, and `k` is at most 64. The keys are hashed by `hasher`, or by Default[T] if it is nil.
+// The parameter `options` is used to configure the filter, see gods.Option.
+// It is not concurrent-safe in default.
+func NewCountingWithSize[T any](m uint64, k uint32, hasher Hasher[T], options ...gods.Option) *CountingFilter[T] {
+	return newCountingFilter(newSizedParams(m, k), hasher, options)
+}
+
+// newCountingFilter creates and returns an empty counting filter of the parameters `p`.
+func newCountingFilter[T any](p params, hasher Hasher[T], opts []gods.Option) *CountingFilter[T] {
+	if hasher == nil {
+		hasher = Default[T]()
+	}
+	return &CountingFilter[T]{
+		mu:       rwmutex.CreateWithoutCOW("bloom.CountingFilter", opts),
+		params:   p,
+		counters: make([]uint8, p.m),
+		hasher:   hasher,
+	}
+}
+
+// M returns the number of counters of the filter.
+func (f *CountingFilter[T]) M() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.params.m
+}
+
+// K returns the number of hashes of the filter, which is the number of counters of every key.
+func (f *CountingFilter[T]) K() uint32 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.params.k
+}
+
+// Add adds one or multiple keys to the filter.
+func (f *CountingFilter[T]) Add(keys ...T) {
+	f.mu.Count("Add")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range keys {
+		f.params.each(f.hasher(key), func(i uint64) bool {
+			if f.counters[i] < math.MaxUint8 {
+				f.counters[i]++
+			}
+			return true
+		})
+	}
+}
+
+// Remove removes `key` from the filter, and reports whether it may be in the filter as Test does.
+// It does nothing if `key` is not in the filter.
+//
+// Note that removing a key which is never added, but tested as a false positive, removes
+// the keys sharing its counters, so only the keys known to be added should be removed.
+func (f *CountingFilter[T]) Remove(key T) bool {
+	f.mu.Count("Remove")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h := f.hasher(key)
+	if !f.doTestWithoutLock(h) {
+		return false
+	}
+	f.params.each(h, func(i uint64) bool {
+		if f.counters[i] < math.MaxUint8 {
+			f.counters[i]--
+		}
+		return true
+	})
+	return true
+}
+
+// Test checks whether `key` may be in the filter. If it returns false, `key` is never added or is removed,
+// and if it returns true, `key` is added, or not with a probability of the false positive rate.
+func (f *CountingFilter[T]) Test(key T) bool {
+	f.mu.Count("Test")
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.doTestWithoutLock(f.hasher(key))
+}
+
+// doTestWithoutLock checks whether all the counters of the key of hash `h` are set without locking.
+func (f *CountingFilter[T]) doTestWithoutLock(h uint64) bool {
+	return f.params.each(h, func(i uint64) bool {
+		return f.counters[i] > 0
+	})
+}
+
+// Clear removes all keys of the filter.
+func (f *CountingFilter[T]) Clear() {
+	f.mu.Count("Clear")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.counters {
+		f.counters[i] = 0
+	}
+}
+
+// FillRatio returns the ratio of the counters set, which is about 0.5 when the filter is
+// at the expected number of keys, and the false positive rate grows along with it.
+func (f *CountingFilter[T]) FillRatio() float64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return float64(f.setCounters()) / float64(f.params.m)
+}
+
+// EstimatedCount returns the estimated number of distinct keys in the filter,
+// which is infinity if all the counters are set.
+func (f *CountingFilter[T]) EstimatedCount() float64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.params.estimatedCount(f.setCounters())
+}
+
+// FalsePositiveRate returns the estimated false positive rate of the filter as of now.
+func (f *CountingFilter[T]) FalsePositiveRate() float64 {
+	return math.Pow(f.FillRatio(), float64(f.K()))
+}
+
+// Clone returns a new filter, which is a copy of current filter.
+func (f *CountingFilter[T]) Clone() *CountingFilter[T] {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	clone := newCountingFilter(f.params, f.hasher, f.mu.Options())
+	copy(clone.counters, f.counters)
+	return clone
+}
+
+// Union returns a new filter of the keys in current filter or any of `others`, whose counters are
+// the sums of theirs, which must not be nil and have the same size and number of hashes,
+// or else it returns ErrIncompatible.
+func (f *CountingFilter[T]) Union(others ...*CountingFilter[T]) (*CountingFilter[T], error) {
+	return f.combine(others, func(counter, other uint8) uint8 {
+		if int(counter)+int(other) >= math.MaxUint8 {
+			return math.MaxUint8
+		}
+		return counter + other
+	})
+}
+
+// Intersect returns a new filter of the keys in current filter and all of `others`, whose counters are
+// the minimums of theirs, which must not be nil and have the same size and number of hashes,
+// or else it returns ErrIncompatible.
+// Note that its false positive rate is higher than the one of a filter only the common keys are added to.
+func (f *CountingFilter[T]) Intersect(others ...*CountingFilter[T]) (*CountingFilter[T], error) {
+	return f.combine(others, func(counter, other uint8) uint8 {
+		if other < counter {
+			return other
+		}
+		return counter
+	})
+}
+
+// combine returns a copy of current filter whose counters are combined with the ones of `others` by `fn`.
+func (f *CountingFilter[T]) combine(others []*CountingFilter[T], fn func(counter, other uint8) uint8) (*CountingFilter[T], error) {
+	newFilter := f.Clone()
+	for _, other := range others {
+		if err := newFilter.doCombineWithoutLock(other, fn); err != nil {
+			return nil, err
+		}
+	}
+	return newFilter, nil
+}
+
+// doCombineWithoutLock combines the counters of `other` into the ones of the filter by `fn`,
+// where the filter is not shared yet, so it is not locked.
+func (f *CountingFilter[T]) doCombineWithoutLock(other *CountingFilter[T], fn func(counter, other uint8) uint8) error {
+	if other == nil {
+		return ErrIncompatible
+	}
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	if other.params != f.params {
+		return ErrIncompatible
+	}
+	for i := range f.counters {
+		f.counters[i] = fn(f.counters[i], other.counters[i])
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the metrics of the filter created with gods.WithMetrics(true).
+func (f *CountingFilter[T]) Stats() gods.Stats {
+	return f.mu.Stats()
+}
+
+// MarshalBinary implements the interface encoding.BinaryMarshaler,
+// which encodes the size, the number of hashes and the counters of the filter, but not its hasher.
+func (f *CountingFilter[T]) MarshalBinary() ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append(f.params.marshal(kindCounting, len(f.counters)), f.counters...), nil
+}
+
+// UnmarshalBinary implements the interface encoding.BinaryUnmarshaler,
+// which replaces the size, the number of hashes and the counters of the filter with the decoded ones.
+// The filter keeps its hasher, which must be the one of the encoded filter, or Default[T] if it has none.
+func (f *CountingFilter[T]) UnmarshalBinary(b []byte) error {
+	p, b, err := unmarshalParams(kindCounting, b)
+	if err != nil {
+		return err
+	}
+	if uint64(len(b)) != p.m {
+		return ErrInvalidData
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.params, f.counters = p, append([]uint8(nil), b...)
+	if f.hasher == nil {
+		f.hasher = Default[T]()
+	}
+	return nil
+}
+
+// setCounters returns the number of counters set.
+func (f *CountingFilter[T]) setCounters() uint64 {
+	var set uint64
+	for _, counter := range f.counters {
+		if counter > 0 {
+			set++
+		}
+	}
+	return set
+}
@@ -0,0 +1,95 @@
+package bloom
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/lazybabe/gods/internal/hash"
+)
+
+// Hasher returns the 64-bit hash of a key, which the filters derive all their indexes from.
+// It must return the same hash for the same key across processes, if the filters are serialized.
+type Hasher[T any] func(key T) uint64
+
+// Default returns the default hasher of keys of type T. It hashes the strings, byte slices, integers,
+// floats and booleans directly, and the other keys by their Go-syntax representation, which is slower.
+func Default[T any]() Hasher[T] {
+	return func(key T) uint64 {
+		switch k := any(key).(type) {
+		case string:
+			return String(k)
+		case []byte:
+			return Bytes(k)
+		case int:
+			return Uint64(uint64(k))
+		case int8:
+			return Uint64(uint64(k))
+		case int16:
+			return Uint64(uint64(k))
+		case int32:
+			return Uint64(uint64(k))
+		case int64:
+			return Uint64(uint64(k))
+		case uint:
+			return Uint64(uint64(k))
+		case uint8:
+			return Uint64(uint64(k))
+		case uint16:
+			return Uint64(uint64(k))
+		case uint32:
+			return Uint64(uint64(k))
+		case uint64:
+			return Uint64(k)
+		case uintptr:
+			return Uint64(uint64(k))
+		case float32:
+			// 0 and -0 are equal, so they hash the same.
+			if k == 0 {
+				return Uint64(0)
+			}
+			return Uint64(uint64(math.Float32bits(k)))
+		case float64:
+			if k == 0 {
+				return Uint64(0)
+			}
+			return Uint64(math.Float64bits(k))
+		case bool:
+			if k {
+				return Uint64(1)
+			}
+			return Uint64(0)
+		}
+		return String(fmt.Sprintf("%#v", key))
+	}
+}
+
+// FNV-1a parameters of 64 bits.
+const (
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+)
+
+// Bytes is the hasher of byte slices, which computes their FNV-1a hash.
+func Bytes(key []byte) uint64 {
+	h := uint64(offset64)
+	for _, c := range key {
+		h ^= uint64(c)
+		h *= prime64
+	}
+	return hash.Mix(h)
+}
+
+// String is the hasher of strings, which computes their FNV-1a hash.
+func String(key string) uint64 {
+	h := uint64(offset64)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= prime64
+	}
+	return hash.Mix(h)
+}
+
+// Uint64 is the hasher of 64-bit integers.
+func Uint64(key uint64) uint64 {
+	return hash.Mix(key)
+}
@@ -0,0 +1,656 @@
+// Package btree implements a B-tree, a balanced search tree whose nodes hold many sorted keys,
+// so it chases fewer pointers and is more cache-friendly than a binary tree for large collections.
+package btree
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// DefaultDegree is the degree of a tree created with a degree less than 2.
+const DefaultDegree = 32
+
+// item is a key-value pair of the tree.
+type item[K, V any] struct {
+	key   K
+	value V
+}
+
+// owner identifies the tree owning a node, which is the only one allowed to modify it in place.
+// It is not empty, so every owner allocated has its own address.
+type owner struct {
+	_ byte
+}
+
+// node is a node of the tree, which is a leaf if it has no children.
+type node[K, V any] struct {
+	items    []item[K, V]
+	children []*node[K, V]
+	owner    *owner
+}
+
+// removal is the kind of removal from the tree.
+type removal int
+
+const (
+	removeKey removal = iota
+	removeMin
+	removeMax
+)
+
+// BTree is a sorted map of keys to values, based on a B-tree.
+// Every node but the root holds from degree-1 to 2*degree-1 keys, and all leaves are at the same depth,
+// so it looks up, inserts and removes in O(log n) and iterates the keys in ascending or descending order.
+//
+// The nodes are shared by copy-on-write between a tree and its clones,
+// so Clone costs O(1), and the first writes after it copy the nodes they modify.
+type BTree[K, V any] struct {
+	mu         rwmutex.RWMutex
+	root       *node[K, V]
+	size       int
+	degree     int
+	comparator func(a, b K) int
+	// Owner of the nodes the tree is allowed to modify in place.
+	owner *owner
+}
+
+// New creates and returns an empty tree of the given `degree` whose keys are sorted by `comparator`,
+// which returns a negative number if a < b, zero if a == b, or a positive number if a > b.
+// See gods.Compare for the ordered types. It uses DefaultDegree if `degree` is less than 2.
+// The parameter `options` is used to configure the tree, see gods.Option.
+// It is not concurrent-safe in default.
+func New[K, V any](degree int, comparator func(a, b K) int, options ...gods.Option) *BTree[K, V] {
+	if comparator == nil {
+		panic("btree: nil comparator")
+	}
+	if degree < 2 {
+		degree = DefaultDegree
+	}
+	return &BTree[K, V]{
+		mu:         rwmutex.CreateWithoutCOW("btree.BTree", options),
+		degree:     degree,
+		comparator: comparator,
+		owner:      new(owner),
+	}
+}
+
+// NewFromSorted creates and returns a tree of the given `degree` loaded with the keys of `keys`,
+// which must be sorted by `comparator` in strictly ascending order, and the values returned by `valueOf`
+// for them, or the zero values if `valueOf` is nil. It builds the tree bottom-up in O(n),
+// which is faster than putting the keys one by one, and returns an error if the keys are not sorted.
+// The parameter `options` is used to configure the tree, see gods.Option.
+// It is not concurrent-safe in default.
+func NewFromSorted[K comparable, V any](degree int, comparator func(a, b K) int, keys *array.Array[K],
+	valueOf func(key K) V, options ...gods.Option) (*BTree[K, V], error) {
+	t := New[K, V](degree, comparator, options...)
+	items := make([]item[K, V], 0, keys.Size())
+	var err error
+	keys.Each(func(i int, key K) bool {
+		if i > 0 && comparator(items[i-1].key, key) >= 0 {
+			err = fmt.Errorf("key %v at index %d is not greater than the previous key %v", key, i, items[i-1].key)
+			return false
+		}
+		var value V
+		if valueOf != nil {
+			value = valueOf(key)
+		}
+		items = append(items, item[K, V]{key, value})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		height := 0
+		for maxSize(t.degree, height) < len(items) {
+			height++
+		}
+		t.root, t.size = t.build(items, height, true), len(items)
+	}
+	return t, nil
+}
+
+// Degree returns the degree of the tree.
+func (t *BTree[K, V]) Degree() int {
+	return t.degree
+}
+
+// Put sets `value` for `key`, it replaces the value if `key` is already in the tree.
+func (t *BTree[K, V]) Put(key K, value V) {
+	t.mu.Count("Put")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.doPutWithoutLock(key, value)
+}
+
+// doPutWithoutLock sets `value` for `key` without locking.
+func (t *BTree[K, V]) doPutWithoutLock(key K, value V) {
+	if t.root == nil {
+		t.root = t.newNode()
+		t.root.items = append(t.root.items, item[K, V]{key, value})
+		t.size++
+		return
+	}
+	t.root = t.mutable(t.root)
+	if len(t.root.items) >= t.maxItems() {
+		middle, second := t.split(t.root, t.maxItems()/2)
+		root := t.newNode()
+		root.items = append(root.items, middle)
+		root.children = append(root.children, t.root, second)
+		t.root = root
+	}
+	if !t.insert(t.root, item[K, V]{key, value}) {
+		t.size++
+	}
+}
+
+// Get returns the value of `key`.
+// If `key` is not in the tree, the `found` is false.
+func (t *BTree[K, V]) Get(key K) (value V, found bool) {
+	t.mu.Count("Get")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for n := t.root; n != nil; {
+		i, found := t.find(n, key)
+		if found {
+			return n.items[i].value, true
+		}
+		if len(n.children) == 0 {
+			break
+		}
+		n = n.children[i]
+	}
+	return
+}
+
+// Contains checks whether `key` is in the tree.
+func (t *BTree[K, V]) Contains(key K) bool {
+	_, found := t.Get(key)
+	return found
+}
+
+// Remove deletes `key` from the tree and returns its value.
+// If `key` is not in the tree, the `found` is false.
+func (t *BTree[K, V]) Remove(key K) (value V, found bool) {
+	t.mu.Count("Remove")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, value, found = t.delete(key, removeKey)
+	return
+}
+
+// Size returns the number of keys in the tree.
+func (t *BTree[K, V]) Size() int {
+	t.mu.Count("Size")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.size
+}
+
+// IsEmpty checks whether the tree is empty.
+func (t *BTree[K, V]) IsEmpty() bool {
+	return t.Size() == 0
+}
+
+// Clear deletes all keys of the tree.
+func (t *BTree[K, V]) Clear() {
+	t.mu.Count("Clear")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.root, t.size = nil, 0
+}
+
+// Min returns the smallest key of the tree and its value.
+// Note that if the tree is empty, the `found` is false.
+func (t *BTree[K, V]) Min() (key K, value V, found bool) {
+	t.mu.Count("Min")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	n := t.root
+	if n == nil {
+		return
+	}
+	for len(n.children) > 0 {
+		n = n.children[0]
+	}
+	return n.items[0].key, n.items[0].value, true
+}
+
+// Max returns the largest key of the tree and its value.
+// Note that if the tree is empty, the `found` is false.
+func (t *BTree[K, V]) Max() (key K, value V, found bool) {
+	t.mu.Count("Max")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	n := t.root
+	if n == nil {
+		return
+	}
+	for len(n.children) > 0 {
+		n = n.children[len(n.children)-1]
+	}
+	last := n.items[len(n.items)-1]
+	return last.key, last.value, true
+}
+
+// DeleteMin removes the smallest key of the tree and returns it with its value.
+// Note that if the tree is empty, the `found` is false.
+func (t *BTree[K, V]) DeleteMin() (key K, value V, found bool) {
+	t.mu.Count("DeleteMin")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.delete(key, removeMin)
+}
+
+// DeleteMax removes the largest key of the tree and returns it with its value.
+// Note that if the tree is empty, the `found` is false.
+func (t *BTree[K, V]) DeleteMax() (key K, value V, found bool) {
+	t.mu.Count("DeleteMax")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.delete(key, removeMax)
+}
+
+// Ascend calls `fn` on every item of the tree in ascending order of the keys,
+// if `fn` returns true then continue iterating; or false to stop.
+// The tree is locked for reading while `fn` runs, so `fn` must not modify the tree.
+func (t *BTree[K, V]) Ascend(fn func(key K, value V) bool) {
+	t.mu.Count("Ascend")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.ascend(t.root, nil, nil, fn)
+}
+
+// AscendFrom calls `fn` on every item of the tree whose key is greater than or equal to `from`
+// in ascending order of the keys, if `fn` returns true then continue iterating; or false to stop.
+// The tree is locked for reading while `fn` runs, so `fn` must not modify the tree.
+func (t *BTree[K, V]) AscendFrom(from K, fn func(key K, value V) bool) {
+	t.mu.Count("AscendFrom")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.ascend(t.root, &from, nil, fn)
+}
+
+// AscendRange calls `fn` on every item of the tree whose key is in the range [from, to)
+// in ascending order of the keys, if `fn` returns true then continue iterating; or false to stop.
+// The tree is locked for reading while `fn` runs, so `fn` must not modify the tree.
+func (t *BTree[K, V]) AscendRange(from, to K, fn func(key K, value V) bool) {
+	t.mu.Count("AscendRange")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.ascend(t.root, &from, &to, fn)
+}
+
+// Descend calls `fn` on every item of the tree in descending order of the keys,
+// if `fn` returns true then continue iterating; or false to stop.
+// The tree is locked for reading while `fn` runs, so `fn` must not modify the tree.
+func (t *BTree[K, V]) Descend(fn func(key K, value V) bool) {
+	t.mu.Count("Descend")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.descend(t.root, fn)
+}
+
+// Keys returns all keys of the tree in ascending order.
+func (t *BTree[K, V]) Keys() []K {
+	keys := make([]K, 0, t.Size())
+	t.Ascend(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Values returns all values of the tree in ascending order of their keys.
+func (t *BTree[K, V]) Values() []V {
+	values := make([]V, 0, t.Size())
+	t.Ascend(func(_ K, value V) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// Clone returns a new tree, which is a copy of current tree with the same configuration.
+// It costs O(1), as the nodes are shared until either tree modifies them.
+func (t *BTree[K, V]) Clone() *BTree[K, V] {
+	t.mu.Count("Clone")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	newTree := New[K, V](t.degree, t.comparator, t.mu.Options()...)
+	newTree.root, newTree.size = t.root, t.size
+	// Both trees lose the ownership of the shared nodes, so they copy the nodes before modifying them.
+	t.owner = new(owner)
+	return newTree
+}
+
+// Stats returns a snapshot of the metrics of the tree created with gods.WithMetrics(true).
+func (t *BTree[K, V]) Stats() gods.Stats {
+	return t.mu.Stats()
+}
+
+// String returns the items of the tree as a string in ascending order of the keys.
+func (t *BTree[K, V]) String() string {
+	out := make([]string, 0)
+	t.Ascend(func(key K, value V) bool {
+		out = append(out, fmt.Sprintf(`%v:%v`, key, value))
+		return true
+	})
+	return fmt.Sprintf("map%v", out)
+}
+
+// maxItems returns the maximum number of items of a node.
+func (t *BTree[K, V]) maxItems() int {
+	return 2*t.degree - 1
+}
+
+// minItems returns the minimum number of items of a node other than the root.
+func (t *BTree[K, V]) minItems() int {
+	return t.degree - 1
+}
+
+// newNode returns an empty node owned by the tree, with room for the maximum number of items.
+func (t *BTree[K, V]) newNode() *node[K, V] {
+	return &node[K, V]{
+		items: make([]item[K, V], 0, t.maxItems()),
+		owner: t.owner,
+	}
+}
+
+// mutable returns `n` if it is owned by the tree, or else a copy of it owned by the tree.
+func (t *BTree[K, V]) mutable(n *node[K, V]) *node[K, V] {
+	if n.owner == t.owner {
+		return n
+	}
+	c := t.newNode()
+	c.items = append(c.items, n.items...)
+	if len(n.children) > 0 {
+		c.children = make([]*node[K, V], len(n.children), t.maxItems()+1)
+		copy(c.children, n.children)
+	}
+	return c
+}
+
+// mutableChild returns the i-th child of `n` after making it mutable, where `n` must be mutable.
+func (t *BTree[K, V]) mutableChild(n *node[K, V], i int) *node[K, V] {
+	c := t.mutable(n.children[i])
+	n.children[i] = c
+	return c
+}
+
+// find returns the index of the first item of `n` whose key is greater than or equal to `key`,
+// and whether the key of the item equals `key`.
+func (t *BTree[K, V]) find(n *node[K, V], key K) (int, bool) {
+	i := sort.Search(len(n.items), func(i int) bool {
+		return t.comparator(n.items[i].key, key) >= 0
+	})
+	return i, i < len(n.items) && t.comparator(n.items[i].key, key) == 0
+}
+
+// split splits the mutable node `n` at the i-th item, it returns the item and a new node
+// with the items and children after it, leaving the ones before it in `n`.
+func (t *BTree[K, V]) split(n *node[K, V], i int) (item[K, V], *node[K, V]) {
+	middle := n.items[i]
+	second := t.newNode()
+	second.items = append(second.items, n.items[i+1:]...)
+	n.items = truncate(n.items, i)
+	if len(n.children) > 0 {
+		second.children = make([]*node[K, V], 0, t.maxItems()+1)
+		second.children = append(second.children, n.children[i+1:]...)
+		n.children = truncate(n.children, i+1)
+	}
+	return middle, second
+}
+
+// insert inserts `it` into the subtree rooted at the mutable node `n`, which is not full,
+// and reports whether it replaces the value of an existing key.
+func (t *BTree[K, V]) insert(n *node[K, V], it item[K, V]) bool {
+	i, found := t.find(n, it.key)
+	if found {
+		n.items[i].value = it.value
+		return true
+	}
+	if len(n.children) == 0 {
+		n.items = insertAt(n.items, i, it)
+		return false
+	}
+	if len(n.children[i].items) >= t.maxItems() {
+		middle, second := t.split(t.mutableChild(n, i), t.maxItems()/2)
+		n.items = insertAt(n.items, i, middle)
+		n.children = insertAt(n.children, i+1, second)
+		switch cmp := t.comparator(it.key, middle.key); {
+		case cmp > 0:
+			i++
+		case cmp == 0:
+			n.items[i].value = it.value
+			return true
+		}
+	}
+	return t.insert(t.mutableChild(n, i), it)
+}
+
+// delete removes the item of `key`, the smallest or the largest item as specified by `kind`,
+// and returns its key and value.
+func (t *BTree[K, V]) delete(key K, kind removal) (deleted K, value V, found bool) {
+	if t.root == nil {
+		return
+	}
+	t.root = t.mutable(t.root)
+	it, found := t.remove(t.root, key, kind)
+	if len(t.root.items) == 0 {
+		if len(t.root.children) > 0 {
+			t.root = t.root.children[0]
+		} else {
+			t.root = nil
+		}
+	}
+	if !found {
+		return
+	}
+	t.size--
+	return it.key, it.value, true
+}
+
+// remove removes an item as specified by `key` and `kind` from the subtree rooted at the mutable node `n`.
+func (t *BTree[K, V]) remove(n *node[K, V], key K, kind removal) (it item[K, V], found bool) {
+	var i int
+	switch kind {
+	case removeMin:
+		if len(n.children) == 0 {
+			n.items, it = removeAt(n.items, 0)
+			return it, true
+		}
+	case removeMax:
+		if len(n.children) == 0 {
+			n.items, it = removeAt(n.items, len(n.items)-1)
+			return it, true
+		}
+		i = len(n.items)
+	default:
+		i, found = t.find(n, key)
+		if len(n.children) == 0 {
+			if found {
+				n.items, it = removeAt(n.items, i)
+			}
+			return it, found
+		}
+	}
+	// Make sure the child to descend into can lose an item.
+	if len(n.children[i].items) <= t.minItems() {
+		t.grow(n, i)
+		return t.remove(n, key, kind)
+	}
+	child := t.mutableChild(n, i)
+	if found {
+		// Replace the item with its predecessor, which is the largest item of the left child.
+		it = n.items[i]
+		n.items[i], _ = t.remove(child, key, removeMax)
+		return it, true
+	}
+	return t.remove(child, key, kind)
+}
+
+// grow adds an item to the i-th child of the mutable node `n`, which has the minimum number of items,
+// by stealing one from a sibling, or else merging it with a sibling.
+func (t *BTree[K, V]) grow(n *node[K, V], i int) {
+	switch {
+	case i > 0 && len(n.children[i-1].items) > t.minItems():
+		// Rotate the largest item of the left sibling through the parent.
+		child, left := t.mutableChild(n, i), t.mutableChild(n, i-1)
+		var stolen item[K, V]
+		left.items, stolen = removeAt(left.items, len(left.items)-1)
+		child.items = insertAt(child.items, 0, n.items[i-1])
+		n.items[i-1] = stolen
+		if len(left.children) > 0 {
+			var grandchild *node[K, V]
+			left.children, grandchild = removeAt(left.children, len(left.children)-1)
+			child.children = insertAt(child.children, 0, grandchild)
+		}
+	case i < len(n.items) && len(n.children[i+1].items) > t.minItems():
+		// Rotate the smallest item of the right sibling through the parent.
+		child, right := t.mutableChild(n, i), t.mutableChild(n, i+1)
+		var stolen item[K, V]
+		right.items, stolen = removeAt(right.items, 0)
+		child.items = append(child.items, n.items[i])
+		n.items[i] = stolen
+		if len(right.children) > 0 {
+			var grandchild *node[K, V]
+			right.children, grandchild = removeAt(right.children, 0)
+			child.children = append(child.children, grandchild)
+		}
+	default:
+		// Merge the child with its right sibling and the item between them, or with its left one if it is the last.
+		if i >= len(n.items) {
+			i--
+		}
+		child := t.mutableChild(n, i)
+		var middle item[K, V]
+		var right *node[K, V]
+		n.items, middle = removeAt(n.items, i)
+		n.children, right = removeAt(n.children, i+1)
+		child.items = append(child.items, middle)
+		child.items = append(child.items, right.items...)
+		child.children = append(child.children, right.children...)
+	}
+}
+
+// ascend calls `fn` on the items of the subtree rooted at `n` in ascending order, starting at `from`
+// and stopping before `to` unless they are nil, it returns false if the iteration is stopped.
+func (t *BTree[K, V]) ascend(n *node[K, V], from, to *K, fn func(key K, value V) bool) bool {
+	if n == nil {
+		return true
+	}
+	i := 0
+	if from != nil {
+		i, _ = t.find(n, *from)
+	}
+	for ; i < len(n.items); i++ {
+		if len(n.children) > 0 && !t.ascend(n.children[i], from, to, fn) {
+			return false
+		}
+		it := n.items[i]
+		if to != nil && t.comparator(it.key, *to) >= 0 {
+			return false
+		}
+		if !fn(it.key, it.value) {
+			return false
+		}
+	}
+	if len(n.children) > 0 {
+		return t.ascend(n.children[len(n.children)-1], from, to, fn)
+	}
+	return true
+}
+
+// descend calls `fn` on the items of the subtree rooted at `n` in descending order,
+// it returns false if the iteration is stopped.
+func (t *BTree[K, V]) descend(n *node[K, V], fn func(key K, value V) bool) bool {
+	if n == nil {
+		return true
+	}
+	for i := len(n.items) - 1; i >= 0; i-- {
+		if len(n.children) > 0 && !t.descend(n.children[i+1], fn) {
+			return false
+		}
+		if !fn(n.items[i].key, n.items[i].value) {
+			return false
+		}
+	}
+	if len(n.children) > 0 {
+		return t.descend(n.children[0], fn)
+	}
+	return true
+}
+
+// build returns a subtree of the given `height` holding the sorted `items`,
+// whose number is within the bounds of the subtrees of that height, see minSize and maxSize.
+// The root of the tree may have fewer items than the other nodes.
+func (t *BTree[K, V]) build(items []item[K, V], height int, root bool) *node[K, V] {
+	n := t.newNode()
+	if height == 0 {
+		n.items = append(n.items, items...)
+		return n
+	}
+	// Use as few children as the maximum size of the subtrees allows, and at least the minimum.
+	slots := maxSize(t.degree, height-1) + 1
+	children := (len(items) + slots) / slots
+	if !root && children < t.degree {
+		children = t.degree
+	}
+	n.children = make([]*node[K, V], 0, t.maxItems()+1)
+	// Split the items evenly, every child and the item after it take (len(items)+1)/children items.
+	start := 0
+	for c := 0; c < children; c++ {
+		end := start + (len(items)+1)*(c+1)/children - (len(items)+1)*c/children - 1
+		n.children = append(n.children, t.build(items[start:end], height-1, false))
+		if end < len(items) {
+			n.items = append(n.items, items[end])
+		}
+		start = end + 1
+	}
+	return n
+}
+
+// maxSize returns the maximum number of items of a subtree of the given `height` and `degree`,
+// which is (2*degree)^(height+1)-1, saturated to the largest int.
+func maxSize(degree int, height int) int {
+	const maxInt = int(^uint(0) >> 1)
+	size := 1
+	for h := 0; h <= height; h++ {
+		if size > maxInt/(2*degree) {
+			return maxInt
+		}
+		size *= 2 * degree
+	}
+	return size - 1
+}
+
+// insertAt inserts `value` at the index `i` of `s`.
+func insertAt[T any](s []T, i int, value T) []T {
+	var zero T
+	s = append(s, zero)
+	copy(s[i+1:], s[i:])
+	s[i] = value
+	return s
+}
+
+// removeAt removes and returns the value at the index `i` of `s`,
+// clearing the freed slot so it does not retain the value.
+func removeAt[T any](s []T, i int) ([]T, T) {
+	value := s[i]
+	copy(s[i:], s[i+1:])
+	return truncate(s, len(s)-1), value
+}
+
+// truncate returns the first `n` elements of `s`, clearing the slots after them.
+func truncate[T any](s []T, n int) []T {
+	var zero T
+	for i := n; i < len(s); i++ {
+		s[i] = zero
+	}
+	return s[:n]
+}
@@ -0,0 +1,264 @@
+package btree_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/btree"
+)
+
+func TestBTree(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "BTree Suite")
+}
+
+// sortedKeys returns the keys of `m` in ascending order.
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// seq returns the integers in [0, n).
+func seq(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}
+
+// mustGet returns `value` and checks that it is found.
+func mustGet[V any](value V, found bool) V {
+	ExpectWithOffset(1, found).To(BeTrue())
+	return value
+}
+
+var _ = Describe("BTree", func() {
+	It("Put, Get and Remove", func() {
+		t := btree.New[int, string](2, gods.Compare[int])
+		Expect(t.Degree()).To(Equal(2))
+		Expect(t.IsEmpty()).To(BeTrue())
+		for _, k := range []int{5, 1, 9, 3, 7} {
+			t.Put(k, string(rune('a'+k)))
+		}
+		t.Put(3, "D")
+		Expect(t.Size()).To(Equal(5))
+		Expect(t.Keys()).To(Equal([]int{1, 3, 5, 7, 9}))
+		Expect(t.Values()).To(Equal([]string{"b", "D", "f", "h", "j"}))
+		Expect(t.String()).To(Equal("map[1:b 3:D 5:f 7:h 9:j]"))
+
+		v, found := t.Get(3)
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal("D"))
+		Expect(t.Contains(4)).To(BeFalse())
+
+		v, found = t.Remove(5)
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal("f"))
+		_, found = t.Remove(5)
+		Expect(found).To(BeFalse())
+		Expect(t.Keys()).To(Equal([]int{1, 3, 7, 9}))
+		Expect(t.Check()).To(Succeed())
+
+		t.Clear()
+		Expect(t.Size()).To(BeZero())
+		Expect(t.Keys()).To(BeEmpty())
+		_, found = t.Remove(1)
+		Expect(found).To(BeFalse())
+	})
+
+	It("Default degree", func() {
+		Expect(btree.New[int, int](0, gods.Compare[int]).Degree()).To(Equal(btree.DefaultDegree))
+		Expect(func() { btree.New[int, int](2, nil) }).To(Panic())
+	})
+
+	It("Min, Max, DeleteMin and DeleteMax", func() {
+		t := btree.New[int, int](2, gods.Compare[int])
+		_, _, found := t.Min()
+		Expect(found).To(BeFalse())
+		_, _, found = t.DeleteMax()
+		Expect(found).To(BeFalse())
+		for i := 0; i < 100; i++ {
+			t.Put(i, -i)
+		}
+		key, value, found := t.Min()
+		Expect(found).To(BeTrue())
+		Expect(key).To(Equal(0))
+		Expect(value).To(Equal(0))
+		key, value, found = t.Max()
+		Expect(found).To(BeTrue())
+		Expect(key).To(Equal(99))
+		Expect(value).To(Equal(-99))
+		for i := 0; i < 50; i++ {
+			key, value, found = t.DeleteMin()
+			Expect(found).To(BeTrue())
+			Expect(key).To(Equal(i))
+			Expect(value).To(Equal(-i))
+			key, _, found = t.DeleteMax()
+			Expect(found).To(BeTrue())
+			Expect(key).To(Equal(99 - i))
+			Expect(t.Check()).To(Succeed())
+		}
+		Expect(t.IsEmpty()).To(BeTrue())
+	})
+
+	It("Ascend, Descend and ranges", func() {
+		t := btree.New[int, int](2, gods.Compare[int])
+		for i := 0; i < 50; i++ {
+			t.Put(i*2, i)
+		}
+		collect := func(each func(fn func(int, int) bool), limit int) []int {
+			keys := make([]int, 0)
+			each(func(k int, _ int) bool {
+				keys = append(keys, k)
+				return len(keys) < limit
+			})
+			return keys
+		}
+		Expect(collect(t.Ascend, 1000)).To(HaveLen(50))
+		Expect(collect(t.Ascend, 3)).To(Equal([]int{0, 2, 4}))
+		Expect(collect(t.Descend, 3)).To(Equal([]int{98, 96, 94}))
+		Expect(collect(t.Descend, 1000)).To(HaveLen(50))
+		from := func(from int) func(fn func(int, int) bool) {
+			return func(fn func(int, int) bool) { t.AscendFrom(from, fn) }
+		}
+		Expect(collect(from(93), 1000)).To(Equal([]int{94, 96, 98}))
+		Expect(collect(from(40), 2)).To(Equal([]int{40, 42}))
+		Expect(collect(from(99), 1000)).To(BeEmpty())
+		between := func(from, to int) func(fn func(int, int) bool) {
+			return func(fn func(int, int) bool) { t.AscendRange(from, to, fn) }
+		}
+		Expect(collect(between(10, 20), 1000)).To(Equal([]int{10, 12, 14, 16, 18}))
+		Expect(collect(between(9, 21), 1000)).To(Equal([]int{10, 12, 14, 16, 18, 20}))
+		Expect(collect(between(9, 21), 2)).To(Equal([]int{10, 12}))
+		Expect(collect(between(20, 10), 1000)).To(BeEmpty())
+	})
+
+	It("NewFromSorted", func() {
+		for _, degree := range []int{2, 3, 4, 7} {
+			for _, n := range []int{0, 1, 2, 3, 5, 6, 7, 8, 15, 16, 17, 63, 64, 65, 100, 343, 1000} {
+				keys := array.NewFrom(seq(n))
+				t, err := btree.NewFromSorted(degree, gods.Compare[int], keys, func(k int) int { return k * k })
+				Expect(err).NotTo(HaveOccurred())
+				Expect(t.Check()).To(Succeed(), "degree %d, %d keys", degree, n)
+				Expect(t.Keys()).To(Equal(seq(n)))
+				Expect(t.Size()).To(Equal(n))
+				if n > 0 {
+					value, found := t.Get(n - 1)
+					Expect(found).To(BeTrue())
+					Expect(value).To(Equal((n - 1) * (n - 1)))
+				}
+				t.Put(n, 0)
+				t.Remove(0)
+				Expect(t.Check()).To(Succeed())
+			}
+		}
+
+		t, err := btree.NewFromSorted[int, struct{}](2, gods.Compare[int], array.NewFrom([]int{1, 2}), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Keys()).To(Equal([]int{1, 2}))
+
+		_, err = btree.NewFromSorted[int, int](2, gods.Compare[int], array.NewFrom([]int{1, 3, 3}), nil)
+		Expect(err).To(MatchError("key 3 at index 2 is not greater than the previous key 3"))
+		_, err = btree.NewFromSorted[int, int](2, gods.Compare[int], array.NewFrom([]int{2, 1}), nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Clone", func() {
+		t := btree.New[int, int](2, gods.Compare[int], gods.WithSafe(true))
+		for i := 0; i < 100; i++ {
+			t.Put(i, i)
+		}
+		c := t.Clone()
+		c.Put(100, 100)
+		c.Put(5, -5)
+		t.Remove(0)
+		Expect(t.Keys()).To(Equal(seq(100)[1:]))
+		Expect(c.Keys()).To(Equal(seq(101)))
+		Expect(mustGet(t.Get(5))).To(Equal(5))
+		Expect(mustGet(c.Get(5))).To(Equal(-5))
+		Expect(t.Check()).To(Succeed())
+		Expect(c.Check()).To(Succeed())
+	})
+
+	It("Stats", func() {
+		t := btree.New[int, int](2, gods.Compare[int], gods.WithMetrics(true), gods.WithLocker(gods.LockMutex))
+		t.Put(1, 1)
+		t.Get(1)
+		Expect(t.Stats().Operations).To(HaveKeyWithValue("Put", uint64(1)))
+		Expect(t.Stats().Operations).To(HaveKeyWithValue("Get", uint64(1)))
+		Expect(t.Stats().WriteLocks).To(Equal(uint64(1)))
+		Expect(t.Stats().ReadLocks).To(Equal(uint64(1)))
+		c := t.Clone()
+		c.Put(2, 2)
+		Expect(c.Stats().Operations).To(Equal(map[string]uint64{"Put": 1}))
+		Expect(func() { btree.New[int, int](2, gods.Compare[int], gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: btree.BTree does not support LockCopyOnWrite"))
+	})
+
+	DescribeTable("keeps the B-tree properties after random operations",
+		func(degree int) {
+			r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+			t := btree.New[int, int](degree, gods.Compare[int])
+			m := make(map[int]int)
+			// Clones and their models, which must not be changed by the writes to the tree.
+			var clones []*btree.BTree[int, int]
+			var models []map[int]int
+			for i := 0; i < 5000; i++ {
+				key := r.Intn(400)
+				switch n := r.Intn(20); {
+				case n < 6:
+					value, found := t.Remove(key)
+					want, ok := m[key]
+					Expect(found).To(Equal(ok))
+					Expect(value).To(Equal(want))
+					delete(m, key)
+				case n < 8:
+					if k, _, found := t.DeleteMin(); found {
+						Expect(k).To(Equal(sortedKeys(m)[0]))
+						delete(m, k)
+					}
+				case n < 10:
+					if k, _, found := t.DeleteMax(); found {
+						keys := sortedKeys(m)
+						Expect(k).To(Equal(keys[len(keys)-1]))
+						delete(m, k)
+					}
+				case n == 10:
+					clones = append(clones, t.Clone())
+					model := make(map[int]int, len(m))
+					for k, v := range m {
+						model[k] = v
+					}
+					models = append(models, model)
+				default:
+					t.Put(key, i)
+					m[key] = i
+				}
+				Expect(t.Check()).To(Succeed())
+				Expect(t.Size()).To(Equal(len(m)))
+			}
+			Expect(t.Keys()).To(Equal(sortedKeys(m)))
+			for i, c := range clones {
+				Expect(c.Check()).To(Succeed())
+				Expect(c.Keys()).To(Equal(sortedKeys(models[i])))
+				for k, v := range models[i] {
+					Expect(mustGet(c.Get(k))).To(Equal(v))
+				}
+			}
+		},
+		Entry("degree 2", 2),
+		Entry("degree 3", 3),
+		Entry("degree 8", 8),
+	)
+})
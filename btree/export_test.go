@@ -0,0 +1,63 @@
+package btree
+
+import "fmt"
+
+// Check checks the tree against the properties of the B-tree,
+// it returns an error describing the first violation.
+func (t *BTree[K, V]) Check() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.root == nil {
+		if t.size != 0 {
+			return fmt.Errorf("size %d of an empty tree", t.size)
+		}
+		return nil
+	}
+	if len(t.root.items) == 0 {
+		return fmt.Errorf("empty root")
+	}
+	size, _, err := t.check(t.root, nil, nil, true)
+	if err == nil && size != t.size {
+		err = fmt.Errorf("size %d of %d items", t.size, size)
+	}
+	return err
+}
+
+// check checks the subtree rooted at `n` whose keys are in the range (lo, hi),
+// it returns the number of its items and its height.
+func (t *BTree[K, V]) check(n *node[K, V], lo, hi *K, root bool) (size int, height int, err error) {
+	if len(n.items) > t.maxItems() || !root && len(n.items) < t.minItems() {
+		return 0, 0, fmt.Errorf("node of %d items", len(n.items))
+	}
+	for i, it := range n.items {
+		if i > 0 && t.comparator(n.items[i-1].key, it.key) >= 0 ||
+			lo != nil && t.comparator(*lo, it.key) >= 0 || hi != nil && t.comparator(it.key, *hi) >= 0 {
+			return 0, 0, fmt.Errorf("key %v is out of order", it.key)
+		}
+	}
+	size = len(n.items)
+	if len(n.children) == 0 {
+		return size, 0, nil
+	}
+	if len(n.children) != len(n.items)+1 {
+		return 0, 0, fmt.Errorf("node of %d items has %d children", len(n.items), len(n.children))
+	}
+	for i, child := range n.children {
+		childLo, childHi := lo, hi
+		if i > 0 {
+			childLo = &n.items[i-1].key
+		}
+		if i < len(n.items) {
+			childHi = &n.items[i].key
+		}
+		childSize, childHeight, err := t.check(child, childLo, childHi, false)
+		if err != nil {
+			return 0, 0, err
+		}
+		if i > 0 && childHeight != height-1 {
+			return 0, 0, fmt.Errorf("leaves at different depths")
+		}
+		size, height = size+childSize, childHeight+1
+	}
+	return size, height, nil
+}
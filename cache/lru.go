@@ -0,0 +1,278 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// LRU is a fixed capacity cache which evicts the least recently used items first.
+// The capacity limits either the number of items or, if a cost function is given,
+// the total cost of the items.
+type LRU[K comparable, V any] struct {
+	mu       rwmutex.RWMutex
+	items    map[K]*entry[K, V]
+	root     entry[K, V] // Sentinel of the recency list, root.next is the most recently used.
+	capacity int
+	cost     int
+	costFunc func(key K, value V) int
+	onEvict  func(key K, value V)
+	stats    Stats
+}
+
+// entry is an item of the recency list.
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	cost       int
+	prev, next *entry[K, V]
+}
+
+// Stats is the hit and miss statistics of a cache.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRatio returns the ratio of hits to all lookups, or 0 if there is no lookup.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// New creates and returns an empty cache holding at most `capacity` items.
+// The parameter `options` is used to configure the cache, see gods.Option.
+// It is not concurrent-safe in default.
+func New[K comparable, V any](capacity int, options ...gods.Option) *LRU[K, V] {
+	return NewWithCost[K, V](capacity, nil, options...)
+}
+
+// NewWithCost creates and returns an empty cache whose items cost at most `capacity` in total.
+// The cost of an item is computed by `costFunc` when it is set, a nil `costFunc` costs 1 for every item.
+// The parameter `options` is used to configure the cache, see gods.Option.
+// It is not concurrent-safe in default.
+func NewWithCost[K comparable, V any](capacity int, costFunc func(key K, value V) int,
+	options ...gods.Option) *LRU[K, V] {
+	c := &LRU[K, V]{
+		mu:       rwmutex.CreateWithoutCOW("cache.LRU", options),
+		items:    make(map[K]*entry[K, V]),
+		capacity: capacity,
+		costFunc: costFunc,
+	}
+	c.root.prev, c.root.next = &c.root, &c.root
+	return c
+}
+
+// OnEvict sets the callback `fn` called with every item evicted to respect the capacity.
+// It is not called for items deleted by Remove or Clear.
+// The callback runs after the cache is unlocked, so it is free to use the cache.
+func (c *LRU[K, V]) OnEvict(fn func(key K, value V)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onEvict = fn
+}
+
+// Get returns the value of `key` and marks it as the most recently used.
+// If `key` is not in the cache, the `found` is false.
+func (c *LRU[K, V]) Get(key K) (value V, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return value, false
+	}
+	c.stats.Hits++
+	c.moveToFront(e)
+	return e.value, true
+}
+
+// Peek returns the value of `key` without updating its recency or the statistics.
+// If `key` is not in the cache, the `found` is false.
+func (c *LRU[K, V]) Peek(key K) (value V, found bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.items[key]
+	if !ok {
+		return value, false
+	}
+	return e.value, true
+}
+
+// Contains checks whether `key` is in the cache without updating its recency.
+func (c *LRU[K, V]) Contains(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.items[key]
+	return ok
+}
+
+// Set sets `value` for `key` and marks it as the most recently used,
+// evicting the least recently used items until the cache fits its capacity.
+// An item costing more than the capacity is not stored, and the old value of `key` if any is evicted.
+// It panics if the cost of the item is negative.
+func (c *LRU[K, V]) Set(key K, value V) {
+	cost := 1
+	if c.costFunc != nil {
+		cost = c.costFunc(key, value)
+	}
+	if cost < 0 {
+		panic(fmt.Sprintf("cache: negative cost %d of key %v", cost, key))
+	}
+	c.mu.Lock()
+	if cost > c.capacity {
+		// The item never fits, keeping it would evict everything else.
+		var evicted []*entry[K, V]
+		if e, ok := c.items[key]; ok {
+			c.unlink(e)
+			evicted = append(evicted, e)
+		}
+		onEvict := c.onEvict
+		c.mu.Unlock()
+		notify(evicted, onEvict)
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		c.cost += cost - e.cost
+		e.value, e.cost = value, cost
+		c.moveToFront(e)
+	} else {
+		e = &entry[K, V]{key: key, value: value, cost: cost}
+		c.items[key] = e
+		c.cost += cost
+		c.pushFront(e)
+	}
+	evicted, onEvict := c.doEvictWithoutLock(), c.onEvict
+	c.mu.Unlock()
+	notify(evicted, onEvict)
+}
+
+// Remove deletes `key` from the cache.
+// It returns true if `key` is found in the cache, or else false if not found.
+func (c *LRU[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.unlink(e)
+	return true
+}
+
+// Resize changes the capacity of the cache,
+// evicting the least recently used items until the cache fits the new capacity.
+// It returns the number of evicted items.
+func (c *LRU[K, V]) Resize(capacity int) int {
+	c.mu.Lock()
+	c.capacity = capacity
+	evicted, onEvict := c.doEvictWithoutLock(), c.onEvict
+	c.mu.Unlock()
+	notify(evicted, onEvict)
+	return len(evicted)
+}
+
+// Keys returns the keys of the cache from the most to the least recently used.
+func (c *LRU[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]K, 0, len(c.items))
+	for e := c.root.next; e != &c.root; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Size returns the number of items in the cache.
+func (c *LRU[K, V]) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// Cost returns the total cost of the items in the cache,
+// which equals to Size if there is no cost function.
+func (c *LRU[K, V]) Cost() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cost
+}
+
+// Capacity returns the capacity of the cache.
+func (c *LRU[K, V]) Capacity() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.capacity
+}
+
+// Clear deletes all items of the cache, the statistics are kept.
+func (c *LRU[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[K]*entry[K, V])
+	c.root.prev, c.root.next = &c.root, &c.root
+	c.cost = 0
+}
+
+// Stats returns a snapshot of the hit and miss statistics of Get.
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stats
+}
+
+// ResetStats resets the hit and miss statistics to zero.
+func (c *LRU[K, V]) ResetStats() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = Stats{}
+}
+
+// doEvictWithoutLock evicts the least recently used items until the cache fits its capacity
+// without lock, and returns the evicted items.
+func (c *LRU[K, V]) doEvictWithoutLock() (evicted []*entry[K, V]) {
+	for c.cost > c.capacity && c.root.prev != &c.root {
+		e := c.root.prev
+		c.unlink(e)
+		evicted = append(evicted, e)
+	}
+	return evicted
+}
+
+// pushFront links `e` as the most recently used.
+func (c *LRU[K, V]) pushFront(e *entry[K, V]) {
+	e.prev, e.next = &c.root, c.root.next
+	c.root.next.prev = e
+	c.root.next = e
+}
+
+// moveToFront marks the linked `e` as the most recently used.
+func (c *LRU[K, V]) moveToFront(e *entry[K, V]) {
+	if c.root.next == e {
+		return
+	}
+	e.prev.next, e.next.prev = e.next, e.prev
+	c.pushFront(e)
+}
+
+// unlink deletes `e` from both the recency list and the items.
+func (c *LRU[K, V]) unlink(e *entry[K, V]) {
+	e.prev.next, e.next.prev = e.next, e.prev
+	e.prev, e.next = nil, nil
+	delete(c.items, e.key)
+	c.cost -= e.cost
+}
+
+// notify calls `onEvict` with every evicted item from the least recently used.
+func notify[K comparable, V any](evicted []*entry[K, V], onEvict func(key K, value V)) {
+	if onEvict == nil {
+		return
+	}
+	for _, e := range evicted {
+		onEvict(e.key, e.value)
+	}
+}
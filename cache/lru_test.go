@@ -0,0 +1,177 @@
+package cache_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/cache"
+)
+
+func TestCache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cache Suite")
+}
+
+var _ = Describe("LRU", func() {
+	It("Get|Set", func() {
+		c := cache.New[string, int](2)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		v, found := c.Get("a")
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal(1))
+		c.Set("c", 3)
+		_, found = c.Get("b")
+		Expect(found).To(BeFalse())
+		Expect(c.Keys()).To(Equal([]string{"c", "a"}))
+		c.Set("a", 10)
+		v, _ = c.Get("a")
+		Expect(v).To(Equal(10))
+		Expect(c.Size()).To(Equal(2))
+	})
+
+	It("Peek", func() {
+		c := cache.New[string, int](2)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		v, found := c.Peek("a")
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal(1))
+		_, found = c.Peek("c")
+		Expect(found).To(BeFalse())
+		Expect(c.Keys()).To(Equal([]string{"b", "a"}))
+		Expect(c.Stats()).To(Equal(cache.Stats{}))
+		Expect(c.Contains("a")).To(BeTrue())
+	})
+
+	It("Remove", func() {
+		c := cache.New[string, int](2)
+		c.Set("a", 1)
+		Expect(c.Remove("a")).To(BeTrue())
+		Expect(c.Remove("a")).To(BeFalse())
+		Expect(c.Size()).To(BeZero())
+		Expect(c.Keys()).To(BeEmpty())
+	})
+
+	It("OnEvict", func() {
+		var evicted []string
+		c := cache.New[string, int](2)
+		c.OnEvict(func(key string, _ int) {
+			evicted = append(evicted, key)
+			// The cache is unlocked while calling back.
+			Expect(c.Size()).To(Equal(2))
+		})
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Set("c", 3)
+		c.Remove("b")
+		Expect(evicted).To(Equal([]string{"a"}))
+	})
+
+	It("Cost", func() {
+		var evicted []string
+		c := cache.NewWithCost(10, func(_ string, v []byte) int { return len(v) })
+		c.OnEvict(func(key string, _ []byte) { evicted = append(evicted, key) })
+		c.Set("a", make([]byte, 4))
+		c.Set("b", make([]byte, 4))
+		Expect(c.Cost()).To(Equal(8))
+		c.Set("c", make([]byte, 4))
+		Expect(c.Cost()).To(Equal(8))
+		Expect(evicted).To(Equal([]string{"a"}))
+		c.Set("b", make([]byte, 1))
+		Expect(c.Cost()).To(Equal(5))
+		c.Set("d", make([]byte, 11))
+		Expect(c.Contains("d")).To(BeFalse())
+		Expect(evicted).To(Equal([]string{"a"}))
+		Expect(c.Keys()).To(Equal([]string{"b", "c"}))
+	})
+
+	It("Oversized overwrite", func() {
+		var evicted [][]byte
+		c := cache.NewWithCost(10, func(_ string, v []byte) int { return len(v) })
+		c.OnEvict(func(_ string, v []byte) { evicted = append(evicted, v) })
+		c.Set("a", []byte("old"))
+		c.Set("a", make([]byte, 11))
+		Expect(c.Contains("a")).To(BeFalse())
+		Expect(c.Cost()).To(BeZero())
+		Expect(evicted).To(Equal([][]byte{[]byte("old")}))
+	})
+
+	It("Negative cost", func() {
+		c := cache.NewWithCost(10, func(_ string, v int) int { return v })
+		Expect(func() { c.Set("a", -1) }).To(PanicWith("cache: negative cost -1 of key a"))
+		c.Set("a", 1)
+		Expect(c.Cost()).To(Equal(1))
+	})
+
+	It("Resize", func() {
+		var evicted []int
+		c := cache.New[int, int](5)
+		c.OnEvict(func(key int, _ int) { evicted = append(evicted, key) })
+		for i := 0; i < 5; i++ {
+			c.Set(i, i)
+		}
+		Expect(c.Resize(2)).To(Equal(3))
+		Expect(c.Capacity()).To(Equal(2))
+		Expect(evicted).To(Equal([]int{0, 1, 2}))
+		Expect(c.Keys()).To(Equal([]int{4, 3}))
+		Expect(c.Resize(4)).To(BeZero())
+		c.Set(5, 5)
+		Expect(c.Size()).To(Equal(3))
+	})
+
+	It("Stats", func() {
+		c := cache.New[int, int](1)
+		c.Set(1, 1)
+		c.Get(1)
+		c.Get(1)
+		c.Get(1)
+		c.Get(2)
+		stats := c.Stats()
+		Expect(stats).To(Equal(cache.Stats{Hits: 3, Misses: 1}))
+		Expect(stats.HitRatio()).To(Equal(0.75))
+		c.ResetStats()
+		Expect(c.Stats().HitRatio()).To(BeZero())
+	})
+
+	It("Clear", func() {
+		c := cache.New[int, int](2)
+		c.Set(1, 1)
+		c.Set(2, 2)
+		c.Clear()
+		Expect(c.Size()).To(BeZero())
+		Expect(c.Cost()).To(BeZero())
+		c.Set(3, 3)
+		Expect(c.Keys()).To(Equal([]int{3}))
+	})
+
+	It("Unsupported options", func() {
+		Expect(func() { cache.New[int, int](2, gods.WithCapacity(2)) }).
+			To(PanicWith("gods: cache.LRU does not support WithCapacity"))
+		Expect(func() { cache.New[int, int](2, gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: cache.LRU does not support LockCopyOnWrite"))
+	})
+
+	It("Concurrent", func() {
+		c := cache.New[int, int](64, gods.WithSafe(true))
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					c.Set(g*1000+i, i)
+					c.Get(g*1000 + i/2)
+				}
+			}(g)
+		}
+		wg.Wait()
+		Expect(c.Size()).To(Equal(64))
+		Expect(c.Stats().Hits + c.Stats().Misses).To(Equal(uint64(8000)))
+	})
+})
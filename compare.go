@@ -0,0 +1,13 @@
+package gods
+
+import "cmp"
+
+// Ordered is a constraint that permits any ordered type: any type that supports the operators < <= >= >.
+// It is cmp.Ordered.
+type Ordered = cmp.Ordered
+
+// Compare is the comparator of the ordered types, which returns -1 if a < b, 0 if a == b,
+// or +1 if a > b. A NaN is less than any non-NaN, and equal to a NaN. It is cmp.Compare.
+func Compare[T Ordered](a, b T) int {
+	return cmp.Compare(a, b)
+}
@@ -0,0 +1,62 @@
+package gods
+
+// Container is the interface implemented by all the containers of items of type T,
+// so the code accepting any container does not depend on the concrete one.
+//
+// The containers keep their own Clear and Clone, whose signatures differ: array.Array returns itself
+// from Clear for chaining, and Clone returns the concrete type. Reset and Copy are their forms shared
+// by all the containers.
+//
+// The maps of keys to values, such as rbtree.Tree, btree.BTree, hashmap.Map and interval.Tree,
+// are not containers, as their items are key-value pairs, which they return by Keys and Values
+// rather than by Slice.
+type Container[T any] interface {
+	// Size returns the number of items in the container.
+	Size() int
+	// IsEmpty checks whether the container has no items.
+	IsEmpty() bool
+	// Slice returns the items of the container as a slice.
+	Slice() []T
+	// String returns the items of the container as a string.
+	String() string
+	// Reset deletes all items of the container, like its Clear.
+	Reset()
+	// Copy returns a new container with the items of the container, like its Clone.
+	Copy() Container[T]
+}
+
+// Collection is a Container which can search and iterate over its items.
+type Collection[T any] interface {
+	Container[T]
+	// Contains checks whether the container contains `value`.
+	Contains(value T) bool
+	// Iterator returns an iterator over the items of the container.
+	Iterator() Iterator[T]
+}
+
+// Iterator iterates over the items of a container, starting before the first item.
+// The items are the ones of the container as of the moment the iterator is created,
+// so the container may be used or modified while iterating.
+//
+//	for it := c.Iterator(); it.Next(); {
+//		fmt.Println(it.Value())
+//	}
+type Iterator[T any] interface {
+	// Next moves to the next item, and reports whether there is one.
+	Next() bool
+	// Value returns the current item, it must be called after Next returns true.
+	Value() T
+}
+
+// ReverseIterator iterates over the items of an ordered container backwards,
+// starting after the last item, like Iterator does.
+//
+//	for it := c.ReverseIterator(); it.Prev(); {
+//		fmt.Println(it.Value())
+//	}
+type ReverseIterator[T any] interface {
+	// Prev moves to the previous item, and reports whether there is one.
+	Prev() bool
+	// Value returns the current item, it must be called after Prev returns true.
+	Value() T
+}
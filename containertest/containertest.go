@@ -0,0 +1,251 @@
+// Package containertest implements a conformance suite of Ginkgo specs for the gods.Container
+// implementations, which checks them against a reference model.
+//
+// The suite clears and clones the container by its Reset and Copy.
+//
+// A package runs the suite by describing its container as a Subject in its test suite:
+//
+//	var _ = containertest.Describe(containertest.Subject[int, *array.Array[int]]{
+//		Name:     "Array",
+//		New:      func(safe bool) *array.Array[int] { return array.New[int](gods.WithSafe(safe)) },
+//		Add:      func(a *array.Array[int], item int) { a.PushRight(item) },
+//		Generate: func(r *rand.Rand) int { return r.Intn(16) },
+//		Ordered:  true,
+//	})
+package containertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+)
+
+// Subject describes a container implementation under test.
+type Subject[T comparable, C gods.Container[T]] struct {
+	// Name is the name of the container in the spec descriptions.
+	Name string
+	// New creates and returns an empty container, which is concurrent-safe if `safe` is true.
+	New func(safe bool) C
+	// Add inserts `item` into the container.
+	Add func(c C, item T)
+	// Remove removes the first occurrence of `item` from the container, it is optional.
+	Remove func(c C, item T)
+	// Generate returns a random item, which should collide often to exercise the duplicates.
+	Generate func(r *rand.Rand) T
+	// Unique specifies whether the container keeps a single occurrence of the items, like a set.
+	Unique bool
+	// Ordered specifies whether the container keeps the items in the order they are added
+	// in Slice, String and the JSON encoding, or else in no particular order.
+	Ordered bool
+	// Operations is the number of random operations of the specs, which is 1000 if zero.
+	Operations int
+}
+
+// model is the reference implementation the container is checked against.
+type model[T comparable] struct {
+	items  []T
+	unique bool
+}
+
+// add adds `item` like the container does.
+func (m *model[T]) add(item T) {
+	if m.unique && m.index(item) >= 0 {
+		return
+	}
+	m.items = append(m.items, item)
+}
+
+// remove removes the first occurrence of `item`.
+func (m *model[T]) remove(item T) {
+	if i := m.index(item); i >= 0 {
+		m.items = append(m.items[:i:i], m.items[i+1:]...)
+	}
+}
+
+// index returns the index of the first occurrence of `item`, or -1 if not exists.
+func (m *model[T]) index(item T) int {
+	for i, v := range m.items {
+		if v == item {
+			return i
+		}
+	}
+	return -1
+}
+
+// Describe declares the conformance specs of `s`, it returns true like ginkgo.Describe,
+// so it can be called at the top level of a test file.
+func Describe[T comparable, C gods.Container[T]](s Subject[T, C]) bool {
+	return ginkgo.Describe(s.Name+" conformance", func() {
+		var r *rand.Rand
+		ginkgo.BeforeEach(func() {
+			r = rand.New(rand.NewSource(ginkgo.GinkgoRandomSeed()))
+		})
+
+		ginkgo.It("matches the model after random operations", func() {
+			c := s.New(false)
+			m := &model[T]{unique: s.Unique}
+			s.check(c, m)
+			for i := 0; i < s.operations(); i++ {
+				item := s.Generate(r)
+				switch n := r.Intn(20); {
+				case n == 0:
+					c.Reset()
+					m.items = nil
+				case n < 7 && s.Remove != nil:
+					s.Remove(c, item)
+					m.remove(item)
+				default:
+					s.Add(c, item)
+					m.add(item)
+				}
+				s.check(c, m)
+			}
+		})
+
+		ginkgo.It("keeps clones independent", func() {
+			c := s.New(false)
+			m := &model[T]{unique: s.Unique}
+			for i := 0; i < 32; i++ {
+				item := s.Generate(r)
+				s.Add(c, item)
+				m.add(item)
+			}
+			clone := c.Copy()
+			cloned := &model[T]{items: append([]T(nil), m.items...), unique: s.Unique}
+			s.check(clone, cloned)
+
+			for i := 0; i < 32; i++ {
+				item := s.Generate(r)
+				s.Add(c, item)
+				m.add(item)
+				item = s.Generate(r)
+				s.Add(clone.(C), item)
+				cloned.add(item)
+			}
+			s.check(c, m)
+			s.check(clone, cloned)
+			clone.Reset()
+			s.check(clone, &model[T]{unique: s.Unique})
+			s.check(c, m)
+		})
+
+		ginkgo.It("round-trips JSON", func() {
+			c := s.New(false)
+			if _, ok := any(c).(json.Marshaler); !ok {
+				ginkgo.Skip("the container does not implement json.Marshaler")
+			}
+			m := &model[T]{unique: s.Unique}
+			for i := 0; i < 32; i++ {
+				item := s.Generate(r)
+				s.Add(c, item)
+				m.add(item)
+			}
+			b, err := json.Marshal(c)
+			Expect(err).NotTo(HaveOccurred())
+			if s.Ordered {
+				Expect(b).To(MatchJSON(mustMarshal(m.items)))
+			}
+			decoded := s.New(false)
+			s.Add(decoded, s.Generate(r))
+			Expect(json.Unmarshal(b, decoded)).To(Succeed())
+			s.check(decoded, m)
+			Expect(json.Unmarshal([]byte(`{`), decoded)).NotTo(Succeed())
+		})
+
+		ginkgo.It("is safe for concurrent use", func() {
+			c := s.New(true)
+			const workers, operations = 8, 200
+			var wg sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				r := rand.New(rand.NewSource(r.Int63()))
+				wg.Add(1)
+				go func() {
+					defer ginkgo.GinkgoRecover()
+					defer wg.Done()
+					for i := 0; i < operations; i++ {
+						item := s.Generate(r)
+						switch r.Intn(8) {
+						case 0:
+							if s.Remove != nil {
+								s.Remove(c, item)
+							}
+						case 1:
+							_ = c.String()
+						case 2:
+							_ = c.Slice()
+						case 3:
+							_ = c.Copy().Size()
+						case 4:
+							if c, ok := any(c).(gods.Collection[T]); ok {
+								c.Contains(item)
+								for it := c.Iterator(); it.Next(); {
+									_ = it.Value()
+								}
+							}
+						default:
+							s.Add(c, item)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			Expect(c.Size()).To(BeNumerically("<=", workers*operations))
+			Expect(c.Slice()).To(HaveLen(c.Size()))
+		})
+	})
+}
+
+// check checks the container `c` against the model `m`.
+func (s Subject[T, C]) check(c gods.Container[T], m *model[T]) {
+	ExpectWithOffset(1, c.Size()).To(Equal(len(m.items)))
+	ExpectWithOffset(1, c.IsEmpty()).To(Equal(len(m.items) == 0))
+
+	strs := make([]string, len(m.items))
+	for i, v := range m.items {
+		strs[i] = fmt.Sprintf("%v", v)
+	}
+	if s.Ordered {
+		ExpectWithOffset(1, append([]T{}, c.Slice()...)).To(Equal(append([]T{}, m.items...)))
+		ExpectWithOffset(1, c.String()).To(Equal(fmt.Sprintf("%v", strs)))
+	} else {
+		ExpectWithOffset(1, c.Slice()).To(ConsistOf(m.items))
+		ExpectWithOffset(1, strings.Fields(strings.Trim(c.String(), "[]"))).To(ConsistOf(strs))
+	}
+
+	collection, ok := any(c).(gods.Collection[T])
+	if !ok {
+		return
+	}
+	var iterated []T
+	for it := collection.Iterator(); it.Next(); {
+		iterated = append(iterated, it.Value())
+	}
+	ExpectWithOffset(1, iterated).To(ConsistOf(m.items))
+	for _, v := range m.items {
+		ExpectWithOffset(1, collection.Contains(v)).To(BeTrue())
+	}
+}
+
+// operations returns the number of random operations of the specs.
+func (s Subject[T, C]) operations() int {
+	if s.Operations > 0 {
+		return s.Operations
+	}
+	return 1000
+}
+
+// mustMarshal returns the JSON encoding of `v`, it panics on error.
+func mustMarshal(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,404 @@
+// Package cuckoo implements a cuckoo filter, which tests whether a key is in a set in compact memory
+// like a Bloom filter, and also supports deleting keys.
+//
+// Every key is stored as a small fingerprint in one of its two candidate buckets, and inserting
+// into full buckets relocates the fingerprints already there to their other candidates.
+package cuckoo
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/bloom"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+const (
+	// DefaultFingerprintBits is the number of bits of a fingerprint of a filter created by New,
+	// which gives a false positive rate of about 0.01% with the default bucket size.
+	DefaultFingerprintBits = 16
+	// DefaultBucketSize is the number of fingerprints of a bucket of a filter created by New.
+	DefaultBucketSize = 4
+	// maxKicks is the number of relocations an insertion tries before the filter is considered full.
+	maxKicks = 500
+)
+
+// ErrInvalidData is returned when decoding invalid binary data into a filter.
+var ErrInvalidData = errors.New("invalid binary data of cuckoo filter")
+
+// kind identifies a cuckoo filter in the binary encoding.
+const kind byte = 'K'
+
+// headerSize is the size of the binary encoding before the buckets: the kind, the fingerprint bits,
+// the bucket size, the number of buckets and of items in 8 bytes each, and the victim in 12 bytes.
+const headerSize = 3 + 8 + 8 + 12
+
+// victim is a fingerprint evicted by an insertion which found no free entry.
+type victim struct {
+	index       uint64
+	fingerprint uint32
+}
+
+// Filter is a cuckoo filter of keys of type T.
+//
+// The same key may be inserted several times, up to twice the bucket size, and is deleted as many times.
+type Filter[T any] struct {
+	mu     rwmutex.RWMutex
+	hasher bloom.Hasher[T]
+	// Fingerprints of the entries packed in 64-bit words, where 0 means an empty entry.
+	table []uint64
+	// Number of buckets, which is a power of 2.
+	buckets         uint64
+	bucketSize      uint64
+	fingerprintBits uint64
+	count           uint64
+	// Fingerprint which did not fit in the table, or nil if there is none.
+	// The filter is full while it is not nil.
+	victim *victim
+	// State of the random generator choosing the entries to relocate.
+	rand uint64
+}
+
+// New creates and returns an empty filter holding up to about `capacity` keys,
+// with the default fingerprint bits and bucket size. The keys are hashed by `hasher`,
+// or by bloom.Default[T] if it is nil.
+// The parameter `options` is used to configure the filter, see gods.Option.
+// It is not concurrent-safe in default.
+func New[T any](capacity int, hasher bloom.Hasher[T], options ...gods.Option) *Filter[T] {
+	return NewWithSize(capacity, DefaultFingerprintBits, DefaultBucketSize, hasher, options...)
+}
+
+// NewWithSize creates and returns an empty filter holding up to about `capacity` keys, whose fingerprints
+// have `fingerprintBits` bits, from 1 to 32, and whose buckets have `bucketSize` entries, from 1 to 255.
+// They are the defaults if not positive, and clamped to the maximum if greater.
+// The false positive rate is about 2*bucketSize/2^fingerprintBits.
+// The keys are hashed by `hasher`, or by bloom.Default[T] if it is nil.
+// The parameter `options` is used to configure the filter, see gods.Option.
+// It is not concurrent-safe in default.
+func NewWithSize[T any](capacity int, fingerprintBits int, bucketSize int, hasher bloom.Hasher[T],
+	options ...gods.Option) *Filter[T] {
+	switch {
+	case fingerprintBits <= 0:
+		fingerprintBits = DefaultFingerprintBits
+	case fingerprintBits > 32:
+		fingerprintBits = 32
+	}
+	switch {
+	case bucketSize <= 0:
+		bucketSize = DefaultBucketSize
+	case bucketSize > 255:
+		bucketSize = 255
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+	buckets := uint64(1)
+	for float64(capacity) > float64(buckets*uint64(bucketSize))*maxLoadFactor(bucketSize) {
+		buckets <<= 1
+	}
+	if hasher == nil {
+		hasher = bloom.Default[T]()
+	}
+	f := &Filter[T]{
+		mu:              rwmutex.CreateWithoutCOW("cuckoo.Filter", options),
+		hasher:          hasher,
+		buckets:         buckets,
+		bucketSize:      uint64(bucketSize),
+		fingerprintBits: uint64(fingerprintBits),
+	}
+	f.table = make([]uint64, f.words())
+	return f
+}
+
+// Insert inserts `key` into the filter, and reports whether it is inserted.
+// It returns false if the filter is full, which happens at a load factor of about 95% with
+// the default bucket size, and at about 50% with buckets of a single entry.
+func (f *Filter[T]) Insert(key T) bool {
+	f.mu.Count("Insert")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.victim != nil {
+		return false
+	}
+	index, fingerprint := f.locate(key)
+	f.place(index, fingerprint)
+	f.count++
+	return true
+}
+
+// Lookup checks whether `key` may be in the filter. If it returns false, `key` is not in the filter,
+// and if it returns true, `key` is in the filter, or not with a probability of the false positive rate.
+func (f *Filter[T]) Lookup(key T) bool {
+	f.mu.Count("Lookup")
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	index, fingerprint := f.locate(key)
+	alternate := f.alternate(index, fingerprint)
+	if v := f.victim; v != nil && v.fingerprint == fingerprint && (v.index == index || v.index == alternate) {
+		return true
+	}
+	return f.find(index, fingerprint) >= 0 || f.find(alternate, fingerprint) >= 0
+}
+
+// Delete deletes one occurrence of `key` from the filter, and reports whether it is deleted.
+// It returns false if `key` is not in the filter.
+//
+// Note that deleting a key which is not inserted, but looked up as a false positive,
+// deletes another key sharing its fingerprint, so only the keys known to be inserted should be deleted.
+func (f *Filter[T]) Delete(key T) bool {
+	f.mu.Count("Delete")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index, fingerprint := f.locate(key)
+	alternate := f.alternate(index, fingerprint)
+	if v := f.victim; v != nil && v.fingerprint == fingerprint && (v.index == index || v.index == alternate) {
+		f.victim = nil
+		f.count--
+		return true
+	}
+	for _, i := range []uint64{index, alternate} {
+		if entry := f.find(i, fingerprint); entry >= 0 {
+			f.set(uint64(entry), 0)
+			f.count--
+			f.reinsertVictim()
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of keys in the filter.
+func (f *Filter[T]) Count() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.count
+}
+
+// Capacity returns the number of entries of the filter, which is the number of buckets times the bucket size.
+func (f *Filter[T]) Capacity() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.buckets * f.bucketSize
+}
+
+// LoadFactor returns the ratio of the entries in use, see Insert for the one the filter gets full at.
+func (f *Filter[T]) LoadFactor() float64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return float64(f.count) / float64(f.buckets*f.bucketSize)
+}
+
+// FingerprintBits returns the number of bits of the fingerprints of the filter.
+func (f *Filter[T]) FingerprintBits() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return int(f.fingerprintBits)
+}
+
+// BucketSize returns the number of entries of a bucket of the filter.
+func (f *Filter[T]) BucketSize() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return int(f.bucketSize)
+}
+
+// Clear deletes all keys of the filter.
+func (f *Filter[T]) Clear() {
+	f.mu.Count("Clear")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.table {
+		f.table[i] = 0
+	}
+	f.count, f.victim = 0, nil
+}
+
+// Stats returns a snapshot of the metrics of the filter created with gods.WithMetrics(true).
+func (f *Filter[T]) Stats() gods.Stats {
+	return f.mu.Stats()
+}
+
+// MarshalBinary implements the interface encoding.BinaryMarshaler,
+// which encodes the sizes and the fingerprints of the filter, but not its hasher.
+func (f *Filter[T]) MarshalBinary() ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	b := make([]byte, 0, headerSize+len(f.table)*8)
+	b = append(b, kind, byte(f.fingerprintBits), byte(f.bucketSize))
+	b = binary.BigEndian.AppendUint64(b, f.buckets)
+	b = binary.BigEndian.AppendUint64(b, f.count)
+	var v victim
+	if f.victim != nil {
+		v = *f.victim
+	}
+	b = binary.BigEndian.AppendUint64(b, v.index)
+	b = binary.BigEndian.AppendUint32(b, v.fingerprint)
+	for _, word := range f.table {
+		b = binary.BigEndian.AppendUint64(b, word)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the interface encoding.BinaryUnmarshaler,
+// which replaces the sizes and the fingerprints of the filter with the decoded ones.
+// The filter keeps its hasher, which must be the one of the encoded filter, or bloom.Default[T] if it has none.
+func (f *Filter[T]) UnmarshalBinary(b []byte) error {
+	if len(b) < headerSize || b[0] != kind {
+		return ErrInvalidData
+	}
+	decoded := Filter[T]{
+		fingerprintBits: uint64(b[1]),
+		bucketSize:      uint64(b[2]),
+		buckets:         binary.BigEndian.Uint64(b[3:]),
+		count:           binary.BigEndian.Uint64(b[11:]),
+	}
+	v := victim{index: binary.BigEndian.Uint64(b[19:]), fingerprint: binary.BigEndian.Uint32(b[27:])}
+	b = b[headerSize:]
+	if decoded.fingerprintBits < 1 || decoded.fingerprintBits > 32 || decoded.bucketSize < 1 ||
+		decoded.buckets == 0 || decoded.buckets&(decoded.buckets-1) != 0 || decoded.buckets > uint64(len(b))*8 ||
+		len(b)%8 != 0 || uint64(len(b)/8) != decoded.words() ||
+		decoded.count > decoded.buckets*decoded.bucketSize+1 || v.fingerprint != 0 && v.index >= decoded.buckets ||
+		uint64(v.fingerprint) >= 1<<decoded.fingerprintBits {
+		return ErrInvalidData
+	}
+	table := make([]uint64, len(b)/8)
+	for i := range table {
+		table[i] = binary.BigEndian.Uint64(b[i*8:])
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.table, f.buckets, f.bucketSize, f.fingerprintBits, f.count, f.victim =
+		table, decoded.buckets, decoded.bucketSize, decoded.fingerprintBits, decoded.count, nil
+	if v.fingerprint != 0 {
+		f.victim = &v
+	}
+	if f.hasher == nil {
+		f.hasher = bloom.Default[T]()
+	}
+	return nil
+}
+
+// maxLoadFactor returns the load factor a filter whose buckets have `bucketSize` entries is sized for,
+// below the one it usually gets full at.
+func maxLoadFactor(bucketSize int) float64 {
+	switch bucketSize {
+	case 1:
+		return 0.5
+	case 2:
+		return 0.84
+	case 3:
+		return 0.9
+	default:
+		return 0.95
+	}
+}
+
+// words returns the number of 64-bit words holding the fingerprints of all the entries.
+func (f *Filter[T]) words() uint64 {
+	bits := f.buckets * f.bucketSize * f.fingerprintBits
+	return bits/64 + (bits%64+63)/64
+}
+
+// locate returns the first candidate bucket and the fingerprint of `key`, which is not zero.
+func (f *Filter[T]) locate(key T) (index uint64, fingerprint uint32) {
+	h := f.hasher(key)
+	fingerprint = uint32((h >> 32) & (1<<f.fingerprintBits - 1))
+	if fingerprint == 0 {
+		fingerprint = 1
+	}
+	return h & (f.buckets - 1), fingerprint
+}
+
+// alternate returns the other candidate bucket of the fingerprint `fingerprint` in the bucket `index`.
+// The alternate of the alternate is the bucket itself.
+func (f *Filter[T]) alternate(index uint64, fingerprint uint32) uint64 {
+	return (index ^ bloom.Uint64(uint64(fingerprint))) & (f.buckets - 1)
+}
+
+// insertInto inserts `fingerprint` into a free entry of the bucket `index`, and reports whether there is one.
+func (f *Filter[T]) insertInto(index uint64, fingerprint uint32) bool {
+	if entry := f.find(index, 0); entry >= 0 {
+		f.set(uint64(entry), fingerprint)
+		return true
+	}
+	return false
+}
+
+// find returns the first entry of the bucket `index` holding `fingerprint`, or -1 if there is none.
+func (f *Filter[T]) find(index uint64, fingerprint uint32) int64 {
+	for entry := index * f.bucketSize; entry < (index+1)*f.bucketSize; entry++ {
+		if f.get(entry) == fingerprint {
+			return int64(entry)
+		}
+	}
+	return -1
+}
+
+// place places `fingerprint` into the bucket `index` or its alternate, relocating the fingerprints
+// already there to their alternate buckets to make room, and reports whether it is placed.
+// If there is still no room after maxKicks relocations, the last evicted fingerprint is kept
+// as the victim, so no key is lost, and the filter refuses the next insertions.
+func (f *Filter[T]) place(index uint64, fingerprint uint32) bool {
+	if f.insertInto(index, fingerprint) || f.insertInto(f.alternate(index, fingerprint), fingerprint) {
+		return true
+	}
+	if f.next()&1 == 1 {
+		index = f.alternate(index, fingerprint)
+	}
+	for kick := 0; kick < maxKicks; kick++ {
+		entry := index*f.bucketSize + f.next()%f.bucketSize
+		evicted := f.get(entry)
+		f.set(entry, fingerprint)
+		fingerprint = evicted
+		index = f.alternate(index, fingerprint)
+		if f.insertInto(index, fingerprint) {
+			return true
+		}
+	}
+	f.victim = &victim{index: index, fingerprint: fingerprint}
+	return false
+}
+
+// reinsertVictim places the victim into the table again, if there is one,
+// after an entry is freed.
+func (f *Filter[T]) reinsertVictim() {
+	if v := f.victim; v != nil {
+		f.victim = nil
+		f.place(v.index, v.fingerprint)
+	}
+}
+
+// get returns the fingerprint of the entry `entry`.
+func (f *Filter[T]) get(entry uint64) uint32 {
+	pos := entry * f.fingerprintBits
+	word, offset := pos/64, pos%64
+	v := f.table[word] >> offset
+	if offset+f.fingerprintBits > 64 {
+		v |= f.table[word+1] << (64 - offset)
+	}
+	return uint32(v & (1<<f.fingerprintBits - 1))
+}
+
+// set sets the fingerprint of the entry `entry`.
+func (f *Filter[T]) set(entry uint64, fingerprint uint32) {
+	pos := entry * f.fingerprintBits
+	word, offset := pos/64, pos%64
+	mask := uint64(1)<<f.fingerprintBits - 1
+	f.table[word] = f.table[word]&^(mask<<offset) | uint64(fingerprint)<<offset
+	if offset+f.fingerprintBits > 64 {
+		shift := 64 - offset
+		f.table[word+1] = f.table[word+1]&^(mask>>shift) | uint64(fingerprint)>>shift
+	}
+}
+
+// next returns the next number of the xorshift random generator.
+func (f *Filter[T]) next() uint64 {
+	if f.rand == 0 {
+		f.rand = 0x9e3779b97f4a7c15
+	}
+	f.rand ^= f.rand << 13
+	f.rand ^= f.rand >> 7
+	f.rand ^= f.rand << 17
+	return f.rand
+}
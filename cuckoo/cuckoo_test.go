@@ -0,0 +1,190 @@
+package cuckoo_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/bloom"
+	"github.com/lazybabe/gods/cuckoo"
+)
+
+func TestCuckoo(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cuckoo Suite")
+}
+
+// falsePositives returns the ratio of the keys in [from, to) looked up as false positives.
+func falsePositives(f *cuckoo.Filter[int], from, to int) float64 {
+	var positives int
+	for i := from; i < to; i++ {
+		if f.Lookup(i) {
+			positives++
+		}
+	}
+	return float64(positives) / float64(to-from)
+}
+
+var _ = Describe("Filter", func() {
+	It("Insert, Lookup and Delete", func() {
+		f := cuckoo.New[int](10000, nil)
+		Expect(f.FingerprintBits()).To(Equal(cuckoo.DefaultFingerprintBits))
+		Expect(f.BucketSize()).To(Equal(cuckoo.DefaultBucketSize))
+		Expect(f.Capacity()).To(Equal(uint64(16384)))
+		Expect(f.Lookup(1)).To(BeFalse())
+		Expect(f.Delete(1)).To(BeFalse())
+
+		for i := 0; i < 10000; i++ {
+			Expect(f.Insert(i)).To(BeTrue())
+		}
+		Expect(f.Count()).To(Equal(uint64(10000)))
+		Expect(f.LoadFactor()).To(BeNumerically("~", 10000.0/16384, 1e-9))
+		for i := 0; i < 10000; i++ {
+			Expect(f.Lookup(i)).To(BeTrue())
+		}
+		Expect(falsePositives(f, 10000, 110000)).To(BeNumerically("<", 0.001))
+
+		for i := 0; i < 5000; i++ {
+			Expect(f.Delete(i)).To(BeTrue())
+		}
+		Expect(f.Count()).To(Equal(uint64(5000)))
+		for i := 5000; i < 10000; i++ {
+			Expect(f.Lookup(i)).To(BeTrue())
+		}
+		Expect(falsePositives(f, 0, 5000)).To(BeNumerically("<", 0.001))
+
+		f.Clear()
+		Expect(f.Count()).To(BeZero())
+		Expect(f.Lookup(9999)).To(BeFalse())
+	})
+
+	It("Duplicates", func() {
+		f := cuckoo.New[string](100, nil)
+		Expect(f.Insert("a")).To(BeTrue())
+		Expect(f.Insert("a")).To(BeTrue())
+		Expect(f.Count()).To(Equal(uint64(2)))
+		Expect(f.Delete("a")).To(BeTrue())
+		Expect(f.Lookup("a")).To(BeTrue())
+		Expect(f.Delete("a")).To(BeTrue())
+		Expect(f.Lookup("a")).To(BeFalse())
+		Expect(f.Delete("a")).To(BeFalse())
+	})
+
+	It("Full", func() {
+		f := cuckoo.NewWithSize[int](1000, 12, 2, nil)
+		Expect(f.FingerprintBits()).To(Equal(12))
+		Expect(f.BucketSize()).To(Equal(2))
+		var inserted []int
+		for i := 0; f.Insert(i); i++ {
+			inserted = append(inserted, i)
+		}
+		Expect(f.LoadFactor()).To(BeNumerically(">", 0.7))
+		Expect(f.Count()).To(Equal(uint64(len(inserted))))
+		for _, i := range inserted {
+			Expect(f.Lookup(i)).To(BeTrue())
+		}
+		Expect(f.Insert(-1)).To(BeFalse())
+
+		// Deleting makes room for the fingerprint set aside, and then for more keys.
+		for _, i := range inserted[:10] {
+			Expect(f.Delete(i)).To(BeTrue())
+		}
+		for _, i := range inserted[10:] {
+			Expect(f.Lookup(i)).To(BeTrue())
+		}
+		Expect(f.Insert(-1)).To(BeTrue())
+		Expect(f.Lookup(-1)).To(BeTrue())
+	})
+
+	DescribeTable("Sizes",
+		func(fingerprintBits, bucketSize int, wantBits, wantSize int) {
+			f := cuckoo.NewWithSize[int](100, fingerprintBits, bucketSize, nil)
+			Expect(f.FingerprintBits()).To(Equal(wantBits))
+			Expect(f.BucketSize()).To(Equal(wantSize))
+			for i := 0; i < 100; i++ {
+				Expect(f.Insert(i)).To(BeTrue())
+			}
+			for i := 0; i < 100; i++ {
+				Expect(f.Lookup(i)).To(BeTrue())
+			}
+			for i := 0; i < 100; i++ {
+				Expect(f.Delete(i)).To(BeTrue())
+			}
+			Expect(f.Count()).To(BeZero())
+		},
+		Entry("defaults", 0, 0, cuckoo.DefaultFingerprintBits, cuckoo.DefaultBucketSize),
+		Entry("6 bits", 6, 4, 6, 4),
+		Entry("7 bits", 7, 3, 7, 3),
+		Entry("32 bits", 33, 1, 32, 1),
+		Entry("large buckets", 20, 300, 20, 255),
+	)
+
+	It("Binary", func() {
+		f := cuckoo.NewWithSize[int](1000, 13, 4, nil)
+		for i := 0; i < 1000; i++ {
+			f.Insert(i)
+		}
+		b, err := f.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		decoded := new(cuckoo.Filter[int])
+		Expect(decoded.UnmarshalBinary(b)).To(Succeed())
+		Expect(decoded.Count()).To(Equal(f.Count()))
+		Expect(decoded.Capacity()).To(Equal(f.Capacity()))
+		Expect(decoded.FingerprintBits()).To(Equal(13))
+		for i := 0; i < 1000; i++ {
+			Expect(decoded.Lookup(i)).To(BeTrue())
+		}
+		Expect(decoded.Delete(0)).To(BeTrue())
+		Expect(decoded.Insert(0)).To(BeTrue())
+
+		Expect(decoded.UnmarshalBinary(nil)).To(MatchError(cuckoo.ErrInvalidData))
+		Expect(decoded.UnmarshalBinary(b[:len(b)-8])).To(MatchError(cuckoo.ErrInvalidData))
+		bad := append([]byte{}, b...)
+		bad[1] = 33
+		Expect(decoded.UnmarshalBinary(bad)).To(MatchError(cuckoo.ErrInvalidData))
+		Expect(decoded.Count()).To(Equal(f.Count()))
+
+		full := cuckoo.NewWithSize[int](8, 4, 1, nil)
+		for i := 0; full.Insert(i); i++ {
+		}
+		b, err = full.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded.UnmarshalBinary(b)).To(Succeed())
+		Expect(decoded.Insert(-1)).To(BeFalse())
+		Expect(decoded.Count()).To(Equal(full.Count()))
+	})
+
+	It("Hasher", func() {
+		f := cuckoo.New(100, bloom.Bytes)
+		Expect(f.Insert([]byte("a"))).To(BeTrue())
+		Expect(f.Lookup([]byte("a"))).To(BeTrue())
+		Expect(f.Lookup([]byte("b"))).To(BeFalse())
+	})
+
+	It("Safe", func() {
+		f := cuckoo.New[int](4000, nil, gods.WithSafe(true), gods.WithMetrics(true))
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					Expect(f.Insert(w*1000 + i)).To(BeTrue())
+					Expect(f.Lookup(w*1000 + i)).To(BeTrue())
+				}
+				for i := 0; i < 500; i++ {
+					Expect(f.Delete(w*1000 + i)).To(BeTrue())
+				}
+			}(w)
+		}
+		wg.Wait()
+		Expect(f.Count()).To(Equal(uint64(2000)))
+		Expect(f.Stats().Operations).To(HaveKeyWithValue("Insert", uint64(4000)))
+		Expect(func() { cuckoo.New[int](100, nil, gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: cuckoo.Filter does not support LockCopyOnWrite"))
+	})
+})
@@ -0,0 +1,42 @@
+package cuckoo_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/lazybabe/gods/cuckoo"
+)
+
+// identity is the hasher of the fuzzed filters, so a key is the fingerprint in its high 32 bits
+// and the bucket in its low ones.
+func identity(key uint64) uint64 {
+	return key
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded := cuckoo.NewWithSize[uint64](1, 0, 0, identity)
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			return
+		}
+		b, err := decoded.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary of %x: %v", data, err)
+		}
+		if !bytes.Equal(b[:19], data[:19]) || !bytes.Equal(b[31:], data[31:]) {
+			t.Fatalf("MarshalBinary of %x = %x", data, b)
+		}
+		// The victim must be looked up by the key it is the fingerprint of.
+		if fingerprint := binary.BigEndian.Uint32(data[27:]); fingerprint != 0 {
+			key := uint64(fingerprint)<<32 | binary.BigEndian.Uint64(data[19:])
+			if !decoded.Lookup(key) {
+				t.Fatalf("Lookup of the victim %x of %x = false", key, data)
+			}
+		}
+		again := cuckoo.NewWithSize[uint64](1, 0, 0, identity)
+		if err := again.UnmarshalBinary(b); err != nil {
+			t.Fatalf("UnmarshalBinary of the encoding %x of %x: %v", b, data, err)
+		}
+	})
+}
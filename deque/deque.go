@@ -0,0 +1,308 @@
+package deque
+
+import (
+	"fmt"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/iterator"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+var _ gods.Container[int] = (*Deque[int])(nil)
+
+// minCapacity is the smallest capacity of the ring buffer, it must be a power of 2.
+const minCapacity = 16
+
+// Deque is a double-ended queue backed by a growable ring buffer.
+// Pushing and popping at both ends are amortized O(1).
+type Deque[T any] struct {
+	mu    rwmutex.RWMutex
+	buf   []T
+	head  int
+	count int
+}
+
+// New creates and returns an empty deque.
+// The parameter `options` is used to configure the deque, see gods.Option.
+// It is not concurrent-safe in default.
+//
+// The deque supports gods.WithCapacity.
+func New[T any](options ...gods.Option) *Deque[T] {
+	return NewFrom[T](nil, options...)
+}
+
+// NewFrom creates and returns a deque with the items of `items` from front to back.
+// The given slice is copied, so it is never aliased by the deque.
+// The parameter `options` is used to configure the deque, see gods.Option.
+// It is not concurrent-safe in default.
+func NewFrom[T any](items []T, options ...gods.Option) *Deque[T] {
+	o := gods.NewOptions(options...)
+	o.Check("deque.Deque", gods.FeatureCapacity)
+	d := &Deque[T]{mu: rwmutex.CreateWith(o)}
+	if n := max(len(items), o.Capacity); n > 0 {
+		d.buf = make([]T, capacityFor(n))
+		d.count = copy(d.buf, items)
+	}
+	return d
+}
+
+// PushFront places `value` at the front of the deque.
+func (d *Deque[T]) PushFront(value T) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.growIfFull()
+	d.head = d.prev(d.head)
+	d.buf[d.head] = value
+	d.count++
+}
+
+// PushBack places `value` at the back of the deque.
+func (d *Deque[T]) PushBack(value T) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.growIfFull()
+	d.buf[d.physical(d.count)] = value
+	d.count++
+}
+
+// PopFront removes and returns the item at the front of the deque.
+// Note that if the deque is empty, the `found` is false.
+func (d *Deque[T]) PopFront() (value T, found bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.count == 0 {
+		return value, false
+	}
+	var zero T
+	value = d.buf[d.head]
+	d.buf[d.head] = zero
+	d.head = d.next(d.head)
+	d.count--
+	d.shrinkIfSparse()
+	return value, true
+}
+
+// PopBack removes and returns the item at the back of the deque.
+// Note that if the deque is empty, the `found` is false.
+func (d *Deque[T]) PopBack() (value T, found bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.count == 0 {
+		return value, false
+	}
+	var zero T
+	tail := d.physical(d.count - 1)
+	value = d.buf[tail]
+	d.buf[tail] = zero
+	d.count--
+	d.shrinkIfSparse()
+	return value, true
+}
+
+// Front returns the item at the front of the deque without removing it.
+// Note that if the deque is empty, the `found` is false.
+func (d *Deque[T]) Front() (value T, found bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.count == 0 {
+		return value, false
+	}
+	return d.buf[d.head], true
+}
+
+// Back returns the item at the back of the deque without removing it.
+// Note that if the deque is empty, the `found` is false.
+func (d *Deque[T]) Back() (value T, found bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.count == 0 {
+		return value, false
+	}
+	return d.buf[d.physical(d.count-1)], true
+}
+
+// At returns the item by the specified index, counting from the front.
+// If the given `index` is out of range of the deque, the `found` is false.
+func (d *Deque[T]) At(index int) (value T, found bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if index < 0 || index >= d.count {
+		return
+	}
+	return d.buf[d.physical(index)], true
+}
+
+// Rotate rotates the deque `k` steps to the back.
+// If `k` is negative, it rotates to the front instead.
+// Example: Rotate(1) on [1, 2, 3] -> [3, 1, 2], Rotate(-1) on [1, 2, 3] -> [2, 3, 1].
+func (d *Deque[T]) Rotate(k int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.count <= 1 {
+		return
+	}
+	k %= d.count
+	if k == 0 {
+		return
+	}
+	// Rotate in the direction which moves fewer items.
+	if k > d.count/2 {
+		k -= d.count
+	} else if k < -d.count/2 {
+		k += d.count
+	}
+	if d.count == len(d.buf) {
+		// The buffer is full, moving the head is enough.
+		d.head = d.physical(d.count - k)
+		return
+	}
+	var zero T
+	for ; k > 0; k-- {
+		tail := d.physical(d.count - 1)
+		d.head = d.prev(d.head)
+		d.buf[d.head] = d.buf[tail]
+		d.buf[tail] = zero
+	}
+	for ; k < 0; k++ {
+		d.buf[d.physical(d.count)] = d.buf[d.head]
+		d.buf[d.head] = zero
+		d.head = d.next(d.head)
+	}
+}
+
+// Size returns the number of items in the deque.
+func (d *Deque[T]) Size() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.count
+}
+
+// IsEmpty returns true if the deque is empty, otherwise returns false.
+func (d *Deque[T]) IsEmpty() bool {
+	return d.Size() == 0
+}
+
+// Clear deletes all items of the deque, keeping its buffer for the next items.
+func (d *Deque[T]) Clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// Zero the slots, so the buffer does not keep the deleted items alive.
+	clear(d.buf)
+	d.head = 0
+	d.count = 0
+}
+
+// Slice returns a copy of the items of the deque from front to back.
+func (d *Deque[T]) Slice() []T {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.doSliceWithoutLock()
+}
+
+// Clone returns a new deque, which is a copy of current deque.
+func (d *Deque[T]) Clone() *Deque[T] {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return NewFrom(d.doSliceWithoutLock(), d.mu.Options()...)
+}
+
+// Reset deletes all items of the deque like Clear, it implements gods.Container.
+func (d *Deque[T]) Reset() {
+	d.Clear()
+}
+
+// Copy returns a new deque like Clone, it implements gods.Container.
+func (d *Deque[T]) Copy() gods.Container[T] {
+	return d.Clone()
+}
+
+// Iterator returns an iterator over the items of the deque from front to back,
+// which are the ones as of the moment Iterator is called.
+func (d *Deque[T]) Iterator() gods.Iterator[T] {
+	return iterator.New(d.Slice())
+}
+
+// ReverseIterator returns an iterator over the items of the deque from back to front,
+// which are the ones as of the moment ReverseIterator is called.
+func (d *Deque[T]) ReverseIterator() gods.ReverseIterator[T] {
+	return iterator.NewReverse(d.Slice())
+}
+
+// Each calls `f` on every item in the deque from front to back.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (d *Deque[T]) Each(f func(k int, v T) bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for i := 0; i < d.count; i++ {
+		if !f(i, d.buf[d.physical(i)]) {
+			break
+		}
+	}
+}
+
+// String returns current deque as a string, which implements like json.Marshal does.
+func (d *Deque[T]) String() string {
+	out := make([]string, 0, d.Size())
+	d.Each(func(_ int, v T) bool { out = append(out, fmt.Sprintf(`%v`, v)); return true })
+	return fmt.Sprintf("%v", out)
+}
+
+// doSliceWithoutLock copies the items from front to back without lock.
+func (d *Deque[T]) doSliceWithoutLock() []T {
+	s := make([]T, d.count)
+	n := copy(s, d.buf[d.head:min(d.head+d.count, len(d.buf))])
+	copy(s[n:], d.buf[:d.count-n])
+	return s
+}
+
+// physical maps the logical `index` counted from the front to the buffer index.
+func (d *Deque[T]) physical(index int) int {
+	return (d.head + index) & (len(d.buf) - 1)
+}
+
+// next returns the buffer index after `i`.
+func (d *Deque[T]) next(i int) int {
+	return (i + 1) & (len(d.buf) - 1)
+}
+
+// prev returns the buffer index before `i`.
+func (d *Deque[T]) prev(i int) int {
+	return (i - 1) & (len(d.buf) - 1)
+}
+
+// growIfFull doubles the buffer if there is no room for another item.
+func (d *Deque[T]) growIfFull() {
+	if d.count < len(d.buf) {
+		return
+	}
+	if len(d.buf) == 0 {
+		d.buf = make([]T, minCapacity)
+		return
+	}
+	d.resize(len(d.buf) << 1)
+}
+
+// shrinkIfSparse halves the buffer if it is only a quarter full.
+func (d *Deque[T]) shrinkIfSparse() {
+	if len(d.buf) > minCapacity && d.count<<2 == len(d.buf) {
+		d.resize(len(d.buf) >> 1)
+	}
+}
+
+// resize moves the items into a new buffer of `capacity`, starting at index 0.
+func (d *Deque[T]) resize(capacity int) {
+	buf := make([]T, capacity)
+	n := copy(buf, d.buf[d.head:min(d.head+d.count, len(d.buf))])
+	copy(buf[n:], d.buf[:d.count-n])
+	d.buf = buf
+	d.head = 0
+}
+
+// capacityFor returns the smallest power of 2 capacity which holds `n` items.
+func capacityFor(n int) int {
+	capacity := minCapacity
+	for capacity < n {
+		capacity <<= 1
+	}
+	return capacity
+}
@@ -0,0 +1,195 @@
+package deque_test
+
+import (
+	"math/rand"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/containertest"
+	"github.com/lazybabe/gods/deque"
+)
+
+func TestDeque(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Deque Suite")
+}
+
+// value drops the `found` result, it fails the spec if the item is not found.
+func value[T any](v T, found bool) T {
+	ExpectWithOffset(1, found).To(BeTrue())
+	return v
+}
+
+var _ = Describe("Deque", func() {
+	It("New", func() {
+		d1 := deque.New[int]()
+		Expect(d1.Size()).To(BeZero())
+		Expect(d1.IsEmpty()).To(BeTrue())
+		Expect(d1.Slice()).To(BeEmpty())
+
+		slice := []int{1, 2, 3}
+		d2 := deque.NewFrom(slice, gods.WithSafe(true))
+		Expect(d2.Size()).To(Equal(3))
+		Expect(d2.Slice()).To(Equal([]int{1, 2, 3}))
+		slice[0] = 100
+		Expect(d2.Slice()).To(Equal([]int{1, 2, 3}))
+
+		d3 := deque.New[int](gods.WithCapacity(100))
+		Expect(d3.IsEmpty()).To(BeTrue())
+		d3.PushBack(1)
+		Expect(d3.Slice()).To(Equal([]int{1}))
+		Expect(func() { deque.New[int](gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: deque.Deque does not support LockCopyOnWrite"))
+	})
+
+	It("PushFront|PushBack", func() {
+		d := deque.New[int]()
+		d.PushBack(2)
+		d.PushFront(1)
+		d.PushBack(3)
+		Expect(d.Slice()).To(Equal([]int{1, 2, 3}))
+		for i := 4; i <= 100; i++ {
+			d.PushBack(i)
+			d.PushFront(-i)
+		}
+		Expect(d.Size()).To(Equal(197))
+		Expect(value(d.Front())).To(Equal(-100))
+		Expect(value(d.Back())).To(Equal(100))
+	})
+
+	It("PopFront|PopBack", func() {
+		d := deque.NewFrom([]int{1, 2, 3})
+		Expect(value(d.PopFront())).To(Equal(1))
+		Expect(value(d.PopBack())).To(Equal(3))
+		Expect(value(d.PopBack())).To(Equal(2))
+		_, found := d.PopFront()
+		Expect(found).To(BeFalse())
+		_, found = d.PopBack()
+		Expect(found).To(BeFalse())
+
+		for i := 0; i < 1000; i++ {
+			d.PushBack(i)
+		}
+		for i := 0; i < 1000; i++ {
+			v, found := d.PopFront()
+			Expect(found).To(BeTrue())
+			Expect(v).To(Equal(i))
+		}
+		Expect(d.IsEmpty()).To(BeTrue())
+	})
+
+	It("Front|Back", func() {
+		d := deque.New[string]()
+		_, found := d.Front()
+		Expect(found).To(BeFalse())
+		_, found = d.Back()
+		Expect(found).To(BeFalse())
+		d.PushBack("a")
+		d.PushBack("b")
+		Expect(value(d.Front())).To(Equal("a"))
+		Expect(value(d.Back())).To(Equal("b"))
+		Expect(d.Size()).To(Equal(2))
+	})
+
+	DescribeTable("At",
+		func(output int, found bool, index int) {
+			d := deque.NewFrom([]int{2, 3})
+			d.PushFront(1)
+			v, ok := d.At(index)
+			Expect(ok).To(Equal(found))
+			Expect(v).To(Equal(output))
+		},
+		Entry("out of range left", 0, false, -1),
+		Entry("out of range right", 0, false, 3),
+		Entry("index0", 1, true, 0),
+		Entry("index2", 3, true, 2),
+	)
+
+	DescribeTable("Rotate",
+		func(output []int, slice []int, k int) {
+			d := deque.NewFrom(slice)
+			d.Rotate(k)
+			Expect(d.Slice()).To(Equal(output))
+		},
+		Entry("empty", []int{}, []int{}, 1),
+		Entry("zero", []int{1, 2, 3}, []int{1, 2, 3}, 0),
+		Entry("back", []int{3, 1, 2}, []int{1, 2, 3}, 1),
+		Entry("front", []int{2, 3, 1}, []int{1, 2, 3}, -1),
+		Entry("back over size", []int{2, 3, 1}, []int{1, 2, 3}, 5),
+		Entry("front over size", []int{3, 1, 2}, []int{1, 2, 3}, -5),
+		Entry("full buffer", []int{15, 16, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14},
+			[]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, 2),
+	)
+
+	It("Rotate after wrapping", func() {
+		d := deque.New[int]()
+		for i := 1; i <= 5; i++ {
+			d.PushFront(i)
+		}
+		d.Rotate(-2)
+		Expect(d.Slice()).To(Equal([]int{3, 2, 1, 5, 4}))
+		Expect(value(d.PopBack())).To(Equal(4))
+	})
+
+	It("Clear", func() {
+		d := deque.NewFrom([]int{1, 2, 3})
+		d.Clear()
+		Expect(d.IsEmpty()).To(BeTrue())
+		d.PushFront(1)
+		Expect(d.Slice()).To(Equal([]int{1}))
+
+		d.PushFront(0)
+		d.PushBack(2)
+		d.Clear()
+		Expect(d.Size()).To(BeZero())
+		d.PushBack(3)
+		d.PushFront(2)
+		Expect(d.Slice()).To(Equal([]int{2, 3}))
+		Expect(value(d.PopBack())).To(Equal(3))
+	})
+
+	It("Clone", func() {
+		d := deque.NewFrom([]int{1, 2, 3}, gods.WithLocker(gods.LockSpin))
+		clone := d.Clone()
+		Expect(clone.Slice()).To(Equal(d.Slice()))
+		clone.PushBack(4)
+		Expect(d.Size()).To(Equal(3))
+	})
+
+	It("Each", func() {
+		d := deque.NewFrom([]int{1, 2, 3})
+		var sum int
+		d.Each(func(_, v int) bool {
+			sum += v
+			return true
+		})
+		Expect(sum).To(Equal(6))
+
+		var partialSum int
+		d.Each(func(k, v int) bool {
+			if k == 1 {
+				return false
+			}
+			partialSum += v
+			return true
+		})
+		Expect(partialSum).To(Equal(1))
+	})
+
+	It("String", func() {
+		d := deque.NewFrom([]int{2, 3})
+		d.PushFront(1)
+		Expect(d.String()).To(Equal(`[1 2 3]`))
+	})
+})
+
+var _ = containertest.Describe(containertest.Subject[int, *deque.Deque[int]]{
+	Name:     "Deque",
+	New:      func(safe bool) *deque.Deque[int] { return deque.New[int](gods.WithSafe(safe)) },
+	Add:      func(d *deque.Deque[int], item int) { d.PushBack(item) },
+	Generate: func(r *rand.Rand) int { return r.Intn(16) },
+	Ordered:  true,
+})
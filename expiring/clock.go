@@ -0,0 +1,23 @@
+package expiring
+
+import (
+	"time"
+)
+
+// Clock tells the current time, it can be replaced in tests to avoid sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock returns the clock of the system, which is used in default.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// systemClock is a Clock based on time.Now.
+type systemClock struct{}
+
+// Now returns the current local time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
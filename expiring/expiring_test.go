@@ -0,0 +1,267 @@
+package expiring_test
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/containertest"
+	"github.com/lazybabe/gods/expiring"
+)
+
+func TestExpiring(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Expiring Suite")
+}
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+var _ = Describe("Map", func() {
+	var clock *fakeClock
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+	})
+
+	It("Get|Set", func() {
+		m := expiring.NewMapWithClock[string, int](time.Minute, clock)
+		m.Set("a", 1)
+		m.SetWithTTL("b", 2, time.Hour)
+		m.SetWithTTL("c", 3, 0)
+		v, found := m.Get("a")
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal(1))
+		Expect(m.Size()).To(Equal(3))
+
+		clock.Advance(time.Minute)
+		_, found = m.Get("a")
+		Expect(found).To(BeFalse())
+		Expect(m.Contains("b")).To(BeTrue())
+		Expect(m.Size()).To(Equal(2))
+		Expect(m.Keys()).To(ConsistOf("b", "c"))
+
+		clock.Advance(time.Hour)
+		Expect(m.Keys()).To(ConsistOf("c"))
+	})
+
+	It("TTL", func() {
+		m := expiring.NewMapWithClock[string, int](time.Minute, clock)
+		m.Set("a", 1)
+		m.SetWithTTL("b", 2, -1)
+		clock.Advance(time.Second)
+		ttl, found := m.TTL("a")
+		Expect(found).To(BeTrue())
+		Expect(ttl).To(Equal(59 * time.Second))
+		ttl, found = m.TTL("b")
+		Expect(found).To(BeTrue())
+		Expect(ttl).To(BeZero())
+		_, found = m.TTL("c")
+		Expect(found).To(BeFalse())
+	})
+
+	It("Renew", func() {
+		m := expiring.NewMapWithClock[string, int](time.Minute, clock)
+		m.Set("a", 1)
+		clock.Advance(30 * time.Second)
+		m.Set("a", 2)
+		clock.Advance(45 * time.Second)
+		v, found := m.Get("a")
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal(2))
+		m.SetWithTTL("a", 3, 0)
+		clock.Advance(time.Hour)
+		Expect(m.Contains("a")).To(BeTrue())
+	})
+
+	It("DeleteExpired", func() {
+		m := expiring.NewMapWithClock[int, int](time.Minute, clock)
+		for i := 0; i < 10; i++ {
+			m.SetWithTTL(i, i, time.Duration(i+1)*time.Second)
+		}
+		clock.Advance(5 * time.Second)
+		Expect(m.DeleteExpired()).To(Equal(5))
+		Expect(m.DeleteExpired()).To(BeZero())
+		Expect(m.Size()).To(Equal(5))
+	})
+
+	It("Size", func() {
+		m := expiring.NewMapWithClock[int, int](time.Minute, clock)
+		// Shuffled TTLs, so the expired entries are scattered over the heap.
+		for i := 0; i < 100; i++ {
+			m.SetWithTTL(i, i, time.Duration((i*37)%100+1)*time.Second)
+		}
+		m.SetWithTTL(-1, -1, 0)
+		for elapsed := 0; elapsed <= 100; elapsed += 7 {
+			Expect(m.Size()).To(Equal(101 - elapsed))
+			Expect(m.Keys()).To(HaveLen(101 - elapsed))
+			clock.Advance(7 * time.Second)
+		}
+		Expect(m.DeleteExpired()).To(Equal(100))
+		Expect(m.Size()).To(Equal(1))
+	})
+
+	It("Lazy deletion", func() {
+		m := expiring.NewMapWithClock[int, int](time.Second, clock)
+		m.Set(1, 1)
+		m.Set(2, 2)
+		clock.Advance(time.Second)
+		m.Set(3, 3)
+		Expect(m.DeleteExpired()).To(BeZero())
+		Expect(m.Keys()).To(ConsistOf(3))
+	})
+
+	It("Remove|Clear", func() {
+		m := expiring.NewMapWithClock[int, int](time.Minute, clock)
+		m.Set(1, 1)
+		m.Set(2, 2)
+		m.Set(3, 3)
+		m.Remove(1, 4)
+		Expect(m.Keys()).To(ConsistOf(2, 3))
+		m.Clear()
+		Expect(m.Size()).To(BeZero())
+		clock.Advance(time.Hour)
+		Expect(m.DeleteExpired()).To(BeZero())
+	})
+
+	It("Each", func() {
+		m := expiring.NewMapWithClock[int, int](time.Minute, clock)
+		m.Set(1, 1)
+		m.Set(2, 2)
+		var count int
+		m.Each(func(_, _ int) bool {
+			count++
+			return false
+		})
+		Expect(count).To(Equal(1))
+	})
+
+	It("Clone", func() {
+		m := expiring.NewMapWithClock[int, int](time.Minute, clock, gods.WithSafe(true))
+		for i := 0; i < 10; i++ {
+			m.SetWithTTL(i, i, time.Duration(i)*time.Second)
+		}
+		clone := m.Clone()
+		m.Set(10, 10)
+		clone.Remove(9)
+		Expect(clone.Size()).To(Equal(9))
+		clock.Advance(5 * time.Second)
+		Expect(clone.Keys()).To(ConsistOf(0, 6, 7, 8))
+		Expect(clone.DeleteExpired()).To(Equal(5))
+		ttl, found := clone.TTL(8)
+		Expect(found).To(BeTrue())
+		Expect(ttl).To(Equal(3 * time.Second))
+		Expect(m.Keys()).To(ConsistOf(0, 6, 7, 8, 9, 10))
+	})
+
+	It("Janitor", func() {
+		m := expiring.NewMapWithClock[int, int](time.Second, clock)
+		Expect(m.StartJanitor(context.Background(), time.Millisecond)).To(HaveOccurred())
+
+		m = expiring.NewMapWithClock[int, int](time.Second, clock, gods.WithSafe(true))
+		Expect(m.StartJanitor(context.Background(), 0)).To(HaveOccurred())
+		Expect(m.StartJanitor(context.Background(), time.Millisecond)).To(Succeed())
+		Expect(m.StartJanitor(context.Background(), time.Millisecond)).To(HaveOccurred())
+		m.Set(1, 1)
+		clock.Advance(time.Second)
+		time.Sleep(20 * time.Millisecond)
+		Expect(m.DeleteExpired()).To(BeZero())
+		m.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		Expect(m.StartJanitor(ctx, time.Millisecond)).To(Succeed())
+		cancel()
+		Eventually(func() error { return m.StartJanitor(context.Background(), time.Millisecond) }).Should(Succeed())
+		m.Close()
+	})
+})
+
+var _ = Describe("Set", func() {
+	var clock *fakeClock
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+	})
+
+	It("Add|Contains", func() {
+		s := expiring.NewSetWithClock[string](time.Minute, clock)
+		s.Add("a", "b")
+		s.AddWithTTL(time.Hour, "c")
+		Expect(s.Contains("a")).To(BeTrue())
+		Expect(s.Size()).To(Equal(3))
+		clock.Advance(time.Minute)
+		Expect(s.Contains("a")).To(BeFalse())
+		Expect(s.Contains("c")).To(BeTrue())
+		Expect(s.Slice()).To(ConsistOf("c"))
+		Expect(s.String()).To(Equal(`[c]`))
+		ttl, found := s.TTL("c")
+		Expect(found).To(BeTrue())
+		Expect(ttl).To(Equal(59 * time.Minute))
+	})
+
+	It("Remove|Clear", func() {
+		s := expiring.NewSetWithClock[int](time.Minute, clock)
+		s.Add(1, 2, 3)
+		s.Remove(1)
+		Expect(s.String()).To(Equal(`[2 3]`))
+		s.Clear()
+		Expect(s.Size()).To(BeZero())
+	})
+
+	It("DeleteExpired", func() {
+		s := expiring.NewSetWithClock[int](time.Minute, clock)
+		s.Add(1, 2)
+		s.AddWithTTL(0, 3)
+		clock.Advance(time.Minute)
+		Expect(s.DeleteExpired()).To(Equal(2))
+		var items []int
+		s.Each(func(v int) bool { items = append(items, v); return true })
+		Expect(items).To(Equal([]int{3}))
+	})
+
+	It("Janitor", func() {
+		s := expiring.NewSet[int](time.Millisecond, gods.WithSafe(true))
+		Expect(s.StartJanitor(context.Background(), time.Millisecond)).To(Succeed())
+		defer s.Close()
+		s.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		Expect(s.DeleteExpired()).To(BeZero())
+	})
+
+	It("Unsupported options", func() {
+		Expect(func() { expiring.NewSet[int](time.Hour, gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: expiring.Set does not support LockCopyOnWrite"))
+		Expect(func() { expiring.NewMap[int, int](time.Hour, gods.WithCapacity(1)) }).
+			To(PanicWith("gods: expiring.Map does not support WithCapacity"))
+	})
+})
+
+var _ = containertest.Describe(containertest.Subject[int, *expiring.Set[int]]{
+	Name:     "Set",
+	New:      func(safe bool) *expiring.Set[int] { return expiring.NewSet[int](time.Hour, gods.WithSafe(safe)) },
+	Add:      func(s *expiring.Set[int], item int) { s.Add(item) },
+	Remove:   func(s *expiring.Set[int], item int) { s.Remove(item) },
+	Generate: func(r *rand.Rand) int { return r.Intn(32) },
+	Unique:   true,
+})
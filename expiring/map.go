@@ -0,0 +1,320 @@
+package expiring
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// Map is a map whose items expire after a time to live.
+// Expired items are never returned, they are deleted lazily by the writes of the map,
+// by DeleteExpired, or by a background janitor started with StartJanitor.
+type Map[K comparable, V any] struct {
+	mu      rwmutex.RWMutex
+	clock   Clock
+	ttl     time.Duration
+	items   map[K]*entry[K, V]
+	expiry  expiryHeap[K, V]
+	janitor *janitor
+}
+
+// janitor is the background goroutine started by StartJanitor.
+type janitor struct {
+	cancel context.CancelFunc
+}
+
+// entry is an item of the map, the `index` is its position in the expiry heap,
+// which is -1 if it never expires.
+type entry[K comparable, V any] struct {
+	key      K
+	value    V
+	expireAt time.Time
+	index    int
+}
+
+// NewMap creates and returns an empty map whose items live for `ttl` in default.
+// A non-positive `ttl` means the items never expire.
+// The parameter `options` is used to configure the map, see gods.Option.
+// It is not concurrent-safe in default.
+func NewMap[K comparable, V any](ttl time.Duration, options ...gods.Option) *Map[K, V] {
+	return NewMapWithClock[K, V](ttl, SystemClock(), options...)
+}
+
+// NewMapWithClock creates and returns an empty map like NewMap, which tells time by `clock`.
+func NewMapWithClock[K comparable, V any](ttl time.Duration, clock Clock, options ...gods.Option) *Map[K, V] {
+	return newMap[K, V](ttl, clock, "expiring.Map", options)
+}
+
+// newMap creates and returns an empty map for `container`, see rwmutex.CreateWithoutCOW.
+func newMap[K comparable, V any](ttl time.Duration, clock Clock, container string, opts []gods.Option) *Map[K, V] {
+	return &Map[K, V]{
+		mu:    rwmutex.CreateWithoutCOW(container, opts),
+		clock: clock,
+		ttl:   ttl,
+		items: make(map[K]*entry[K, V]),
+	}
+}
+
+// Set sets `value` for `key`, which lives for the default time to live of the map.
+func (m *Map[K, V]) Set(key K, value V) {
+	m.SetWithTTL(key, value, m.ttl)
+}
+
+// SetWithTTL sets `value` for `key`, which lives for `ttl`.
+// A non-positive `ttl` means the item never expires.
+func (m *Map[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	m.doDeleteExpiredWithoutLock(now)
+	e, ok := m.items[key]
+	if !ok {
+		e = &entry[K, V]{key: key, index: -1}
+		m.items[key] = e
+	}
+	e.value = value
+	if ttl > 0 {
+		e.expireAt = now.Add(ttl)
+		if e.index < 0 {
+			heap.Push(&m.expiry, e)
+		} else {
+			heap.Fix(&m.expiry, e.index)
+		}
+	} else {
+		e.expireAt = time.Time{}
+		if e.index >= 0 {
+			heap.Remove(&m.expiry, e.index)
+		}
+	}
+}
+
+// Get returns the value of `key`.
+// If `key` is not in the map or is expired, the `found` is false.
+func (m *Map[K, V]) Get(key K) (value V, found bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.items[key]
+	if !ok || e.expired(m.clock.Now()) {
+		return value, false
+	}
+	return e.value, true
+}
+
+// Contains checks whether `key` is in the map and is not expired.
+func (m *Map[K, V]) Contains(key K) bool {
+	_, found := m.Get(key)
+	return found
+}
+
+// TTL returns the remaining time to live of `key`, which is 0 if it never expires.
+// If `key` is not in the map or is expired, the `found` is false.
+func (m *Map[K, V]) TTL(key K) (ttl time.Duration, found bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	now := m.clock.Now()
+	e, ok := m.items[key]
+	if !ok || e.expired(now) {
+		return 0, false
+	}
+	if e.index < 0 {
+		return 0, true
+	}
+	return e.expireAt.Sub(now), true
+}
+
+// Remove deletes one or multiple keys from the map.
+func (m *Map[K, V]) Remove(keys ...K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.doDeleteExpiredWithoutLock(m.clock.Now())
+	for _, key := range keys {
+		if e, ok := m.items[key]; ok {
+			m.doDeleteWithoutLock(e)
+		}
+	}
+}
+
+// Size returns the number of the items which are not expired.
+// It is O(k) for the k expired items which are not deleted yet, and O(1) if there is none.
+func (m *Map[K, V]) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.items) - m.expiry.countExpired(0, m.clock.Now())
+}
+
+// Keys returns the keys of the items which are not expired in no particular order.
+func (m *Map[K, V]) Keys() []K {
+	keys := make([]K, 0)
+	m.Each(func(key K, _ V) bool { keys = append(keys, key); return true })
+	return keys
+}
+
+// Each calls `fn` on every item which is not expired in no particular order.
+// If `fn` returns true, then it continues iterating; or false to stop.
+func (m *Map[K, V]) Each(fn func(key K, value V) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	now := m.clock.Now()
+	for k, e := range m.items {
+		if e.expired(now) {
+			continue
+		}
+		if !fn(k, e.value) {
+			break
+		}
+	}
+}
+
+// Clear deletes all items of the map.
+func (m *Map[K, V]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items = make(map[K]*entry[K, V])
+	m.expiry = nil
+}
+
+// Clone returns a new map with the items of the map, which keep their expiration time,
+// and the same time to live, clock and configuration. The janitor is not cloned.
+func (m *Map[K, V]) Clone() *Map[K, V] {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c := &Map[K, V]{
+		mu:     rwmutex.CreateWith(gods.NewOptions(m.mu.Options()...)),
+		clock:  m.clock,
+		ttl:    m.ttl,
+		items:  make(map[K]*entry[K, V], len(m.items)),
+		expiry: make(expiryHeap[K, V], len(m.expiry)),
+	}
+	for key, e := range m.items {
+		clone := *e
+		c.items[key] = &clone
+		// The clones keep the positions in the heap, which stays ordered.
+		if e.index >= 0 {
+			c.expiry[e.index] = &clone
+		}
+	}
+	return c
+}
+
+// DeleteExpired deletes all expired items and returns the number of deleted items.
+func (m *Map[K, V]) DeleteExpired() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.doDeleteExpiredWithoutLock(m.clock.Now())
+}
+
+// StartJanitor starts a background goroutine calling DeleteExpired every `interval`,
+// until `ctx` is done or Close is called, after which another janitor can be started.
+// It fails if the map is not in concurrent-safe usage, or if a janitor is running.
+func (m *Map[K, V]) StartJanitor(ctx context.Context, interval time.Duration) error {
+	if !m.mu.IsSafe() {
+		return errors.New("janitor requires a concurrent-safe map")
+	}
+	if interval <= 0 {
+		return errors.New("janitor interval must be positive")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.janitor != nil {
+		return errors.New("janitor is already running")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	j := &janitor{cancel: cancel}
+	m.janitor = j
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				m.mu.Lock()
+				// Close or a new janitor may have replaced it already.
+				if m.janitor == j {
+					m.janitor = nil
+				}
+				m.mu.Unlock()
+				return
+			case <-ticker.C:
+				m.DeleteExpired()
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops the background janitor if it is running.
+// The map remains usable after Close.
+func (m *Map[K, V]) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.janitor != nil {
+		m.janitor.cancel()
+		m.janitor = nil
+	}
+}
+
+// doDeleteExpiredWithoutLock deletes the items expired at `now` without lock,
+// and returns the number of deleted items.
+func (m *Map[K, V]) doDeleteExpiredWithoutLock(now time.Time) int {
+	deleted := 0
+	for len(m.expiry) > 0 && m.expiry[0].expired(now) {
+		m.doDeleteWithoutLock(m.expiry[0])
+		deleted++
+	}
+	return deleted
+}
+
+// doDeleteWithoutLock deletes `e` from both the items and the expiry heap without lock.
+func (m *Map[K, V]) doDeleteWithoutLock(e *entry[K, V]) {
+	if e.index >= 0 {
+		heap.Remove(&m.expiry, e.index)
+	}
+	delete(m.items, e.key)
+}
+
+// expired checks whether `e` is expired at `now`.
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return e.index >= 0 && !now.Before(e.expireAt)
+}
+
+// expiryHeap is a min-heap of the expiring entries ordered by their expiration time,
+// it implements heap.Interface.
+type expiryHeap[K comparable, V any] []*entry[K, V]
+
+// countExpired returns the number of the entries expired at `now` in the subtree of the heap at `i`,
+// which stops at the entries not expired, as the ones below them expire later.
+func (h expiryHeap[K, V]) countExpired(i int, now time.Time) int {
+	if i >= len(h) || !h[i].expired(now) {
+		return 0
+	}
+	return 1 + h.countExpired(2*i+1, now) + h.countExpired(2*i+2, now)
+}
+
+func (h expiryHeap[K, V]) Len() int { return len(h) }
+
+func (h expiryHeap[K, V]) Less(i, j int) bool { return h[i].expireAt.Before(h[j].expireAt) }
+
+func (h expiryHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap[K, V]) Push(x any) {
+	e := x.(*entry[K, V])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap[K, V]) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	e.index = -1
+	*h = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,137 @@
+package expiring
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/iterator"
+)
+
+var _ gods.Collection[int] = (*Set[int])(nil)
+
+// Set is a unordered collection of unique members, which expire after a time to live.
+// It is typically used to deduplicate short-lived items like message IDs.
+type Set[T comparable] struct {
+	data *Map[T, struct{}]
+}
+
+// NewSet creates and returns an empty set whose items live for `ttl` in default.
+// A non-positive `ttl` means the items never expire.
+// The parameter `options` is used to configure the set, see gods.Option.
+// It is not concurrent-safe in default.
+func NewSet[T comparable](ttl time.Duration, options ...gods.Option) *Set[T] {
+	return NewSetWithClock[T](ttl, SystemClock(), options...)
+}
+
+// NewSetWithClock creates and returns an empty set like NewSet, which tells time by `clock`.
+func NewSetWithClock[T comparable](ttl time.Duration, clock Clock, options ...gods.Option) *Set[T] {
+	return &Set[T]{
+		data: newMap[T, struct{}](ttl, clock, "expiring.Set", options),
+	}
+}
+
+// Add adds one or multiple items to the set, which live for the default time to live of the set.
+// Adding an existing item renews its time to live.
+func (s *Set[T]) Add(items ...T) {
+	s.AddWithTTL(s.data.ttl, items...)
+}
+
+// AddWithTTL adds one or multiple items to the set, which live for `ttl`.
+// A non-positive `ttl` means the items never expire.
+func (s *Set[T]) AddWithTTL(ttl time.Duration, items ...T) {
+	for _, item := range items {
+		s.data.SetWithTTL(item, struct{}{}, ttl)
+	}
+}
+
+// Remove deletes one or multiple items from set.
+func (s *Set[T]) Remove(items ...T) {
+	s.data.Remove(items...)
+}
+
+// Contains checks whether the set contains `item` which is not expired.
+func (s *Set[T]) Contains(item T) bool {
+	return s.data.Contains(item)
+}
+
+// TTL returns the remaining time to live of `item`, which is 0 if it never expires.
+// If `item` is not in the set or is expired, the `found` is false.
+func (s *Set[T]) TTL(item T) (ttl time.Duration, found bool) {
+	return s.data.TTL(item)
+}
+
+// Size returns the number of the items which are not expired.
+func (s *Set[T]) Size() int {
+	return s.data.Size()
+}
+
+// Slice returns all items of the set which are not expired as slice.
+func (s *Set[T]) Slice() []T {
+	return s.data.Keys()
+}
+
+// Each calls `fn` on every item which is not expired in no particular order,
+// if `fn` returns true then continue iterating; or false to stop.
+func (s *Set[T]) Each(fn func(item T) bool) {
+	s.data.Each(func(item T, _ struct{}) bool { return fn(item) })
+}
+
+// IsEmpty checks whether the set has no items which are not expired.
+func (s *Set[T]) IsEmpty() bool {
+	return s.Size() == 0
+}
+
+// Iterator returns an iterator over the items which are not expired in no particular order,
+// which are the ones as of the moment Iterator is called.
+func (s *Set[T]) Iterator() gods.Iterator[T] {
+	return iterator.New(s.Slice())
+}
+
+// Clear deletes all items of the set.
+func (s *Set[T]) Clear() {
+	s.data.Clear()
+}
+
+// Clone returns a new set with the items of the set, which keep their expiration time.
+func (s *Set[T]) Clone() *Set[T] {
+	return &Set[T]{data: s.data.Clone()}
+}
+
+// Reset deletes all items of the set like Clear, it implements gods.Container.
+func (s *Set[T]) Reset() {
+	s.Clear()
+}
+
+// Copy returns a new set like Clone, it implements gods.Container.
+func (s *Set[T]) Copy() gods.Container[T] {
+	return s.Clone()
+}
+
+// String returns items as a string.
+func (s *Set[T]) String() string {
+	out := make([]string, 0)
+	s.Each(func(v T) bool { out = append(out, fmt.Sprintf(`%v`, v)); return true })
+	sort.Strings(out)
+	return fmt.Sprintf("%v", out)
+}
+
+// DeleteExpired deletes all expired items and returns the number of deleted items.
+func (s *Set[T]) DeleteExpired() int {
+	return s.data.DeleteExpired()
+}
+
+// StartJanitor starts a background goroutine calling DeleteExpired every `interval`,
+// until `ctx` is done or Close is called.
+// It fails if the set is not in concurrent-safe usage, or if a janitor is running.
+func (s *Set[T]) StartJanitor(ctx context.Context, interval time.Duration) error {
+	return s.data.StartJanitor(ctx, interval)
+}
+
+// Close stops the background janitor if it is running.
+// The set remains usable after Close.
+func (s *Set[T]) Close() {
+	s.data.Close()
+}
@@ -0,0 +1,43 @@
+// Package gods contains the definitions shared by all containers of the gods module.
+package gods
+
+import (
+	"fmt"
+)
+
+// Locker is the locking strategy of a container in concurrent-safe usage.
+type Locker int
+
+const (
+	// LockNone does no locking, the container is not concurrent-safe.
+	LockNone Locker = iota
+	// LockMutex serializes all readers and writers with a sync.Mutex.
+	LockMutex
+	// LockRWMutex allows concurrent readers with a sync.RWMutex.
+	LockRWMutex
+	// LockSpin serializes all readers and writers with a spin lock,
+	// which suits very short critical sections under low contention.
+	LockSpin
+	// LockCopyOnWrite serializes writers with a sync.Mutex, and every write publishes
+	// a new copy of the data, so readers never lock nor block.
+	// It suits read-mostly containers, as every write costs a full copy.
+	// array.COWArray is an array in this usage, which also reads its items without copying.
+	LockCopyOnWrite
+)
+
+// String returns the name of the locking strategy.
+func (l Locker) String() string {
+	switch l {
+	case LockNone:
+		return "none"
+	case LockMutex:
+		return "mutex"
+	case LockRWMutex:
+		return "rwmutex"
+	case LockSpin:
+		return "spin"
+	case LockCopyOnWrite:
+		return "copy-on-write"
+	}
+	return fmt.Sprintf("Locker(%d)", int(l))
+}
@@ -0,0 +1,202 @@
+package gods_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/deque"
+	"github.com/lazybabe/gods/expiring"
+	"github.com/lazybabe/gods/set"
+	"github.com/lazybabe/gods/stack"
+)
+
+func TestGods(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Gods Suite")
+}
+
+var _ = Describe("Gods", func() {
+	DescribeTable("Locker",
+		func(output gods.Locker, options ...gods.Option) {
+			Expect(gods.NewOptions(options...).Locker).To(Equal(output))
+		},
+		Entry("default", gods.LockNone),
+		Entry("WithSafe", gods.LockRWMutex, gods.WithSafe(true)),
+		Entry("WithSafe false", gods.LockNone, gods.WithSafe(false)),
+		Entry("WithLocker", gods.LockSpin, gods.WithLocker(gods.LockSpin)),
+		Entry("WithSafe keeps locker", gods.LockSpin, gods.WithLocker(gods.LockSpin), gods.WithSafe(true)),
+		Entry("last wins", gods.LockNone, gods.WithLocker(gods.LockMutex), gods.WithSafe(false)),
+		Entry("nil", gods.LockNone, nil),
+		Entry("deprecated Safe", gods.LockRWMutex, gods.Safe(true)),
+		Entry("deprecated Safe false", gods.LockNone, gods.WithLocker(gods.LockMutex), gods.Safe(false)),
+		Entry("deprecated Safe empty", gods.LockMutex, gods.WithLocker(gods.LockMutex), gods.Safe()),
+	)
+
+	It("NewOptions", func() {
+		o := gods.NewOptions(gods.WithCapacity(8))
+		Expect(o.Capacity).To(Equal(8))
+		Expect(gods.ComparatorOf[int](o)).To(BeNil())
+		Expect(gods.HooksOf[int](o)).To(Equal(gods.Hooks[int]{}))
+
+		var inserted []int
+		o = gods.NewOptions(
+			gods.WithComparator(func(a, b int) int { return a - b }),
+			gods.WithHooks(gods.Hooks[int]{OnInsert: func(v int) { inserted = append(inserted, v) }}),
+		)
+		Expect(gods.ComparatorOf[int](o)(1, 2)).To(BeNumerically("<", 0))
+		gods.HooksOf[int](o).OnInsert(1)
+		Expect(inserted).To(Equal([]int{1}))
+		Expect(func() { gods.ComparatorOf[string](o) }).To(Panic())
+		Expect(func() { gods.HooksOf[string](o) }).To(Panic())
+
+		o = gods.NewOptions(gods.WithComparator(func(a, b int) int { return a - b }), gods.WithComparator[int](nil))
+		Expect(gods.ComparatorOf[int](o)).To(BeNil())
+
+		Expect(gods.NewOptions().Metrics).To(BeFalse())
+		Expect(gods.NewOptions(gods.WithMetrics(true)).Metrics).To(BeTrue())
+		Expect(gods.NewOptions(gods.WithMetrics(true), gods.WithMetrics(false)).Metrics).To(BeFalse())
+	})
+
+	It("Safe with extra values", func() {
+		Expect(func() { gods.Safe(true, false) }).To(PanicWith("gods: Safe takes at most one value, got 2"))
+	})
+
+	It("Check", func() {
+		Expect(func() { gods.NewOptions(gods.WithSafe(true), gods.WithMetrics(true)).Check("test.Test", 0) }).
+			NotTo(Panic())
+		Expect(func() {
+			gods.NewOptions(gods.WithCapacity(8), gods.WithSnapshot(false)).Check("test.Test", gods.FeatureCapacity)
+		}).NotTo(Panic())
+		Expect(func() { gods.NewOptions(gods.WithCapacity(8)).Check("test.Test", 0) }).
+			To(PanicWith("gods: test.Test does not support WithCapacity"))
+		Expect(func() {
+			gods.NewOptions(
+				gods.WithLocker(gods.LockCopyOnWrite),
+				gods.WithComparator(func(a, b int) int { return a - b }),
+				gods.WithHooks(gods.Hooks[int]{OnInsert: func(int) {}}),
+			).Check("test.Test", gods.FeatureHooks)
+		}).To(PanicWith("gods: test.Test does not support WithComparator|LockCopyOnWrite"))
+		Expect(gods.Feature(0).String()).To(Equal("Feature(0)"))
+		Expect((gods.FeatureSnapshot | 64).String()).To(Equal("WithSnapshot|Feature(64)"))
+	})
+
+	DescribeTable("Bool and locker constructors",
+		func(newPusher func(options ...any) (push func(int), size func() int)) {
+			for _, options := range [][]any{{true}, {gods.WithLocker(gods.LockSpin)}, {true, gods.WithMetrics(true)}} {
+				push, size := newPusher(options...)
+				var wg sync.WaitGroup
+				for g := 0; g < 4; g++ {
+					wg.Add(1)
+					go func(g int) {
+						defer wg.Done()
+						for i := 0; i < 100; i++ {
+							push(g*100 + i)
+						}
+					}(g)
+				}
+				wg.Wait()
+				Expect(size()).To(Equal(400))
+			}
+			push, size := newPusher(false)
+			push(1)
+			Expect(size()).To(Equal(1))
+			Expect(func() { newPusher("safe") }).To(Panic())
+		},
+		Entry("array", func(options ...any) (func(int), func() int) {
+			a := array.New[int](options...)
+			return func(v int) { a.PushRight(v) }, a.Size
+		}),
+		Entry("set", func(options ...any) (func(int), func() int) {
+			s := set.New[int](options...)
+			return func(v int) { s.Add(v) }, s.Size
+		}),
+		Entry("stack", func(options ...any) (func(int), func() int) {
+			s := stack.New[int](options...)
+			return s.Push, s.Size
+		}),
+	)
+
+	It("Locker String", func() {
+		Expect(gods.LockCopyOnWrite.String()).To(Equal("copy-on-write"))
+		Expect(gods.Locker(100).String()).To(Equal("Locker(100)"))
+	})
+
+	DescribeTable("Collection",
+		func(c gods.Collection[int], ordered []int) {
+			Expect(c.Size()).To(Equal(3))
+			Expect(c.IsEmpty()).To(BeFalse())
+			Expect(c.Contains(2)).To(BeTrue())
+			Expect(c.Contains(4)).To(BeFalse())
+			Expect(c.Slice()).To(ConsistOf(1, 2, 3))
+			Expect(c.String()).To(Equal("[1 2 3]"))
+
+			var items []int
+			for it := c.Iterator(); it.Next(); {
+				items = append(items, it.Value())
+				c.Reset()
+			}
+			if ordered != nil {
+				Expect(items).To(Equal(ordered))
+			} else {
+				Expect(items).To(ConsistOf(1, 2, 3))
+			}
+			Expect(c.Size()).To(BeZero())
+			Expect(c.IsEmpty()).To(BeTrue())
+			Expect(c.Slice()).To(BeEmpty())
+		},
+		Entry("array", array.NewFrom([]int{1, 2, 3}, gods.WithSafe(true)), []int{1, 2, 3}),
+		Entry("copy-on-write array", array.NewCOWFrom([]int{1, 2, 3}), []int{1, 2, 3}),
+		Entry("set", set.NewFrom([]int{3, 1, 2}, gods.WithSafe(true)), nil),
+		Entry("tree set", set.NewTreeSetFrom([]int{3, 1, 2}, gods.Compare[int], gods.WithSafe(true)), []int{1, 2, 3}),
+		Entry("stack", stack.NewFrom([]int{1, 2, 3}, gods.WithSafe(true)), []int{3, 2, 1}),
+		Entry("expiring set", newExpiringSet(1, 2, 3), nil),
+	)
+
+	It("Container", func() {
+		var c gods.Container[int] = deque.NewFrom([]int{1, 2, 3})
+		Expect(c.Slice()).To(Equal([]int{1, 2, 3}))
+		Expect(c.String()).To(Equal("[1 2 3]"))
+		clone := c.Copy()
+		c.Reset()
+		Expect(c.IsEmpty()).To(BeTrue())
+		Expect(clone.Slice()).To(Equal([]int{1, 2, 3}))
+	})
+
+	DescribeTable("ReverseIterator",
+		func(it gods.ReverseIterator[int]) {
+			var items []int
+			for it.Prev() {
+				items = append(items, it.Value())
+			}
+			Expect(items).To(Equal([]int{3, 2, 1}))
+			Expect(it.Prev()).To(BeFalse())
+		},
+		Entry("array", array.NewFrom([]int{1, 2, 3}).ReverseIterator()),
+		Entry("copy-on-write array", array.NewCOWFrom([]int{1, 2, 3}).ReverseIterator()),
+		Entry("deque", deque.NewFrom([]int{1, 2, 3}).ReverseIterator()),
+		Entry("tree set", set.NewTreeSetFrom([]int{2, 3, 1}, gods.Compare[int]).ReverseIterator()),
+	)
+
+	It("Compare", func() {
+		Expect(gods.Compare(1, 2)).To(Equal(-1))
+		Expect(gods.Compare(2, 2)).To(Equal(0))
+		Expect(gods.Compare(3, 2)).To(Equal(+1))
+		Expect(gods.Compare("b", "a")).To(Equal(+1))
+		nan := math.NaN()
+		Expect(gods.Compare(nan, 1)).To(Equal(-1))
+		Expect(gods.Compare(1, nan)).To(Equal(+1))
+		Expect(gods.Compare(nan, nan)).To(Equal(0))
+	})
+})
+
+func newExpiringSet(items ...int) *expiring.Set[int] {
+	s := expiring.NewSet[int](0)
+	s.Add(items...)
+	return s
+}
@@ -0,0 +1,108 @@
+package graph
+
+import "container/heap"
+
+// Weight is the constraint of the weights of the graphs Dijkstra searches.
+type Weight interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Paths are the shortest paths from a source vertex to the vertices reachable from it.
+type Paths[V comparable, W Weight] struct {
+	source V
+	// Distances of the vertices reachable from the source.
+	distances map[V]W
+	// Previous vertices of the vertices on their shortest paths from the source.
+	previous map[V]V
+}
+
+// Source returns the source vertex of the paths.
+func (p *Paths[V, W]) Source() V {
+	return p.source
+}
+
+// Distance returns the total weight of the shortest path to `to`.
+// Note that if `to` is not reachable from the source, the `found` is false.
+func (p *Paths[V, W]) Distance(to V) (distance W, found bool) {
+	distance, found = p.distances[to]
+	return
+}
+
+// PathTo returns the vertices of the shortest path from the source to `to`, both included,
+// or nil if `to` is not reachable from the source.
+func (p *Paths[V, W]) PathTo(to V) []V {
+	if _, ok := p.distances[to]; !ok {
+		return nil
+	}
+	var path []V
+	for v := to; v != p.source; v = p.previous[v] {
+		path = append(path, v)
+	}
+	path = append(path, p.source)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Dijkstra returns the shortest paths of `g` from `source` by Dijkstra's algorithm, where the weight
+// of a path is the sum of the weights of its edges. It returns ErrVertexNotFound if `source` is not in
+// the graph, and ErrNegativeWeight if an edge reachable from `source` has a negative or NaN weight.
+func Dijkstra[V comparable, W Weight](g *Graph[V, W], source V) (*Paths[V, W], error) {
+	g.mu.Count("Dijkstra")
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if _, ok := g.vertices[source]; !ok {
+		return nil, ErrVertexNotFound
+	}
+	p := &Paths[V, W]{source: source, distances: map[V]W{source: 0}, previous: make(map[V]V)}
+	done := make(map[V]bool)
+	queue := &distanceHeap[V, W]{{vertex: source}}
+	for queue.Len() > 0 {
+		d := heap.Pop(queue).(distance[V, W])
+		// Skip the outdated distances of the vertices already reached by a shorter path.
+		if done[d.vertex] {
+			continue
+		}
+		done[d.vertex] = true
+		for _, to := range g.neighbors(d.vertex) {
+			weight := g.vertices[d.vertex].out[to]
+			if !(weight >= 0) {
+				return nil, ErrNegativeWeight
+			}
+			if current, ok := p.distances[to]; !done[to] && (!ok || d.distance+weight < current) {
+				p.distances[to], p.previous[to] = d.distance+weight, d.vertex
+				heap.Push(queue, distance[V, W]{vertex: to, distance: d.distance + weight})
+			}
+		}
+	}
+	return p, nil
+}
+
+// distance is a vertex reached at a distance from the source.
+type distance[V comparable, W Weight] struct {
+	vertex   V
+	distance W
+}
+
+// distanceHeap is a min-heap of the vertices ordered by their distances, it implements heap.Interface.
+type distanceHeap[V comparable, W Weight] []distance[V, W]
+
+func (h distanceHeap[V, W]) Len() int { return len(h) }
+
+func (h distanceHeap[V, W]) Less(i, j int) bool { return h[i].distance < h[j].distance }
+
+func (h distanceHeap[V, W]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *distanceHeap[V, W]) Push(x any) {
+	*h = append(*h, x.(distance[V, W]))
+}
+
+func (h *distanceHeap[V, W]) Pop() any {
+	old := *h
+	d := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return d
+}
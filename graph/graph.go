@@ -0,0 +1,488 @@
+// Package graph implements directed and undirected graphs with weighted edges,
+// and the usual algorithms over them: traversals, topological sorting, shortest paths
+// and strongly connected components.
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/iterator"
+	"github.com/lazybabe/gods/internal/rwmutex"
+	"github.com/lazybabe/gods/set"
+)
+
+var (
+	// ErrCycle is returned when sorting a graph with a cycle topologically.
+	ErrCycle = errors.New("graph has a cycle")
+	// ErrUndirected is returned when sorting an undirected graph topologically.
+	ErrUndirected = errors.New("graph is undirected")
+	// ErrVertexNotFound is returned when searching paths from a vertex not in the graph.
+	ErrVertexNotFound = errors.New("vertex not found")
+	// ErrNegativeWeight is returned when searching shortest paths in a graph with a negative or NaN weight.
+	ErrNegativeWeight = errors.New("negative weight")
+)
+
+// Edge is an edge of a graph from the vertex From to the vertex To.
+type Edge[V comparable, W any] struct {
+	From   V
+	To     V
+	Weight W
+}
+
+// vertex is a vertex of a graph with its edges.
+type vertex[V comparable, W any] struct {
+	// Sequence number of the vertex, which orders the vertices by the time they are added.
+	id int
+	// Weights of the edges from the vertex by the vertices they go to.
+	out map[V]W
+	// Vertices of the edges to the vertex.
+	in map[V]struct{}
+}
+
+// Graph is a graph of vertices of type V and edges weighted by values of type W,
+// which is struct{} for unweighted graphs.
+// There is at most one edge from a vertex to another, and an edge of an undirected graph goes both ways.
+//
+// The vertices and the edges are visited in the order they are added, so the results are deterministic.
+type Graph[V comparable, W any] struct {
+	mu       rwmutex.RWMutex
+	directed bool
+	vertices map[V]*vertex[V, W]
+	edges    int
+	// Sequence number of the next vertex.
+	next int
+}
+
+// NewDirected creates and returns an empty directed graph.
+// The parameter `options` is used to configure the graph, see gods.Option.
+// It is not concurrent-safe in default.
+//
+// The graph supports gods.WithCapacity, which is the number of vertices.
+func NewDirected[V comparable, W any](options ...gods.Option) *Graph[V, W] {
+	return newGraph[V, W](true, options)
+}
+
+// NewUndirected creates and returns an empty undirected graph.
+// The parameter `options` is used to configure the graph, see gods.Option.
+// It is not concurrent-safe in default.
+//
+// The graph supports gods.WithCapacity, which is the number of vertices.
+func NewUndirected[V comparable, W any](options ...gods.Option) *Graph[V, W] {
+	return newGraph[V, W](false, options)
+}
+
+// newGraph creates and returns an empty graph, which is directed if `directed` is true.
+func newGraph[V comparable, W any](directed bool, opts []gods.Option) *Graph[V, W] {
+	o := gods.NewOptions(opts...)
+	o.Check("graph.Graph", gods.FeatureCapacity)
+	return &Graph[V, W]{
+		mu:       rwmutex.CreateWith(o),
+		directed: directed,
+		vertices: make(map[V]*vertex[V, W], o.Capacity),
+	}
+}
+
+// IsDirected checks whether the graph is directed.
+func (g *Graph[V, W]) IsDirected() bool {
+	return g.directed
+}
+
+// AddVertex adds one or multiple vertices to the graph, unless they are already in the graph.
+func (g *Graph[V, W]) AddVertex(vertices ...V) {
+	g.mu.Count("AddVertex")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, v := range vertices {
+		g.vertex(v)
+	}
+}
+
+// RemoveVertex removes `v` and its edges from the graph, and reports whether it is in the graph.
+func (g *Graph[V, W]) RemoveVertex(v V) bool {
+	g.mu.Count("RemoveVertex")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	vv, ok := g.vertices[v]
+	if !ok {
+		return false
+	}
+	for to := range vv.out {
+		g.doRemoveEdgeWithoutLock(v, to)
+	}
+	for from := range vv.in {
+		g.doRemoveEdgeWithoutLock(from, v)
+	}
+	delete(g.vertices, v)
+	return true
+}
+
+// HasVertex checks whether `v` is in the graph.
+func (g *Graph[V, W]) HasVertex(v V) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	_, ok := g.vertices[v]
+	return ok
+}
+
+// AddEdge adds an edge from `from` to `to` weighted `weight`, which replaces the weight of
+// the edge if it is already in the graph. The vertices are added first if they are not in the graph.
+func (g *Graph[V, W]) AddEdge(from, to V, weight W) {
+	g.mu.Count("AddEdge")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	vf, vt := g.vertex(from), g.vertex(to)
+	if _, ok := vf.out[to]; !ok {
+		g.edges++
+	}
+	vf.out[to], vt.in[from] = weight, struct{}{}
+	if !g.directed {
+		vt.out[from], vf.in[to] = weight, struct{}{}
+	}
+}
+
+// RemoveEdge removes the edge from `from` to `to`, and reports whether it is in the graph.
+func (g *Graph[V, W]) RemoveEdge(from, to V) bool {
+	g.mu.Count("RemoveEdge")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.doRemoveEdgeWithoutLock(from, to)
+}
+
+// doRemoveEdgeWithoutLock removes the edge from `from` to `to` without locking.
+func (g *Graph[V, W]) doRemoveEdgeWithoutLock(from, to V) bool {
+	vf, ok := g.vertices[from]
+	if !ok {
+		return false
+	}
+	if _, ok = vf.out[to]; !ok {
+		return false
+	}
+	vt := g.vertices[to]
+	delete(vf.out, to)
+	delete(vt.in, from)
+	if !g.directed {
+		delete(vt.out, from)
+		delete(vf.in, to)
+	}
+	g.edges--
+	return true
+}
+
+// HasEdge checks whether the edge from `from` to `to` is in the graph.
+func (g *Graph[V, W]) HasEdge(from, to V) bool {
+	_, found := g.Weight(from, to)
+	return found
+}
+
+// Weight returns the weight of the edge from `from` to `to`.
+// Note that if the edge is not in the graph, the `found` is false.
+func (g *Graph[V, W]) Weight(from, to V) (weight W, found bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if vf, ok := g.vertices[from]; ok {
+		weight, found = vf.out[to]
+	}
+	return
+}
+
+// Neighbors returns the vertices the edges from `v` go to, which is empty if `v` is not in the graph.
+// The parameter `options` is used to configure the set, see gods.Option.
+func (g *Graph[V, W]) Neighbors(v V, options ...gods.Option) *set.Set[V] {
+	g.mu.Count("Neighbors")
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	neighbors := set.New[V](rwmutex.Any(options)...)
+	if vv, ok := g.vertices[v]; ok {
+		for to := range vv.out {
+			neighbors.Add(to)
+		}
+	}
+	return neighbors
+}
+
+// Vertices returns the vertices of the graph in the order they are added.
+func (g *Graph[V, W]) Vertices() []V {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.sortedVertices()
+}
+
+// Edges returns the edges of the graph in the order of the vertices they go from,
+// then of the vertices they go to. An edge of an undirected graph is returned once,
+// from the vertex added first.
+func (g *Graph[V, W]) Edges() []Edge[V, W] {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	edges := make([]Edge[V, W], 0, g.edges)
+	for _, from := range g.sortedVertices() {
+		vf := g.vertices[from]
+		for _, to := range g.neighbors(from) {
+			if g.directed || vf.id <= g.vertices[to].id {
+				edges = append(edges, Edge[V, W]{From: from, To: to, Weight: vf.out[to]})
+			}
+		}
+	}
+	return edges
+}
+
+// VertexCount returns the number of vertices of the graph.
+func (g *Graph[V, W]) VertexCount() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.vertices)
+}
+
+// EdgeCount returns the number of edges of the graph, where an edge of an undirected graph counts once.
+func (g *Graph[V, W]) EdgeCount() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.edges
+}
+
+// Clear removes all vertices and edges of the graph.
+func (g *Graph[V, W]) Clear() {
+	g.mu.Count("Clear")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.vertices = make(map[V]*vertex[V, W])
+	g.edges = 0
+}
+
+// BFS returns an iterator over the vertices reachable from `start` in breadth-first order,
+// which are the ones as of the moment BFS is called. It is empty if `start` is not in the graph.
+func (g *Graph[V, W]) BFS(start V) gods.Iterator[V] {
+	g.mu.Count("BFS")
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if _, ok := g.vertices[start]; !ok {
+		return iterator.New[V](nil)
+	}
+	visited := map[V]bool{start: true}
+	order := []V{start}
+	for i := 0; i < len(order); i++ {
+		for _, to := range g.neighbors(order[i]) {
+			if !visited[to] {
+				visited[to] = true
+				order = append(order, to)
+			}
+		}
+	}
+	return iterator.New(order)
+}
+
+// DFS returns an iterator over the vertices reachable from `start` in depth-first preorder,
+// which are the ones as of the moment DFS is called. It is empty if `start` is not in the graph.
+func (g *Graph[V, W]) DFS(start V) gods.Iterator[V] {
+	g.mu.Count("DFS")
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if _, ok := g.vertices[start]; !ok {
+		return iterator.New[V](nil)
+	}
+	visited := make(map[V]bool)
+	var order []V
+	stack := []V{start}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[v] {
+			continue
+		}
+		visited[v] = true
+		order = append(order, v)
+		// Push the neighbors backwards, so they are visited in order.
+		neighbors := g.neighbors(v)
+		for i := len(neighbors) - 1; i >= 0; i-- {
+			if !visited[neighbors[i]] {
+				stack = append(stack, neighbors[i])
+			}
+		}
+	}
+	return iterator.New(order)
+}
+
+// TopologicalSort returns the vertices of the directed graph in an order where every edge goes
+// from a vertex to a later one, the vertices added first coming first when there is a choice.
+// It returns ErrCycle if the graph has a cycle, and ErrUndirected if the graph is undirected.
+func (g *Graph[V, W]) TopologicalSort() ([]V, error) {
+	g.mu.Count("TopologicalSort")
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if !g.directed {
+		return nil, ErrUndirected
+	}
+	vertices := g.sortedVertices()
+	degrees := make(map[V]int, len(vertices))
+	order := make([]V, 0, len(vertices))
+	for _, v := range vertices {
+		degrees[v] = len(g.vertices[v].in)
+		if degrees[v] == 0 {
+			order = append(order, v)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		for _, to := range g.neighbors(order[i]) {
+			if degrees[to]--; degrees[to] == 0 {
+				order = append(order, to)
+			}
+		}
+	}
+	if len(order) < len(vertices) {
+		return nil, ErrCycle
+	}
+	return order, nil
+}
+
+// StronglyConnectedComponents returns the strongly connected components of the graph as sets,
+// where every vertex is reachable from every other one of its component.
+// They are in reverse topological order: no edge goes from a component to a later one.
+// The components of an undirected graph are its connected components.
+// The parameter `options` is used to configure the sets, see gods.Option.
+func (g *Graph[V, W]) StronglyConnectedComponents(options ...gods.Option) []*set.Set[V] {
+	g.mu.Count("StronglyConnectedComponents")
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	// Tarjan's algorithm, with an explicit stack of the vertices being visited instead of recursion.
+	type frame struct {
+		v         V
+		neighbors []V
+		next      int
+	}
+	var (
+		components []*set.Set[V]
+		index      = make(map[V]int, len(g.vertices))
+		low        = make(map[V]int, len(g.vertices))
+		onStack    = make(map[V]bool, len(g.vertices))
+		stack      []V
+		frames     []*frame
+	)
+	visit := func(v V) {
+		index[v], low[v] = len(index), len(index)
+		stack = append(stack, v)
+		onStack[v] = true
+		frames = append(frames, &frame{v: v, neighbors: g.neighbors(v)})
+	}
+	for _, v := range g.sortedVertices() {
+		if _, ok := index[v]; ok {
+			continue
+		}
+		visit(v)
+		for len(frames) > 0 {
+			f := frames[len(frames)-1]
+			if f.next < len(f.neighbors) {
+				to := f.neighbors[f.next]
+				f.next++
+				if _, ok := index[to]; !ok {
+					visit(to)
+				} else if onStack[to] && index[to] < low[f.v] {
+					low[f.v] = index[to]
+				}
+				continue
+			}
+			frames = frames[:len(frames)-1]
+			if len(frames) > 0 {
+				if parent := frames[len(frames)-1].v; low[f.v] < low[parent] {
+					low[parent] = low[f.v]
+				}
+			}
+			if low[f.v] != index[f.v] {
+				continue
+			}
+			// The vertex is the root of a component, which is the vertices above it on the stack.
+			component := set.New[V](rwmutex.Any(options)...)
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component.Add(w)
+				if w == f.v {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+	return components
+}
+
+// DOT returns the graph in the DOT language of Graphviz, where the vertices and the weights
+// are labeled by their default formats. The weights of type struct{} are omitted.
+func (g *Graph[V, W]) DOT() string {
+	g.mu.Count("DOT")
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var b strings.Builder
+	kind, arrow := "graph", "--"
+	if g.directed {
+		kind, arrow = "digraph", "->"
+	}
+	b.WriteString(kind + " {\n")
+	vertices := g.sortedVertices()
+	for _, v := range vertices {
+		fmt.Fprintf(&b, "\t%s;\n", strconv.Quote(fmt.Sprint(v)))
+	}
+	for _, from := range vertices {
+		vf := g.vertices[from]
+		for _, to := range g.neighbors(from) {
+			if !g.directed && vf.id > g.vertices[to].id {
+				continue
+			}
+			fmt.Fprintf(&b, "\t%s %s %s", strconv.Quote(fmt.Sprint(from)), arrow, strconv.Quote(fmt.Sprint(to)))
+			if _, unweighted := any(vf.out[to]).(struct{}); !unweighted {
+				fmt.Fprintf(&b, " [label=%s]", strconv.Quote(fmt.Sprint(vf.out[to])))
+			}
+			b.WriteString(";\n")
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Stats returns a snapshot of the metrics of the graph created with gods.WithMetrics(true).
+func (g *Graph[V, W]) Stats() gods.Stats {
+	return g.mu.Stats()
+}
+
+// vertex returns the vertex `v`, which is added first if it is not in the graph.
+func (g *Graph[V, W]) vertex(v V) *vertex[V, W] {
+	vv, ok := g.vertices[v]
+	if !ok {
+		vv = &vertex[V, W]{id: g.next, out: make(map[V]W), in: make(map[V]struct{})}
+		g.vertices[v] = vv
+		g.next++
+	}
+	return vv
+}
+
+// sortedVertices returns the vertices in the order they are added.
+func (g *Graph[V, W]) sortedVertices() []V {
+	vertices := make([]V, 0, len(g.vertices))
+	for v := range g.vertices {
+		vertices = append(vertices, v)
+	}
+	g.sortByID(vertices)
+	return vertices
+}
+
+// neighbors returns the vertices the edges from `v` go to in the order they are added.
+func (g *Graph[V, W]) neighbors(v V) []V {
+	out := g.vertices[v].out
+	neighbors := make([]V, 0, len(out))
+	for to := range out {
+		neighbors = append(neighbors, to)
+	}
+	g.sortByID(neighbors)
+	return neighbors
+}
+
+// sortByID sorts `vertices` in the order they are added.
+func (g *Graph[V, W]) sortByID(vertices []V) {
+	sort.Slice(vertices, func(i, j int) bool {
+		return g.vertices[vertices[i]].id < g.vertices[vertices[j]].id
+	})
+}
@@ -0,0 +1,282 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/graph"
+	"github.com/lazybabe/gods/set"
+)
+
+func TestGraph(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Graph Suite")
+}
+
+// collect returns the items of `it`.
+func collect[T any](it gods.Iterator[T]) []T {
+	items := make([]T, 0)
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+	return items
+}
+
+// sorted returns the items of the sets sorted.
+func sorted(sets []*set.Set[string]) [][]string {
+	s := make([][]string, len(sets))
+	for i := range sets {
+		s[i] = set.NewTreeSetFrom(sets[i].Slice(), gods.Compare[string]).Slice()
+	}
+	return s
+}
+
+var _ = Describe("Graph", func() {
+	It("Directed", func() {
+		g := graph.NewDirected[string, int]()
+		Expect(g.IsDirected()).To(BeTrue())
+		g.AddVertex("a", "b")
+		g.AddEdge("a", "b", 1)
+		g.AddEdge("b", "c", 2)
+		g.AddEdge("a", "c", 5)
+		g.AddEdge("a", "c", 4)
+		Expect(g.VertexCount()).To(Equal(3))
+		Expect(g.EdgeCount()).To(Equal(3))
+		Expect(g.Vertices()).To(Equal([]string{"a", "b", "c"}))
+		Expect(g.HasVertex("c")).To(BeTrue())
+		Expect(g.HasEdge("a", "b")).To(BeTrue())
+		Expect(g.HasEdge("b", "a")).To(BeFalse())
+		weight, found := g.Weight("a", "c")
+		Expect(found).To(BeTrue())
+		Expect(weight).To(Equal(4))
+		Expect(g.Neighbors("a").Equal(set.NewFrom([]string{"b", "c"}))).To(BeTrue())
+		Expect(g.Neighbors("c").IsEmpty()).To(BeTrue())
+		Expect(g.Neighbors("x").IsEmpty()).To(BeTrue())
+		Expect(g.Edges()).To(Equal([]graph.Edge[string, int]{
+			{From: "a", To: "b", Weight: 1}, {From: "a", To: "c", Weight: 4}, {From: "b", To: "c", Weight: 2},
+		}))
+
+		Expect(g.RemoveEdge("b", "a")).To(BeFalse())
+		Expect(g.RemoveEdge("x", "a")).To(BeFalse())
+		Expect(g.RemoveEdge("a", "b")).To(BeTrue())
+		Expect(g.EdgeCount()).To(Equal(2))
+		Expect(g.RemoveVertex("c")).To(BeTrue())
+		Expect(g.RemoveVertex("c")).To(BeFalse())
+		Expect(g.EdgeCount()).To(BeZero())
+		Expect(g.Vertices()).To(Equal([]string{"a", "b"}))
+
+		g.Clear()
+		Expect(g.VertexCount()).To(BeZero())
+		Expect(g.EdgeCount()).To(BeZero())
+	})
+
+	It("Undirected", func() {
+		g := graph.NewUndirected[int, struct{}]()
+		Expect(g.IsDirected()).To(BeFalse())
+		g.AddEdge(1, 2, struct{}{})
+		g.AddEdge(2, 3, struct{}{})
+		g.AddEdge(3, 2, struct{}{})
+		g.AddEdge(3, 3, struct{}{})
+		Expect(g.EdgeCount()).To(Equal(3))
+		Expect(g.HasEdge(2, 1)).To(BeTrue())
+		Expect(g.Neighbors(3).Equal(set.NewFrom([]int{2, 3}))).To(BeTrue())
+		Expect(g.Edges()).To(Equal([]graph.Edge[int, struct{}]{{From: 1, To: 2}, {From: 2, To: 3}, {From: 3, To: 3}}))
+
+		Expect(g.RemoveEdge(2, 1)).To(BeTrue())
+		Expect(g.HasEdge(1, 2)).To(BeFalse())
+		Expect(g.RemoveVertex(3)).To(BeTrue())
+		Expect(g.EdgeCount()).To(BeZero())
+		Expect(g.Neighbors(2).IsEmpty()).To(BeTrue())
+
+		_, err := g.TopologicalSort()
+		Expect(err).To(MatchError(graph.ErrUndirected))
+	})
+
+	It("BFS and DFS", func() {
+		g := graph.NewDirected[int, struct{}]()
+		for _, e := range [][2]int{{1, 2}, {1, 3}, {2, 4}, {3, 4}, {4, 5}, {2, 6}, {5, 1}} {
+			g.AddEdge(e[0], e[1], struct{}{})
+		}
+		g.AddVertex(7)
+		Expect(collect(g.BFS(1))).To(Equal([]int{1, 2, 3, 4, 6, 5}))
+		Expect(collect(g.DFS(1))).To(Equal([]int{1, 2, 4, 5, 6, 3}))
+		Expect(collect(g.BFS(6))).To(Equal([]int{6}))
+		Expect(collect(g.DFS(7))).To(Equal([]int{7}))
+		Expect(collect(g.BFS(8))).To(BeEmpty())
+		Expect(collect(g.DFS(8))).To(BeEmpty())
+
+		// The iterators are over the vertices as of the moment they are created.
+		it := g.BFS(1)
+		g.RemoveVertex(2)
+		Expect(collect(it)).To(HaveLen(6))
+	})
+
+	It("TopologicalSort", func() {
+		g := graph.NewDirected[string, struct{}]()
+		g.AddVertex("shirt", "tie", "jacket", "belt", "trousers", "shoes", "socks")
+		for _, e := range [][2]string{
+			{"shirt", "tie"}, {"tie", "jacket"}, {"shirt", "belt"}, {"belt", "jacket"},
+			{"trousers", "belt"}, {"trousers", "shoes"}, {"socks", "shoes"},
+		} {
+			g.AddEdge(e[0], e[1], struct{}{})
+		}
+		order, err := g.TopologicalSort()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(order).To(Equal([]string{"shirt", "trousers", "socks", "tie", "belt", "shoes", "jacket"}))
+
+		g.AddEdge("jacket", "shirt", struct{}{})
+		_, err = g.TopologicalSort()
+		Expect(err).To(MatchError(graph.ErrCycle))
+
+		order, err = graph.NewDirected[string, struct{}]().TopologicalSort()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(order).To(BeEmpty())
+	})
+
+	It("StronglyConnectedComponents", func() {
+		g := graph.NewDirected[string, struct{}]()
+		for _, e := range [][2]string{
+			{"a", "b"}, {"b", "c"}, {"c", "a"}, {"b", "d"}, {"d", "e"}, {"e", "d"}, {"e", "f"}, {"g", "g"},
+		} {
+			g.AddEdge(e[0], e[1], struct{}{})
+		}
+		Expect(sorted(g.StronglyConnectedComponents())).To(Equal([][]string{{"f"}, {"d", "e"}, {"a", "b", "c"}, {"g"}}))
+
+		u := graph.NewUndirected[string, struct{}]()
+		u.AddEdge("a", "b", struct{}{})
+		u.AddEdge("c", "b", struct{}{})
+		u.AddEdge("d", "e", struct{}{})
+		u.AddVertex("f")
+		Expect(sorted(u.StronglyConnectedComponents())).To(Equal([][]string{{"a", "b", "c"}, {"d", "e"}, {"f"}}))
+	})
+
+	It("StronglyConnectedComponents of a long path", func() {
+		g := graph.NewDirected[int, struct{}]()
+		for i := 0; i < 100000; i++ {
+			g.AddEdge(i, i+1, struct{}{})
+		}
+		g.AddEdge(100000, 0, struct{}{})
+		components := g.StronglyConnectedComponents()
+		Expect(components).To(HaveLen(1))
+		Expect(components[0].Size()).To(Equal(100001))
+	})
+
+	It("Dijkstra", func() {
+		g := graph.NewDirected[string, float64]()
+		g.AddEdge("a", "b", 7)
+		g.AddEdge("a", "c", 9)
+		g.AddEdge("a", "f", 14)
+		g.AddEdge("b", "c", 10)
+		g.AddEdge("b", "d", 15)
+		g.AddEdge("c", "d", 11)
+		g.AddEdge("c", "f", 2)
+		g.AddEdge("d", "e", 6)
+		g.AddEdge("f", "e", 9)
+		g.AddVertex("x")
+		paths, err := graph.Dijkstra(g, "a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths.Source()).To(Equal("a"))
+		distance, found := paths.Distance("e")
+		Expect(found).To(BeTrue())
+		Expect(distance).To(Equal(20.0))
+		Expect(paths.PathTo("e")).To(Equal([]string{"a", "c", "f", "e"}))
+		Expect(paths.PathTo("a")).To(Equal([]string{"a"}))
+		_, found = paths.Distance("x")
+		Expect(found).To(BeFalse())
+		Expect(paths.PathTo("x")).To(BeNil())
+
+		_, err = graph.Dijkstra(g, "y")
+		Expect(err).To(MatchError(graph.ErrVertexNotFound))
+		g.AddEdge("e", "x", -1)
+		_, err = graph.Dijkstra(g, "a")
+		Expect(err).To(MatchError(graph.ErrNegativeWeight))
+		g.AddEdge("e", "x", math.NaN())
+		_, err = graph.Dijkstra(g, "a")
+		Expect(err).To(MatchError(graph.ErrNegativeWeight))
+	})
+
+	It("Dijkstra matches Bellman-Ford on random graphs", func() {
+		r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+		for n := 0; n < 20; n++ {
+			g := graph.NewUndirected[int, uint]()
+			g.AddVertex(0)
+			for i := 0; i < 100; i++ {
+				g.AddEdge(r.Intn(30), r.Intn(30), uint(r.Intn(20)))
+			}
+			paths, err := graph.Dijkstra(g, 0)
+			Expect(err).NotTo(HaveOccurred())
+			distances := map[int]uint{0: 0}
+			for i := 0; i < 30; i++ {
+				for _, e := range g.Edges() {
+					for _, e := range []graph.Edge[int, uint]{e, {From: e.To, To: e.From, Weight: e.Weight}} {
+						if d, ok := distances[e.From]; ok {
+							if current, ok := distances[e.To]; !ok || d+e.Weight < current {
+								distances[e.To] = d + e.Weight
+							}
+						}
+					}
+				}
+			}
+			for _, v := range g.Vertices() {
+				distance, found := paths.Distance(v)
+				want, ok := distances[v]
+				Expect(found).To(Equal(ok))
+				Expect(distance).To(Equal(want))
+				if found {
+					path := paths.PathTo(v)
+					var sum uint
+					for i := 1; i < len(path); i++ {
+						weight, _ := g.Weight(path[i-1], path[i])
+						sum += weight
+					}
+					Expect(sum).To(Equal(want))
+				}
+			}
+		}
+	})
+
+	It("DOT", func() {
+		g := graph.NewDirected[string, int]()
+		g.AddEdge("a", "b", 1)
+		g.AddEdge("b", `say "hi"`, 2)
+		g.AddVertex("c")
+		Expect(g.DOT()).To(Equal("digraph {\n" +
+			"\t\"a\";\n\t\"b\";\n\t\"say \\\"hi\\\"\";\n\t\"c\";\n" +
+			"\t\"a\" -> \"b\" [label=\"1\"];\n\t\"b\" -> \"say \\\"hi\\\"\" [label=\"2\"];\n" +
+			"}\n"))
+
+		u := graph.NewUndirected[int, struct{}]()
+		u.AddEdge(2, 1, struct{}{})
+		Expect(u.DOT()).To(Equal("graph {\n\t\"2\";\n\t\"1\";\n\t\"2\" -- \"1\";\n}\n"))
+	})
+
+	It("Safe", func() {
+		Expect(func() { graph.NewDirected[int, int](gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: graph.Graph does not support LockCopyOnWrite"))
+		g := graph.NewUndirected[int, int](gods.WithSafe(true), gods.WithMetrics(true), gods.WithCapacity(800))
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					g.AddEdge(w*1000+i, w*1000+i+1, i)
+					collect(g.BFS(w * 1000))
+					_, err := graph.Dijkstra(g, w*1000)
+					Expect(err).NotTo(HaveOccurred())
+				}
+			}(w)
+		}
+		wg.Wait()
+		Expect(g.EdgeCount()).To(Equal(800))
+		Expect(g.StronglyConnectedComponents()).To(HaveLen(4))
+		Expect(g.Stats().Operations).To(HaveKeyWithValue("AddEdge", uint64(800)))
+	})
+})
@@ -0,0 +1,137 @@
+package hashmap
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// Map is a unordered collection of key-value pairs guarded by a single lock.
+type Map[K comparable, V any] struct {
+	mu   rwmutex.RWMutex
+	data map[K]V
+}
+
+// New creates and returns an empty map.
+// The parameter `options` is used to configure the map, see gods.Option.
+// It is not concurrent-safe in default.
+//
+// The map supports gods.WithCapacity.
+func New[K comparable, V any](options ...gods.Option) *Map[K, V] {
+	return NewFrom[K, V](nil, options...)
+}
+
+// NewFrom creates and returns a map with a copy of `data`.
+// The parameter `options` is used to configure the map, see gods.Option.
+// It is not concurrent-safe in default.
+func NewFrom[K comparable, V any](data map[K]V, options ...gods.Option) *Map[K, V] {
+	o := gods.NewOptions(options...)
+	o.Check("hashmap.Map", gods.FeatureCapacity)
+	m := &Map[K, V]{
+		mu:   rwmutex.CreateWith(o),
+		data: make(map[K]V, max(len(data), o.Capacity)),
+	}
+	for k, v := range data {
+		m.data[k] = v
+	}
+	return m
+}
+
+// Set sets `value` for `key`.
+func (m *Map[K, V]) Set(key K, value V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+}
+
+// Get returns the value of `key`.
+// If `key` is not in the map, the `found` is false.
+func (m *Map[K, V]) Get(key K) (value V, found bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, found = m.data[key]
+	return
+}
+
+// Contains checks whether `key` is in the map.
+func (m *Map[K, V]) Contains(key K) bool {
+	_, found := m.Get(key)
+	return found
+}
+
+// Remove deletes one or multiple keys from the map.
+func (m *Map[K, V]) Remove(keys ...K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.data, key)
+	}
+}
+
+// Size returns the number of items in the map.
+func (m *Map[K, V]) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+// Keys returns all keys of the map in no particular order.
+func (m *Map[K, V]) Keys() []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]K, 0, len(m.data))
+	for k := range m.data {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Each calls `fn` on every item of the map in no particular order.
+// If `fn` returns true, then it continues iterating; or false to stop.
+func (m *Map[K, V]) Each(fn func(key K, value V) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for k, v := range m.data {
+		if !fn(k, v) {
+			break
+		}
+	}
+}
+
+// Clear deletes all items of the map.
+func (m *Map[K, V]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = make(map[K]V)
+}
+
+// Snapshot returns a copy of the items of the map as a builtin map.
+func (m *Map[K, V]) Snapshot() map[K]V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data := make(map[K]V, len(m.data))
+	for k, v := range m.data {
+		data[k] = v
+	}
+	return data
+}
+
+// Clone returns a new map, which is a copy of current map.
+func (m *Map[K, V]) Clone() *Map[K, V] {
+	return NewFrom(m.Snapshot(), m.mu.Options()...)
+}
+
+// String returns the items of the map as a string sorted by keys.
+func (m *Map[K, V]) String() string {
+	return toString(m.Each)
+}
+
+// toString formats the items iterated by `each` like fmt does with a builtin map.
+func toString[K comparable, V any](each func(fn func(key K, value V) bool)) string {
+	out := make([]string, 0)
+	each(func(k K, v V) bool { out = append(out, fmt.Sprintf(`%v:%v`, k, v)); return true })
+	sort.Strings(out)
+	return fmt.Sprintf("map%v", out)
+}
@@ -0,0 +1,203 @@
+package hashmap_test
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/hashmap"
+)
+
+func TestHashmap(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hashmap Suite")
+}
+
+// container is the API shared by Map and ShardedMap.
+type container[K comparable, V any] interface {
+	Set(key K, value V)
+	Get(key K) (value V, found bool)
+	Contains(key K) bool
+	Remove(keys ...K)
+	Size() int
+	Keys() []K
+	Each(fn func(key K, value V) bool)
+	Clear()
+	Snapshot() map[K]V
+	String() string
+}
+
+var _ = DescribeTable("Map API",
+	func(newMap func() container[string, int]) {
+		m := newMap()
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		v, found := m.Get("a")
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal(1))
+		_, found = m.Get("d")
+		Expect(found).To(BeFalse())
+		Expect(m.Contains("b")).To(BeTrue())
+		Expect(m.Size()).To(Equal(3))
+		Expect(m.Keys()).To(ConsistOf("a", "b", "c"))
+		Expect(m.String()).To(Equal(`map[a:1 b:2 c:3]`))
+
+		var sum, count int
+		m.Each(func(_ string, v int) bool { sum += v; return true })
+		Expect(sum).To(Equal(6))
+		m.Each(func(_ string, _ int) bool { count++; return false })
+		Expect(count).To(Equal(1))
+
+		snapshot := m.Snapshot()
+		Expect(snapshot).To(Equal(map[string]int{"a": 1, "b": 2, "c": 3}))
+		snapshot["d"] = 4
+		Expect(m.Contains("d")).To(BeFalse())
+
+		m.Remove("a", "d")
+		Expect(m.Keys()).To(ConsistOf("b", "c"))
+		m.Clear()
+		Expect(m.Size()).To(BeZero())
+	},
+	Entry("Map", func() container[string, int] { return hashmap.New[string, int]() }),
+	Entry("Map safe", func() container[string, int] { return hashmap.New[string, int](gods.WithSafe(true)) }),
+	Entry("ShardedMap", func() container[string, int] { return hashmap.NewSharded[string, int]() }),
+	Entry("ShardedMap single shard", func() container[string, int] {
+		return hashmap.NewShardedWith[string, int](1, nil)
+	}),
+)
+
+var _ = Describe("Map", func() {
+	It("NewFrom|Clone", func() {
+		data := map[int]int{1: 1, 2: 2}
+		m := hashmap.NewFrom(data)
+		data[3] = 3
+		Expect(m.Size()).To(Equal(2))
+		clone := m.Clone()
+		clone.Set(3, 3)
+		Expect(m.Size()).To(Equal(2))
+		Expect(clone.Size()).To(Equal(3))
+
+		Expect(hashmap.New[int, int](gods.WithCapacity(100)).Size()).To(BeZero())
+		Expect(func() { hashmap.New[int, int](gods.WithSnapshot(true)) }).
+			To(PanicWith("gods: hashmap.Map does not support WithSnapshot"))
+	})
+})
+
+var _ = Describe("ShardedMap", func() {
+	It("Custom hash", func() {
+		var calls int
+		m := hashmap.NewShardedWith[int, int](3, func(key int) uint64 { calls++; return uint64(key) })
+		m.Set(1, 1)
+		m.Set(5, 5)
+		Expect(m.Size()).To(Equal(2))
+		Expect(calls).To(Equal(2))
+		clone := m.Clone()
+		Expect(clone.Snapshot()).To(Equal(map[int]int{1: 1, 5: 5}))
+		clone.Set(2, 2)
+		Expect(m.Size()).To(Equal(2))
+		Expect(calls).To(Equal(3))
+	})
+
+	It("Default hash", func() {
+		type point struct{ X, Y int }
+		p1, p2 := &point{1, 2}, &point{1, 2}
+
+		m1 := hashmap.NewSharded[*point, int]()
+		m1.Set(p1, 1)
+		m1.Set(p2, 2)
+		p1.X = 100
+		v, found := m1.Get(p1)
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal(1))
+		Expect(m1.Size()).To(Equal(2))
+
+		m2 := hashmap.NewSharded[point, int]()
+		m2.Set(point{1, 2}, 1)
+		Expect(m2.Contains(point{1, 2})).To(BeTrue())
+
+		m3 := hashmap.NewSharded[float64, int]()
+		m3.Set(0.0, 1)
+		negativeZero := 0.0
+		negativeZero = -negativeZero
+		Expect(m3.Contains(negativeZero)).To(BeTrue())
+	})
+
+	It("Concurrent", func() {
+		m := hashmap.NewSharded[int, int]()
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					m.Set(g*1000+i, i)
+					m.Get(i)
+					if i%100 == 0 {
+						m.Size()
+						m.Snapshot()
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		Expect(m.Size()).To(Equal(8000))
+	})
+
+	It("Benchmark", Serial, func() {
+		const keys = 1 << 10
+		workers := runtime.GOMAXPROCS(0) * 2
+
+		// run spreads mixed reads and writes over the workers.
+		run := func(set func(k, v int), get func(k int)) {
+			var wg sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < keys; i++ {
+						k := (i*workers + w) % keys
+						if i%4 == 0 {
+							set(k, i)
+						} else {
+							get(k)
+						}
+					}
+				}(w)
+			}
+			wg.Wait()
+		}
+
+		single := hashmap.New[int, int](gods.WithSafe(true))
+		sharded := hashmap.NewSharded[int, int]()
+		var builtin sync.Map
+
+		experiment := gmeasure.NewExperiment("ConcurrentMap")
+		AddReportEntry(experiment.Name, experiment)
+		experiment.Sample(func(idx int) {
+			experiment.MeasureDuration("SingleLock", func() {
+				run(single.Set, func(k int) { single.Get(k) })
+			})
+			experiment.MeasureDuration("Sharded", func() {
+				run(sharded.Set, func(k int) { sharded.Get(k) })
+			})
+			experiment.MeasureDuration("SyncMap", func() {
+				run(func(k, v int) { builtin.Store(k, v) }, func(k int) { builtin.Load(k) })
+			})
+		}, gmeasure.SamplingConfig{N: 200, Duration: 2 * time.Second})
+
+		for _, name := range []string{"SingleLock", "Sharded", "SyncMap"} {
+			median := experiment.GetStats(name).DurationFor(gmeasure.StatMedian)
+			AddReportEntry(fmt.Sprintf("%s median", name), median)
+			Expect(median).To(BeNumerically(">", 0))
+		}
+	})
+})
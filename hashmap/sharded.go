@@ -0,0 +1,266 @@
+package hashmap
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sync"
+
+	"github.com/lazybabe/gods/internal/hash"
+)
+
+// DefaultShards is the number of shards of a sharded map in default.
+const DefaultShards = 32
+
+// ShardedMap is a concurrent-safe unordered collection of key-value pairs,
+// which spreads the items over shards selected by the hash of the keys,
+// so writers of different shards never contend for the same lock.
+//
+// The whole-map operations Size, Keys, Each, Clear and Snapshot lock all shards
+// in ascending order, so they observe or produce a consistent state of the map.
+type ShardedMap[K comparable, V any] struct {
+	shards []shard[K, V]
+	mask   uint64
+	hash   func(key K) uint64
+}
+
+// shard is a part of a sharded map, padded to reduce false sharing between neighbouring shards.
+type shard[K comparable, V any] struct {
+	mu   sync.RWMutex
+	data map[K]V
+	_    [32]byte
+}
+
+// NewSharded creates and returns an empty sharded map with DefaultShards shards,
+// which hashes keys with the default hash function.
+func NewSharded[K comparable, V any]() *ShardedMap[K, V] {
+	return NewShardedWith[K, V](DefaultShards, nil)
+}
+
+// NewShardedWith creates and returns an empty sharded map with at least `shards` shards,
+// rounded up to a power of 2, which hashes keys with `hash`.
+// A nil `hash` uses the default hash function.
+func NewShardedWith[K comparable, V any](shards int, hash func(key K) uint64) *ShardedMap[K, V] {
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	if hash == nil {
+		hash = newDefaultHash[K]()
+	}
+	m := &ShardedMap[K, V]{
+		shards: make([]shard[K, V], n),
+		mask:   uint64(n - 1),
+		hash:   hash,
+	}
+	for i := range m.shards {
+		m.shards[i].data = make(map[K]V)
+	}
+	return m
+}
+
+// Set sets `value` for `key`.
+func (m *ShardedMap[K, V]) Set(key K, value V) {
+	s := m.shardOf(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+}
+
+// Get returns the value of `key`.
+// If `key` is not in the map, the `found` is false.
+func (m *ShardedMap[K, V]) Get(key K) (value V, found bool) {
+	s := m.shardOf(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, found = s.data[key]
+	return
+}
+
+// Contains checks whether `key` is in the map.
+func (m *ShardedMap[K, V]) Contains(key K) bool {
+	_, found := m.Get(key)
+	return found
+}
+
+// Remove deletes one or multiple keys from the map.
+func (m *ShardedMap[K, V]) Remove(keys ...K) {
+	for _, key := range keys {
+		s := m.shardOf(key)
+		s.mu.Lock()
+		delete(s.data, key)
+		s.mu.Unlock()
+	}
+}
+
+// Size returns the number of items in the map.
+func (m *ShardedMap[K, V]) Size() int {
+	m.rlockAll()
+	defer m.runlockAll()
+	size := 0
+	for i := range m.shards {
+		size += len(m.shards[i].data)
+	}
+	return size
+}
+
+// Keys returns all keys of the map in no particular order.
+func (m *ShardedMap[K, V]) Keys() []K {
+	keys := make([]K, 0)
+	m.Each(func(key K, _ V) bool { keys = append(keys, key); return true })
+	return keys
+}
+
+// Each calls `fn` on every item of the map in no particular order.
+// If `fn` returns true, then it continues iterating; or false to stop.
+// All shards are read locked during the iteration, so `fn` must not write the map.
+func (m *ShardedMap[K, V]) Each(fn func(key K, value V) bool) {
+	m.rlockAll()
+	defer m.runlockAll()
+	for i := range m.shards {
+		for k, v := range m.shards[i].data {
+			if !fn(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Clear deletes all items of the map.
+func (m *ShardedMap[K, V]) Clear() {
+	m.lockAll()
+	defer m.unlockAll()
+	for i := range m.shards {
+		m.shards[i].data = make(map[K]V)
+	}
+}
+
+// Snapshot returns a copy of the items of the map as a builtin map.
+func (m *ShardedMap[K, V]) Snapshot() map[K]V {
+	m.rlockAll()
+	defer m.runlockAll()
+	size := 0
+	for i := range m.shards {
+		size += len(m.shards[i].data)
+	}
+	data := make(map[K]V, size)
+	for i := range m.shards {
+		for k, v := range m.shards[i].data {
+			data[k] = v
+		}
+	}
+	return data
+}
+
+// Clone returns a new sharded map with the same shards and hash function,
+// which is a copy of current map.
+func (m *ShardedMap[K, V]) Clone() *ShardedMap[K, V] {
+	clone := NewShardedWith[K, V](len(m.shards), m.hash)
+	m.rlockAll()
+	defer m.runlockAll()
+	for i := range m.shards {
+		for k, v := range m.shards[i].data {
+			clone.shards[i].data[k] = v
+		}
+	}
+	return clone
+}
+
+// String returns the items of the map as a string sorted by keys.
+func (m *ShardedMap[K, V]) String() string {
+	return toString(m.Each)
+}
+
+// shardOf returns the shard of `key`.
+func (m *ShardedMap[K, V]) shardOf(key K) *shard[K, V] {
+	return &m.shards[m.hash(key)&m.mask]
+}
+
+// lockAll locks all shards for writing in ascending order.
+func (m *ShardedMap[K, V]) lockAll() {
+	for i := range m.shards {
+		m.shards[i].mu.Lock()
+	}
+}
+
+// unlockAll unlocks all shards for writing in descending order.
+func (m *ShardedMap[K, V]) unlockAll() {
+	for i := len(m.shards) - 1; i >= 0; i-- {
+		m.shards[i].mu.Unlock()
+	}
+}
+
+// rlockAll locks all shards for reading in ascending order.
+func (m *ShardedMap[K, V]) rlockAll() {
+	for i := range m.shards {
+		m.shards[i].mu.RLock()
+	}
+}
+
+// runlockAll unlocks all shards for reading in descending order.
+func (m *ShardedMap[K, V]) runlockAll() {
+	for i := len(m.shards) - 1; i >= 0; i-- {
+		m.shards[i].mu.RUnlock()
+	}
+}
+
+// newDefaultHash returns a hash function of the keys, which is fast for the builtin
+// numeric and string keys, and formats the other keys before hashing them.
+// Composite keys holding floats or interfaces of them should use a custom hash function,
+// since 0 and -0 are equal but formatted differently.
+func newDefaultHash[K comparable]() func(key K) uint64 {
+	seed := maphash.MakeSeed()
+	return func(key K) uint64 {
+		switch k := any(key).(type) {
+		case int:
+			return hash.Mix(uint64(k))
+		case int8:
+			return hash.Mix(uint64(k))
+		case int16:
+			return hash.Mix(uint64(k))
+		case int32:
+			return hash.Mix(uint64(k))
+		case int64:
+			return hash.Mix(uint64(k))
+		case uint:
+			return hash.Mix(uint64(k))
+		case uint8:
+			return hash.Mix(uint64(k))
+		case uint16:
+			return hash.Mix(uint64(k))
+		case uint32:
+			return hash.Mix(uint64(k))
+		case uint64:
+			return hash.Mix(k)
+		case uintptr:
+			return hash.Mix(uint64(k))
+		case float32:
+			return hashFloat(float64(k))
+		case float64:
+			return hashFloat(k)
+		case string:
+			var h maphash.Hash
+			h.SetSeed(seed)
+			_, _ = h.WriteString(k)
+			return h.Sum64()
+		}
+		// Pointer-like keys are equal by address, the pointed values may change.
+		switch v := reflect.ValueOf(key); v.Kind() {
+		case reflect.Chan, reflect.Pointer, reflect.UnsafePointer:
+			return hash.Mix(uint64(v.Pointer()))
+		}
+		var h maphash.Hash
+		h.SetSeed(seed)
+		_, _ = fmt.Fprintf(&h, "%#v", key)
+		return h.Sum64()
+	}
+}
+
+// hashFloat returns the hash of `f`, where 0 and -0 are equal.
+func hashFloat(f float64) uint64 {
+	if f == 0 {
+		return hash.Mix(0)
+	}
+	return hash.Mix(math.Float64bits(f))
+}
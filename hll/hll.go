@@ -0,0 +1,354 @@
+// Package hll implements HyperLogLog sketches, which estimate the number of distinct keys added
+// in a few kilobytes of memory, however many keys there are, with a standard error of 1.04/sqrt(2^precision).
+//
+// A sketch starts in a sparse representation, which counts small cardinalities almost exactly
+// in less memory, and turns into the dense one as it grows.
+package hll
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/bloom"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+const (
+	// MinPrecision is the minimum precision of a sketch.
+	MinPrecision = 4
+	// MaxPrecision is the maximum precision of a sketch.
+	MaxPrecision = 18
+	// DefaultPrecision is the precision of a sketch created with a precision not positive,
+	// which gives a standard error of about 0.81% in 16 KB.
+	DefaultPrecision = 14
+	// sparsePrecision is the precision of the indexes in the sparse representation.
+	sparsePrecision = 25
+)
+
+var (
+	// ErrIncompatible is returned when merging sketches of different precisions.
+	ErrIncompatible = errors.New("incompatible sketches")
+	// ErrInvalidData is returned when decoding invalid binary data into a sketch.
+	ErrInvalidData = errors.New("invalid binary data of sketch")
+)
+
+// Representations of sketches in the binary encoding.
+const (
+	kindSparse byte = 'S'
+	kindDense  byte = 'D'
+)
+
+// Sketch is a HyperLogLog sketch of keys of type T.
+type Sketch[T any] struct {
+	mu        rwmutex.RWMutex
+	precision uint8
+	hasher    bloom.Hasher[T]
+	// Entries of the sparse representation in ascending order, see sparseEntry,
+	// or nil in the dense representation.
+	sparse []uint32
+	// Registers of the dense representation, or nil in the sparse representation.
+	registers []uint8
+}
+
+// New creates and returns an empty sketch of 2^`precision` registers, where `precision` is clamped
+// between MinPrecision and MaxPrecision, or DefaultPrecision if it is not positive.
+// The keys are hashed by `hasher`, or by bloom.Default[T] if it is nil.
+// The parameter `options` is used to configure the sketch, see gods.Option.
+// It is not concurrent-safe in default.
+func New[T any](precision int, hasher bloom.Hasher[T], options ...gods.Option) *Sketch[T] {
+	switch {
+	case precision <= 0:
+		precision = DefaultPrecision
+	case precision < MinPrecision:
+		precision = MinPrecision
+	case precision > MaxPrecision:
+		precision = MaxPrecision
+	}
+	if hasher == nil {
+		hasher = bloom.Default[T]()
+	}
+	return &Sketch[T]{
+		mu:        rwmutex.CreateWithoutCOW("hll.Sketch", options),
+		precision: uint8(precision),
+		hasher:    hasher,
+		sparse:    make([]uint32, 0),
+	}
+}
+
+// Precision returns the precision of the sketch, which has 2^precision registers.
+func (s *Sketch[T]) Precision() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int(s.precision)
+}
+
+// StandardError returns the standard error of the estimates of the sketch relative to the cardinality,
+// which is about 1.04/sqrt(2^precision).
+func (s *Sketch[T]) StandardError() float64 {
+	return 1.04 / math.Sqrt(float64(uint64(1)<<s.Precision()))
+}
+
+// IsSparse reports whether the sketch is in the sparse representation.
+func (s *Sketch[T]) IsSparse() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.registers == nil
+}
+
+// Add adds one or multiple keys to the sketch.
+func (s *Sketch[T]) Add(keys ...T) {
+	s.mu.Count("Add")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		h := s.hasher(key)
+		if s.registers == nil {
+			s.addSparse(h>>(64-sparsePrecision), rank(h, sparsePrecision))
+			continue
+		}
+		s.addDense(h>>(64-s.precision), rank(h, s.precision))
+	}
+}
+
+// Count returns the estimated number of distinct keys added to the sketch.
+func (s *Sketch[T]) Count() uint64 {
+	s.mu.Count("Count")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.registers == nil {
+		// Linear counting over the registers of the sparse precision, which are hardly ever shared.
+		m := float64(uint64(1) << sparsePrecision)
+		return uint64(math.Round(m * math.Log(m/(m-float64(len(s.sparse))))))
+	}
+	m := float64(len(s.registers))
+	var sum float64
+	var zeros int
+	for _, r := range s.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha(len(s.registers)) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
+
+// Merge merges the keys added to all of `others` into the sketch, which then estimates
+// the cardinality of their union. They must not be nil and have the same precision as the sketch,
+// or else it returns ErrIncompatible, and the sketch is not changed.
+// The hashers of the sketches must be the same.
+func (s *Sketch[T]) Merge(others ...*Sketch[T]) error {
+	s.mu.Count("Merge")
+	copies := make([]*Sketch[T], 0, len(others))
+	for _, other := range others {
+		if other == nil {
+			return ErrIncompatible
+		}
+		copies = append(copies, other.copy())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, other := range copies {
+		if other.precision != s.precision {
+			return ErrIncompatible
+		}
+	}
+	for _, other := range copies {
+		if other.registers == nil {
+			for _, entry := range other.sparse {
+				if s.registers == nil {
+					s.addSparse(uint64(entry>>6), uint8(entry&63))
+				} else {
+					s.addDense(s.denseOf(entry))
+				}
+			}
+			continue
+		}
+		if s.registers == nil {
+			s.toDense()
+		}
+		for i, r := range other.registers {
+			if r > s.registers[i] {
+				s.registers[i] = r
+			}
+		}
+	}
+	return nil
+}
+
+// Clear removes all keys of the sketch, which turns into the sparse representation.
+func (s *Sketch[T]) Clear() {
+	s.mu.Count("Clear")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sparse, s.registers = make([]uint32, 0), nil
+}
+
+// Clone returns a new sketch, which is a copy of current sketch.
+func (s *Sketch[T]) Clone() *Sketch[T] {
+	c := s.copy()
+	c.mu = rwmutex.CreateWith(gods.NewOptions(s.mu.Options()...))
+	return c
+}
+
+// Stats returns a snapshot of the metrics of the sketch created with gods.WithMetrics(true).
+func (s *Sketch[T]) Stats() gods.Stats {
+	return s.mu.Stats()
+}
+
+// MarshalBinary implements the interface encoding.BinaryMarshaler,
+// which encodes the precision and the registers of the sketch in its representation, but not its hasher.
+func (s *Sketch[T]) MarshalBinary() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.registers == nil {
+		b := make([]byte, 0, 6+len(s.sparse)*4)
+		b = append(b, kindSparse, s.precision)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(s.sparse)))
+		for _, entry := range s.sparse {
+			b = binary.BigEndian.AppendUint32(b, entry)
+		}
+		return b, nil
+	}
+	return append([]byte{kindDense, s.precision}, s.registers...), nil
+}
+
+// UnmarshalBinary implements the interface encoding.BinaryUnmarshaler,
+// which replaces the precision and the registers of the sketch with the decoded ones.
+// The sketch keeps its hasher, which must be the one of the encoded sketch, or bloom.Default[T] if it has none.
+func (s *Sketch[T]) UnmarshalBinary(b []byte) error {
+	if len(b) < 2 || b[1] < MinPrecision || b[1] > MaxPrecision {
+		return ErrInvalidData
+	}
+	precision := b[1]
+	var sparse []uint32
+	var registers []uint8
+	switch b[0] {
+	case kindSparse:
+		if len(b) < 6 || uint64(len(b)-6) != uint64(binary.BigEndian.Uint32(b[2:]))*4 {
+			return ErrInvalidData
+		}
+		sparse = make([]uint32, 0, (len(b)-6)/4)
+		for i := 6; i < len(b); i += 4 {
+			entry := binary.BigEndian.Uint32(b[i:])
+			if entry>>6 >= 1<<sparsePrecision || entry&63 == 0 || entry&63 > 64-sparsePrecision+1 ||
+				len(sparse) > 0 && entry>>6 <= sparse[len(sparse)-1]>>6 {
+				return ErrInvalidData
+			}
+			sparse = append(sparse, entry)
+		}
+	case kindDense:
+		if len(b)-2 != 1<<precision {
+			return ErrInvalidData
+		}
+		for _, r := range b[2:] {
+			if r > 64-precision+1 {
+				return ErrInvalidData
+			}
+		}
+		registers = append([]uint8(nil), b[2:]...)
+	default:
+		return ErrInvalidData
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.precision, s.sparse, s.registers = precision, sparse, registers
+	if s.hasher == nil {
+		s.hasher = bloom.Default[T]()
+	}
+	return nil
+}
+
+// copy returns a copy of the sketch with a zero lock.
+func (s *Sketch[T]) copy() *Sketch[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := &Sketch[T]{precision: s.precision, hasher: s.hasher}
+	if s.registers == nil {
+		c.sparse = append(make([]uint32, 0, len(s.sparse)), s.sparse...)
+	} else {
+		c.registers = append([]uint8(nil), s.registers...)
+	}
+	return c
+}
+
+// addSparse sets the register `index` of the sparse precision to `r` if it is lower,
+// and turns the sketch into the dense representation once the sparse one is no smaller.
+//
+// An entry of the sparse representation holds the index of a register in its upper bits
+// and the register in the lower 6 bits, so the entries are sorted by their indexes.
+func (s *Sketch[T]) addSparse(index uint64, r uint8) {
+	entry := uint32(index)<<6 | uint32(r)
+	i := sort.Search(len(s.sparse), func(i int) bool { return s.sparse[i]>>6 >= uint32(index) })
+	switch {
+	case i < len(s.sparse) && s.sparse[i]>>6 == uint32(index):
+		if s.sparse[i]&63 < uint32(r) {
+			s.sparse[i] = entry
+		}
+		return
+	case i == len(s.sparse):
+		s.sparse = append(s.sparse, entry)
+	default:
+		s.sparse = append(s.sparse[:i+1], s.sparse[i:]...)
+		s.sparse[i] = entry
+	}
+	// Every entry takes 4 bytes, and every dense register 1 byte.
+	if len(s.sparse)*4 > 1<<s.precision {
+		s.toDense()
+	}
+}
+
+// addDense sets the register `index` to `r` if it is lower.
+func (s *Sketch[T]) addDense(index uint64, r uint8) {
+	if s.registers[index] < r {
+		s.registers[index] = r
+	}
+}
+
+// toDense turns the sketch into the dense representation.
+func (s *Sketch[T]) toDense() {
+	s.registers = make([]uint8, 1<<s.precision)
+	for _, entry := range s.sparse {
+		s.addDense(s.denseOf(entry))
+	}
+	s.sparse = nil
+}
+
+// denseOf returns the index and the register of the dense precision of the sparse entry `entry`.
+// The index of the dense precision is the upper bits of the index of the sparse precision,
+// and the lower bits are the upper bits of the hash the register counts the leading zeros of.
+func (s *Sketch[T]) denseOf(entry uint32) (uint64, uint8) {
+	shift := sparsePrecision - s.precision
+	index, r := uint64(entry>>6), uint8(entry&63)
+	if rest := index & (1<<shift - 1); rest != 0 {
+		return index >> shift, uint8(bits.LeadingZeros64(rest<<(64-shift))) + 1
+	}
+	return index >> shift, shift + r
+}
+
+// rank returns the register of the hash `h` in a sketch of the precision `precision`,
+// which is the position of the first 1 bit after the bits of the index, at most 64-precision+1.
+func rank(h uint64, precision uint8) uint8 {
+	return uint8(bits.LeadingZeros64(h<<precision|1<<(precision-1))) + 1
+}
+
+// alpha returns the bias correction constant of `m` registers.
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(m))
+	}
+}
@@ -0,0 +1,175 @@
+package hll_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/bloom"
+	"github.com/lazybabe/gods/hll"
+	"github.com/lazybabe/gods/set"
+)
+
+func TestHLL(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HLL Suite")
+}
+
+// seq returns the set of the integers in [from, to).
+func seq(from, to int) *set.Set[int] {
+	s := set.New[int]()
+	for i := from; i < to; i++ {
+		s.Add(i)
+	}
+	return s
+}
+
+var _ = Describe("Sketch", func() {
+	It("Precision", func() {
+		Expect(hll.New[int](0, nil).Precision()).To(Equal(hll.DefaultPrecision))
+		Expect(hll.New[int](1, nil).Precision()).To(Equal(hll.MinPrecision))
+		Expect(hll.New[int](30, nil).Precision()).To(Equal(hll.MaxPrecision))
+		Expect(hll.New[int](10, nil).StandardError()).To(BeNumerically("~", 1.04/32, 1e-12))
+	})
+
+	DescribeTable("Count",
+		func(precision int, n int) {
+			s := seq(0, n)
+			sketch := hll.FromSet(s, precision, nil)
+			Expect(hll.RelativeError(sketch, s)).To(BeNumerically("<=", 3*sketch.StandardError()),
+				"count %d of %d keys", sketch.Count(), n)
+			// Adding the keys again does not change the estimate.
+			count := sketch.Count()
+			s.Each(func(i int) bool {
+				sketch.Add(i)
+				return true
+			})
+			Expect(sketch.Count()).To(Equal(count))
+		},
+		Entry("empty", 14, 0),
+		Entry("sparse", 14, 1000),
+		Entry("sparse with low precision", 8, 60),
+		Entry("linear counting", 10, 2000),
+		Entry("dense", 10, 100000),
+		Entry("default precision", 0, 200000),
+		Entry("max precision", 18, 300000),
+	)
+
+	It("Sparse", func() {
+		sketch := hll.New[int](14, nil)
+		Expect(sketch.IsSparse()).To(BeTrue())
+		Expect(sketch.Count()).To(BeZero())
+		for i := 0; i < 1000; i++ {
+			sketch.Add(i)
+		}
+		Expect(sketch.IsSparse()).To(BeTrue())
+		Expect(sketch.Count()).To(BeNumerically("~", 1000, 1))
+		for i := 1000; i < 5000; i++ {
+			sketch.Add(i)
+		}
+		Expect(sketch.IsSparse()).To(BeFalse())
+		Expect(hll.RelativeError(sketch, seq(0, 5000))).To(BeNumerically("<", 0.03))
+
+		sketch.Clear()
+		Expect(sketch.IsSparse()).To(BeTrue())
+		Expect(sketch.Count()).To(BeZero())
+	})
+
+	It("Merge", func() {
+		a, b := seq(0, 30000), seq(20000, 50000)
+		sa, sb := hll.FromSet(a, 12, nil), hll.FromSet(b, 12, nil)
+		Expect(sa.Merge(sb)).To(Succeed())
+		union := a.Union(b)
+		Expect(hll.RelativeError(sa, union)).To(BeNumerically("<=", 3*sa.StandardError()))
+
+		// The intersection is estimated by inclusion-exclusion.
+		intersection := float64(hll.FromSet(a, 12, nil).Count()+sb.Count()) - float64(sa.Count())
+		Expect(intersection).To(BeNumerically("~", a.Intersect(b).Size(), 2000))
+
+		Expect(sa.Merge(hll.New[int](13, nil))).To(MatchError(hll.ErrIncompatible))
+		Expect(sa.Merge(nil)).To(MatchError(hll.ErrIncompatible))
+		Expect(sa.Merge(sa)).To(Succeed())
+		Expect(hll.RelativeError(sa, union)).To(BeNumerically("<=", 3*sa.StandardError()))
+	})
+
+	It("Merge sparse and dense", func() {
+		for _, sizes := range [][2]int{{100, 200}, {100, 20000}, {20000, 100}, {900, 900}} {
+			a, b := seq(0, sizes[0]), seq(sizes[0]/2, sizes[0]/2+sizes[1])
+			sa, sb := hll.FromSet(a, 12, nil), hll.FromSet(b, 12, nil)
+			Expect(sa.Merge(sb)).To(Succeed())
+			expected := hll.FromSet(a.Union(b), 12, nil)
+			Expect(sa.Count()).To(BeNumerically("~", expected.Count(), float64(expected.Count())*0.01+1),
+				"sizes %v", sizes)
+		}
+	})
+
+	It("Binary", func() {
+		for _, n := range []int{0, 100, 20000} {
+			sketch := hll.FromSet(seq(0, n), 12, nil)
+			b, err := sketch.MarshalBinary()
+			Expect(err).NotTo(HaveOccurred())
+			decoded := new(hll.Sketch[int])
+			Expect(decoded.UnmarshalBinary(b)).To(Succeed())
+			Expect(decoded.Precision()).To(Equal(12))
+			Expect(decoded.IsSparse()).To(Equal(sketch.IsSparse()))
+			Expect(decoded.Count()).To(Equal(sketch.Count()))
+			decoded.Add(n)
+			Expect(decoded.Count()).To(BeNumerically(">=", sketch.Count()))
+		}
+		Expect(hll.FromSet(seq(0, 20000), 12, nil).IsSparse()).To(BeFalse())
+
+		decoded := hll.New[int](12, nil)
+		Expect(decoded.UnmarshalBinary(nil)).To(MatchError(hll.ErrInvalidData))
+		Expect(decoded.UnmarshalBinary([]byte{'D', 3})).To(MatchError(hll.ErrInvalidData))
+		Expect(decoded.UnmarshalBinary([]byte{'D', 4, 0})).To(MatchError(hll.ErrInvalidData))
+		Expect(decoded.UnmarshalBinary([]byte{'S', 4, 0, 0, 0, 1})).To(MatchError(hll.ErrInvalidData))
+		Expect(decoded.UnmarshalBinary([]byte{'S', 4, 0, 0, 0, 1, 0, 0, 0, 0})).To(MatchError(hll.ErrInvalidData))
+		Expect(decoded.UnmarshalBinary([]byte{'S', 4, 0, 0, 0, 1, 0, 0, 0, 1})).To(Succeed())
+		Expect(decoded.Count()).To(Equal(uint64(1)))
+	})
+
+	It("Hasher and Clone", func() {
+		sketch := hll.New(10, bloom.String, gods.WithMetrics(true))
+		for i := 0; i < 500; i++ {
+			sketch.Add(fmt.Sprint(i))
+		}
+		c := sketch.Clone()
+		c.Add("a", "b", "c")
+		Expect(c.Count()).To(BeNumerically(">", sketch.Count()))
+		Expect(c.Stats().Operations).To(HaveKeyWithValue("Add", uint64(1)))
+		Expect(sketch.Stats().Operations).To(HaveKeyWithValue("Add", uint64(500)))
+	})
+
+	It("Safe", func() {
+		Expect(func() { hll.New[int](12, nil, gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: hll.Sketch does not support LockCopyOnWrite"))
+		sketch := hll.New[int](12, nil, gods.WithSafe(true), gods.WithLocker(gods.LockSpin))
+		other := hll.New[int](12, nil, gods.WithSafe(true))
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(2)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 5000; i++ {
+					sketch.Add(w*5000 + i)
+					other.Add(w*5000 + i)
+				}
+			}(w)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 10; i++ {
+					sketch.Count()
+					Expect(sketch.Merge(other)).To(Succeed())
+					Expect(other.Merge(sketch)).To(Succeed())
+				}
+			}()
+		}
+		wg.Wait()
+		Expect(hll.RelativeError(sketch, seq(0, 20000))).To(BeNumerically("<=", 3*sketch.StandardError()))
+	})
+})
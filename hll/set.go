@@ -0,0 +1,37 @@
+package hll
+
+import (
+	"math"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/bloom"
+	"github.com/lazybabe/gods/set"
+)
+
+// FromSet creates and returns a sketch of 2^`precision` registers which the items of `s` are added to,
+// see New for the parameters.
+func FromSet[T comparable](s *set.Set[T], precision int, hasher bloom.Hasher[T], options ...gods.Option) *Sketch[T] {
+	sketch := New(precision, hasher, options...)
+	s.Each(func(item T) bool {
+		sketch.Add(item)
+		return true
+	})
+	return sketch
+}
+
+// RelativeError returns the error of the estimate of `sketch` relative to the size of `s`,
+// which is the exact cardinality of the keys added to `sketch`, such as in tests comparing
+// the estimates with sets. It is 0 if both are empty, and infinity if only `s` is empty.
+//
+// The estimates are within one StandardError of the cardinality about 65% of the time,
+// and within three about 99% of the time.
+func RelativeError[T comparable](sketch *Sketch[T], s *set.Set[T]) float64 {
+	count, size := float64(sketch.Count()), float64(s.Size())
+	if size == 0 {
+		if count == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return math.Abs(count-size) / size
+}
@@ -0,0 +1,13 @@
+// Package hash implements the hashing helpers shared by the hash-based containers.
+package hash
+
+// Mix scrambles the bits of `x` with the finalizer of SplitMix64,
+// so every bit of the result depends on every bit of `x`, and consecutive integers spread evenly.
+func Mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,29 @@
+package hash_test
+
+import (
+	"math/bits"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods/internal/hash"
+)
+
+func TestHash(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hash Suite")
+}
+
+var _ = Describe("Hash", func() {
+	It("Mix", func() {
+		Expect(hash.Mix(0)).To(BeZero())
+		Expect(hash.Mix(1)).To(Equal(hash.Mix(1)))
+		var changed int
+		for i := uint64(1); i <= 1000; i++ {
+			changed += bits.OnesCount64(hash.Mix(i) ^ hash.Mix(i-1))
+		}
+		// Consecutive integers differ in about half of the bits of their hashes.
+		Expect(changed / 1000).To(BeNumerically("~", 32, 4))
+	})
+})
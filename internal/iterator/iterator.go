@@ -0,0 +1,44 @@
+// Package iterator implements the iterators of the containers.
+package iterator
+
+// Slice is an iterator over a slice, which implements both gods.Iterator and gods.ReverseIterator.
+type Slice[T any] struct {
+	items []T
+	index int
+}
+
+// New creates and returns an iterator over `items` starting before the first item.
+// Note that `items` must not be modified while iterating.
+func New[T any](items []T) *Slice[T] {
+	return &Slice[T]{items: items, index: -1}
+}
+
+// NewReverse creates and returns an iterator over `items` starting after the last item.
+// Note that `items` must not be modified while iterating.
+func NewReverse[T any](items []T) *Slice[T] {
+	return &Slice[T]{items: items, index: len(items)}
+}
+
+// Next moves to the next item, and reports whether there is one.
+func (it *Slice[T]) Next() bool {
+	if it.index < len(it.items) {
+		it.index++
+	}
+	return it.index < len(it.items)
+}
+
+// Prev moves to the previous item, and reports whether there is one.
+func (it *Slice[T]) Prev() bool {
+	if it.index >= 0 {
+		it.index--
+	}
+	return it.index >= 0
+}
+
+// Value returns the current item, which is the zero value if there is none.
+func (it *Slice[T]) Value() (value T) {
+	if it.index < 0 || it.index >= len(it.items) {
+		return
+	}
+	return it.items[it.index]
+}
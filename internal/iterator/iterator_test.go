@@ -0,0 +1,45 @@
+package iterator_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods/internal/iterator"
+)
+
+func TestIterator(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Iterator Suite")
+}
+
+var _ = Describe("Iterator", func() {
+	It("Slice", func() {
+		it := iterator.New([]int{1, 2})
+		Expect(it.Value()).To(BeZero())
+		Expect(it.Next()).To(BeTrue())
+		Expect(it.Value()).To(Equal(1))
+		Expect(it.Next()).To(BeTrue())
+		Expect(it.Value()).To(Equal(2))
+		Expect(it.Next()).To(BeFalse())
+		Expect(it.Next()).To(BeFalse())
+		Expect(it.Value()).To(BeZero())
+		Expect(it.Prev()).To(BeTrue())
+		Expect(it.Value()).To(Equal(2))
+
+		it = iterator.NewReverse([]int{1, 2})
+		Expect(it.Prev()).To(BeTrue())
+		Expect(it.Value()).To(Equal(2))
+		Expect(it.Prev()).To(BeTrue())
+		Expect(it.Value()).To(Equal(1))
+		Expect(it.Prev()).To(BeFalse())
+		Expect(it.Prev()).To(BeFalse())
+		Expect(it.Next()).To(BeTrue())
+		Expect(it.Value()).To(Equal(1))
+
+		empty := iterator.New[int](nil)
+		Expect(empty.Next()).To(BeFalse())
+		Expect(empty.Prev()).To(BeFalse())
+	})
+})
@@ -0,0 +1,183 @@
+package rwmutex
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// checkedLocker is a locker of debug usage, which tracks the goroutines holding the lock,
+// panics on the locking which would deadlock, and reports the locks held too long.
+type checkedLocker struct {
+	locker locker
+	// Whether readers do not lock, like in copy-on-write usage.
+	lockFreeReads bool
+	// Duration after which a held lock is reported, no report if not positive.
+	holdLimit time.Duration
+	// Guards the fields below.
+	mu sync.Mutex
+	// Goroutine holding the lock for writing, 0 if none.
+	writer int64
+	// Timer reporting the lock held too long for writing.
+	writerTimer *time.Timer
+	// Timers reporting the lock held too long for reading, by the goroutines holding it,
+	// where the timers are nil if there is no hold limit.
+	readers map[int64]*time.Timer
+}
+
+// newCheckedLocker creates and returns a checked locker wrapping `l`.
+func newCheckedLocker(l locker, lockFreeReads bool, holdLimit time.Duration) *checkedLocker {
+	return &checkedLocker{
+		locker:        l,
+		lockFreeReads: lockFreeReads,
+		holdLimit:     holdLimit,
+		readers:       make(map[int64]*time.Timer),
+	}
+}
+
+// Lock locks for writing, it panics if the calling goroutine already holds the lock.
+func (l *checkedLocker) Lock() {
+	id := l.check("writing")
+	l.locker.Lock()
+	l.locked(id)
+}
+
+// TryLock tries to lock for writing, it fails if the calling goroutine already holds the lock.
+func (l *checkedLocker) TryLock() bool {
+	id, held := l.holder()
+	if held || !l.locker.TryLock() {
+		return false
+	}
+	l.locked(id)
+	return true
+}
+
+// Unlock unlocks for writing.
+func (l *checkedLocker) Unlock() {
+	l.mu.Lock()
+	l.writer = 0
+	if l.writerTimer != nil {
+		l.writerTimer.Stop()
+		l.writerTimer = nil
+	}
+	l.mu.Unlock()
+	l.locker.Unlock()
+}
+
+// RLock locks for reading, it panics if the calling goroutine already holds the lock,
+// as a re-entrant read locking deadlocks once a writer is waiting.
+func (l *checkedLocker) RLock() {
+	if l.lockFreeReads {
+		return
+	}
+	id := l.check("reading")
+	l.locker.RLock()
+	l.rlocked(id)
+}
+
+// TryRLock tries to lock for reading, it fails if the calling goroutine already holds the lock.
+func (l *checkedLocker) TryRLock() bool {
+	if l.lockFreeReads {
+		return true
+	}
+	id, held := l.holder()
+	if held || !l.locker.TryRLock() {
+		return false
+	}
+	l.rlocked(id)
+	return true
+}
+
+// RUnlock unlocks for reading.
+func (l *checkedLocker) RUnlock() {
+	if l.lockFreeReads {
+		return
+	}
+	id := goid()
+	l.mu.Lock()
+	if timer := l.readers[id]; timer != nil {
+		timer.Stop()
+	}
+	delete(l.readers, id)
+	l.mu.Unlock()
+	l.locker.RUnlock()
+}
+
+// check returns the id of the calling goroutine about to lock for `usage`,
+// it panics if the goroutine already holds the lock, as the locking would deadlock.
+func (l *checkedLocker) check(usage string) int64 {
+	id := goid()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case l.writer == id:
+		panic(fmt.Sprintf("rwmutex: goroutine %d locks for %s while holding the lock for writing, "+
+			"which deadlocks; do not call a container inside its own hooks or callbacks", id, usage))
+	case l.holdsRead(id) && usage == "writing":
+		panic(fmt.Sprintf("rwmutex: goroutine %d locks for writing while holding the lock for reading, "+
+			"which deadlocks; do not modify a container inside its own callbacks", id))
+	case l.holdsRead(id):
+		panic(fmt.Sprintf("rwmutex: goroutine %d locks for reading while holding the lock for reading, "+
+			"which deadlocks once a writer is waiting; do not call a container inside its own callbacks", id))
+	}
+	return id
+}
+
+// holder returns the id of the calling goroutine, and whether it holds the lock.
+func (l *checkedLocker) holder() (id int64, held bool) {
+	id = goid()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return id, l.writer == id || l.holdsRead(id)
+}
+
+// locked records the lock acquired for writing by goroutine `id`.
+func (l *checkedLocker) locked(id int64) {
+	l.mu.Lock()
+	l.writer = id
+	l.writerTimer = l.report("writing", id)
+	l.mu.Unlock()
+}
+
+// rlocked records the lock acquired for reading by goroutine `id`.
+func (l *checkedLocker) rlocked(id int64) {
+	l.mu.Lock()
+	l.readers[id] = l.report("reading", id)
+	l.mu.Unlock()
+}
+
+// holdsRead checks and returns whether goroutine `id` holds the lock for reading.
+func (l *checkedLocker) holdsRead(id int64) bool {
+	_, ok := l.readers[id]
+	return ok
+}
+
+// report returns a timer logging the lock held for `usage` by goroutine `id` once the hold limit
+// is exceeded, along with the stack acquiring the lock. It returns nil if there is no hold limit.
+func (l *checkedLocker) report(usage string, id int64) *time.Timer {
+	if l.holdLimit <= 0 {
+		return nil
+	}
+	buf := make([]byte, 4096)
+	stack := buf[:runtime.Stack(buf, false)]
+	limit := l.holdLimit
+	return time.AfterFunc(limit, func() {
+		log.Printf("rwmutex: goroutine %d has held the lock for %s longer than %s, acquired at:\n%s",
+			id, usage, limit, stack)
+	})
+}
+
+// goid returns the id of the calling goroutine.
+func goid() int64 {
+	var buf [64]byte
+	s := strings.TrimPrefix(string(buf[:runtime.Stack(buf[:], false)]), "goroutine ")
+	if i := strings.IndexByte(s, ' '); i > 0 {
+		s = s[:i]
+	}
+	id, _ := strconv.ParseInt(s, 10, 64)
+	return id
+}
@@ -0,0 +1,6 @@
+//go:build godsdebug
+
+package rwmutex
+
+// debug specifies whether all safe rwmutexes use checked locks, see gods.WithDebug.
+const debug = true
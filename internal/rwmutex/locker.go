@@ -0,0 +1,81 @@
+package rwmutex
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// mutexLocker is a locker serializing readers and writers with a sync.Mutex.
+type mutexLocker struct {
+	sync.Mutex
+}
+
+// RLock locks the mutex.
+func (l *mutexLocker) RLock() {
+	l.Lock()
+}
+
+// RUnlock unlocks the mutex.
+func (l *mutexLocker) RUnlock() {
+	l.Unlock()
+}
+
+// TryRLock tries to lock the mutex.
+func (l *mutexLocker) TryRLock() bool {
+	return l.TryLock()
+}
+
+// spinLocker is a locker serializing readers and writers by spinning on an atomic flag.
+type spinLocker struct {
+	locked atomic.Bool
+}
+
+// Lock spins until the lock is acquired, yielding the processor between attempts.
+func (l *spinLocker) Lock() {
+	for !l.locked.CompareAndSwap(false, true) {
+		runtime.Gosched()
+	}
+}
+
+// TryLock tries to acquire the lock once.
+func (l *spinLocker) TryLock() bool {
+	return l.locked.CompareAndSwap(false, true)
+}
+
+// Unlock releases the lock.
+func (l *spinLocker) Unlock() {
+	l.locked.Store(false)
+}
+
+// RLock locks like Lock.
+func (l *spinLocker) RLock() {
+	l.Lock()
+}
+
+// RUnlock unlocks like Unlock.
+func (l *spinLocker) RUnlock() {
+	l.Unlock()
+}
+
+// TryRLock tries to lock like TryLock.
+func (l *spinLocker) TryRLock() bool {
+	return l.TryLock()
+}
+
+// cowLocker is a locker of copy-on-write usage, which serializes writers with a sync.Mutex,
+// and lets readers through without locking.
+type cowLocker struct {
+	sync.Mutex
+}
+
+// RLock does nothing, readers read the data published by the last writer.
+func (l *cowLocker) RLock() {}
+
+// RUnlock does nothing.
+func (l *cowLocker) RUnlock() {}
+
+// TryRLock always succeeds, readers never block.
+func (l *cowLocker) TryRLock() bool {
+	return true
+}
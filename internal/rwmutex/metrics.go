@@ -0,0 +1,90 @@
+package rwmutex
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lazybabe/gods"
+)
+
+// metrics records the lock and operation metrics of a RWMutex.
+type metrics struct {
+	readLocks  atomic.Uint64
+	writeLocks atomic.Uint64
+	readWait   atomic.Int64
+	writeWait  atomic.Int64
+	readHold   atomic.Int64
+	writeHold  atomic.Int64
+	// Time the lock was acquired for writing, guarded by the lock itself.
+	writeLockedAt time.Time
+	// Readers holding the lock and the time the first of them acquired it.
+	readers      sync.Mutex
+	readCount    int
+	readLockedAt time.Time
+	// Counters of operations, which maps names to *atomic.Uint64.
+	operations sync.Map
+}
+
+// locked records the lock acquired for writing, which was requested at `start`.
+func (m *metrics) locked(start time.Time) {
+	now := time.Now()
+	m.writeLocks.Add(1)
+	m.writeWait.Add(int64(now.Sub(start)))
+	m.writeLockedAt = now
+}
+
+// unlocking records the lock to be released for writing.
+func (m *metrics) unlocking() {
+	m.writeHold.Add(int64(time.Since(m.writeLockedAt)))
+}
+
+// rlocked records the lock acquired for reading, which was requested at `start`.
+func (m *metrics) rlocked(start time.Time) {
+	now := time.Now()
+	m.readLocks.Add(1)
+	m.readWait.Add(int64(now.Sub(start)))
+	m.readers.Lock()
+	if m.readCount == 0 {
+		m.readLockedAt = now
+	}
+	m.readCount++
+	m.readers.Unlock()
+}
+
+// runlocking records the lock to be released for reading.
+func (m *metrics) runlocking() {
+	m.readers.Lock()
+	m.readCount--
+	if m.readCount == 0 {
+		m.readHold.Add(int64(time.Since(m.readLockedAt)))
+	}
+	m.readers.Unlock()
+}
+
+// count increases the counter of operation `op`.
+func (m *metrics) count(op string) {
+	counter, ok := m.operations.Load(op)
+	if !ok {
+		counter, _ = m.operations.LoadOrStore(op, new(atomic.Uint64))
+	}
+	counter.(*atomic.Uint64).Add(1)
+}
+
+// stats returns a snapshot of the metrics.
+func (m *metrics) stats() gods.Stats {
+	stats := gods.Stats{
+		ReadLocks:  m.readLocks.Load(),
+		WriteLocks: m.writeLocks.Load(),
+		ReadWait:   time.Duration(m.readWait.Load()),
+		WriteWait:  time.Duration(m.writeWait.Load()),
+		ReadHold:   time.Duration(m.readHold.Load()),
+		WriteHold:  time.Duration(m.writeHold.Load()),
+		Operations: make(map[string]uint64),
+	}
+	m.operations.Range(func(op, counter any) bool {
+		stats.Operations[op.(string)] = counter.(*atomic.Uint64).Load()
+		return true
+	})
+	return stats
+}
@@ -1,13 +1,32 @@
 package rwmutex
 
 import (
+	"context"
+	"fmt"
 	"sync"
+	"time"
+
+	"github.com/lazybabe/gods"
 )
 
-// RWMutex is a sync.RWMutex with a switch for concurrent safe feature.
+// RWMutex is a read/write lock with a switch for concurrent safe feature,
+// and a pluggable locking strategy.
 type RWMutex struct {
-	// Underlying rwmutex.
-	rwmutex *sync.RWMutex
+	// Underlying locker, which is nil if not in concurrent-safe usage.
+	locker locker
+	kind   gods.Locker
+	// Recorded metrics, which is nil if the metrics are not enabled.
+	metrics *metrics
+}
+
+// locker is the implementation of a locking strategy.
+type locker interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+	TryLock() bool
+	TryRLock() bool
 }
 
 // New creates and returns a new *RWMutex.
@@ -23,46 +42,299 @@ func New(safe ...bool) *RWMutex {
 // which is false in default.
 func Create(safe ...bool) RWMutex {
 	if len(safe) > 0 && safe[0] {
-		return RWMutex{
-			rwmutex: new(sync.RWMutex),
-		}
+		return CreateLocker(gods.LockRWMutex)
 	}
 	return RWMutex{}
 }
 
+// CreateWith creates and returns a new RWMutex object configured by `o`.
+func CreateWith(o gods.Options) RWMutex {
+	var m *metrics
+	if o.Metrics {
+		m = new(metrics)
+	}
+	if l := newLocker(o.Locker); l != nil {
+		if o.Debug || debug {
+			limit := o.HoldLimit
+			if limit == 0 {
+				limit = gods.DefaultHoldLimit
+			}
+			l = newCheckedLocker(l, o.Locker == gods.LockCopyOnWrite, limit)
+		}
+		return RWMutex{locker: l, kind: o.Locker, metrics: m}
+	}
+	return RWMutex{metrics: m}
+}
+
+// CreateWithoutCOW creates and returns a new RWMutex object configured by `options` for `container`,
+// which supports only the locking options but gods.LockCopyOnWrite, as its readers always lock.
+// It panics if another option is given, see gods.Options.Check.
+func CreateWithoutCOW(container string, options []gods.Option) RWMutex {
+	o := gods.NewOptions(options...)
+	o.Check(container, 0)
+	return CreateWith(o)
+}
+
+// OptionsOf returns the options of the `options` argument of the constructors of `container`
+// which accept the former `safe` bool as well as gods.Option, such as array.New:
+// a bool is gods.Safe of it, which is deprecated, and a gods.Option is itself.
+// It panics on an argument of another type, or on more than one bool as gods.Safe does.
+func OptionsOf(container string, options []any) []gods.Option {
+	var safe []bool
+	opts := make([]gods.Option, 0, len(options))
+	for _, option := range options {
+		switch option := option.(type) {
+		case nil:
+		case bool:
+			safe = append(safe, option)
+		case gods.Option:
+			opts = append(opts, option)
+		default:
+			panic(fmt.Sprintf("gods: %s takes bool or gods.Option, got %T", container, option))
+		}
+	}
+	if len(safe) > 0 {
+		// The bool is applied first, so the options given along with it take precedence.
+		opts = append([]gods.Option{gods.Safe(safe...)}, opts...)
+	}
+	return opts
+}
+
+// Any returns `options` as the argument of the constructors taking them as any, such as set.New.
+func Any(options []gods.Option) []any {
+	args := make([]any, len(options))
+	for i, option := range options {
+		args[i] = option
+	}
+	return args
+}
+
+// CreateLocker creates and returns a new RWMutex object with the locking strategy `kind`.
+func CreateLocker(kind gods.Locker) RWMutex {
+	return CreateWith(gods.Options{Locker: kind})
+}
+
+// newLocker creates and returns the locker of the locking strategy `kind`,
+// or nil if `kind` is LockNone or unknown.
+func newLocker(kind gods.Locker) locker {
+	switch kind {
+	case gods.LockMutex:
+		return new(mutexLocker)
+	case gods.LockRWMutex:
+		return new(sync.RWMutex)
+	case gods.LockSpin:
+		return new(spinLocker)
+	case gods.LockCopyOnWrite:
+		return new(cowLocker)
+	}
+	return nil
+}
+
+// Options returns the locking options which configure a copy of current rwmutex.
+func (mu *RWMutex) Options() []gods.Option {
+	return []gods.Option{
+		gods.WithLocker(mu.Locker()), gods.WithMetrics(mu.HasMetrics()),
+		gods.WithDebug(mu.IsDebug()), gods.WithHoldLimit(mu.HoldLimit()),
+	}
+}
+
 // IsSafe checks and returns whether current rwmutex is in concurrent-safe usage.
 func (mu *RWMutex) IsSafe() bool {
-	return mu.rwmutex != nil
+	return mu.locker != nil
+}
+
+// Locker returns the locking strategy of current rwmutex.
+func (mu *RWMutex) Locker() gods.Locker {
+	return mu.kind
+}
+
+// IsDebug checks and returns whether current rwmutex uses checked locks, see gods.WithDebug.
+func (mu *RWMutex) IsDebug() bool {
+	_, ok := mu.locker.(*checkedLocker)
+	return ok
+}
+
+// HoldLimit returns the duration after which a checked lock held is reported,
+// which is negative if there is no report, or zero if current rwmutex does not use checked locks.
+func (mu *RWMutex) HoldLimit() time.Duration {
+	if l, ok := mu.locker.(*checkedLocker); ok {
+		return l.holdLimit
+	}
+	return 0
+}
+
+// HasMetrics checks and returns whether current rwmutex records metrics.
+func (mu *RWMutex) HasMetrics() bool {
+	return mu.metrics != nil
+}
+
+// Count increases the counter of operation `op`.
+// It does nothing if the metrics are not enabled.
+func (mu *RWMutex) Count(op string) {
+	if mu.metrics != nil {
+		mu.metrics.count(op)
+	}
+}
+
+// Stats returns a snapshot of the metrics, which are all zero if the metrics are not enabled.
+func (mu *RWMutex) Stats() gods.Stats {
+	if mu.metrics == nil {
+		return gods.Stats{}
+	}
+	return mu.metrics.stats()
+}
+
+// IsCopyOnWrite checks and returns whether current rwmutex is in copy-on-write usage,
+// in which RLock does nothing, so the guarded data must be copied and published on writing.
+func (mu *RWMutex) IsCopyOnWrite() bool {
+	return mu.kind == gods.LockCopyOnWrite
 }
 
 // Lock locks rwmutex for writing.
 // It does nothing if it is not in concurrent-safe usage.
 func (mu *RWMutex) Lock() {
-	if mu.rwmutex != nil {
-		mu.rwmutex.Lock()
+	if mu.metrics == nil {
+		if mu.locker != nil {
+			mu.locker.Lock()
+		}
+		return
+	}
+	start := time.Now()
+	if mu.locker != nil {
+		mu.locker.Lock()
+	}
+	mu.metrics.locked(start)
+}
+
+// TryLock tries to lock rwmutex for writing, and reports whether it succeeded.
+// It always succeeds if it is not in concurrent-safe usage.
+func (mu *RWMutex) TryLock() bool {
+	if mu.locker != nil && !mu.locker.TryLock() {
+		return false
+	}
+	if mu.metrics != nil {
+		mu.metrics.locked(time.Now())
 	}
+	return true
+}
+
+// LockContext locks rwmutex for writing, or returns ctx.Err() if `ctx` is done before
+// the lock is acquired. It does nothing if it is not in concurrent-safe usage.
+//
+// Note that it polls the lock with a growing backoff, so it may wait longer than Lock
+// under contention, and it does not block the readers arriving later like Lock does.
+func (mu *RWMutex) LockContext(ctx context.Context) error {
+	var start time.Time
+	if mu.metrics != nil {
+		start = time.Now()
+	}
+	if mu.locker != nil {
+		if err := acquire(ctx, mu.locker.TryLock); err != nil {
+			return err
+		}
+	}
+	if mu.metrics != nil {
+		mu.metrics.locked(start)
+	}
+	return nil
 }
 
 // Unlock unlocks rwmutex for writing.
 // It does nothing if it is not in concurrent-safe usage.
 func (mu *RWMutex) Unlock() {
-	if mu.rwmutex != nil {
-		mu.rwmutex.Unlock()
+	if mu.metrics != nil {
+		mu.metrics.unlocking()
+	}
+	if mu.locker != nil {
+		mu.locker.Unlock()
 	}
 }
 
 // RLock locks rwmutex for reading.
 // It does nothing if it is not in concurrent-safe usage.
 func (mu *RWMutex) RLock() {
-	if mu.rwmutex != nil {
-		mu.rwmutex.RLock()
+	if mu.metrics == nil {
+		if mu.locker != nil {
+			mu.locker.RLock()
+		}
+		return
 	}
+	start := time.Now()
+	if mu.locker != nil {
+		mu.locker.RLock()
+	}
+	mu.metrics.rlocked(start)
+}
+
+// TryRLock tries to lock rwmutex for reading, and reports whether it succeeded.
+// It always succeeds if it is not in concurrent-safe usage.
+func (mu *RWMutex) TryRLock() bool {
+	if mu.locker != nil && !mu.locker.TryRLock() {
+		return false
+	}
+	if mu.metrics != nil {
+		mu.metrics.rlocked(time.Now())
+	}
+	return true
+}
+
+// RLockContext locks rwmutex for reading, or returns ctx.Err() if `ctx` is done before
+// the lock is acquired. It does nothing if it is not in concurrent-safe usage.
+func (mu *RWMutex) RLockContext(ctx context.Context) error {
+	var start time.Time
+	if mu.metrics != nil {
+		start = time.Now()
+	}
+	if mu.locker != nil {
+		if err := acquire(ctx, mu.locker.TryRLock); err != nil {
+			return err
+		}
+	}
+	if mu.metrics != nil {
+		mu.metrics.rlocked(start)
+	}
+	return nil
 }
 
 // RUnlock unlocks rwmutex for reading.
 // It does nothing if it is not in concurrent-safe usage.
 func (mu *RWMutex) RUnlock() {
-	if mu.rwmutex != nil {
-		mu.rwmutex.RUnlock()
+	if mu.metrics != nil {
+		mu.metrics.runlocking()
+	}
+	if mu.locker != nil {
+		mu.locker.RUnlock()
+	}
+}
+
+const (
+	// minBackoff is the first interval between the attempts of acquire.
+	minBackoff = 10 * time.Microsecond
+	// maxBackoff is the longest interval between the attempts of acquire.
+	maxBackoff = time.Millisecond
+)
+
+// acquire calls `try` until it succeeds, or returns ctx.Err() if `ctx` is done before.
+// The interval between the attempts doubles from minBackoff up to maxBackoff.
+func acquire(ctx context.Context, try func() bool) error {
+	if try() {
+		return nil
+	}
+	backoff := minBackoff
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if try() {
+			return nil
+		}
+		if backoff < maxBackoff {
+			backoff *= 2
+		}
+		timer.Reset(backoff)
 	}
 }
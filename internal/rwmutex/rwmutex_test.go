@@ -1,6 +1,10 @@
 package rwmutex_test
 
 import (
+	"bytes"
+	"context"
+	"log"
+	"sync"
 	"testing"
 	"time"
 
@@ -8,6 +12,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gmeasure"
 
+	"github.com/lazybabe/gods"
 	"github.com/lazybabe/gods/internal/rwmutex"
 )
 
@@ -24,9 +29,291 @@ var _ = Describe("RwMutex", func() {
 		Expect(unsafeLock.IsSafe()).To(BeFalse())
 	})
 
+	DescribeTable("CreateLocker",
+		func(kind gods.Locker, safe, copyOnWrite bool) {
+			mu := rwmutex.CreateLocker(kind)
+			Expect(mu.IsSafe()).To(Equal(safe))
+			Expect(mu.IsCopyOnWrite()).To(Equal(copyOnWrite))
+			Expect(mu.Locker()).To(Equal(kind))
+
+			var wg sync.WaitGroup
+			counter := 0
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 1000; j++ {
+						mu.RLock()
+						mu.RUnlock()
+						if safe {
+							mu.Lock()
+							counter++
+							mu.Unlock()
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			if safe {
+				Expect(counter).To(Equal(8000))
+			}
+		},
+		Entry("none", gods.LockNone, false, false),
+		Entry("mutex", gods.LockMutex, true, false),
+		Entry("rwmutex", gods.LockRWMutex, true, false),
+		Entry("spin", gods.LockSpin, true, false),
+		Entry("copy-on-write", gods.LockCopyOnWrite, true, true),
+	)
+
+	It("Create", func() {
+		safeLock := rwmutex.Create(true)
+		unsafeLock := rwmutex.Create()
+		Expect(safeLock.Locker()).To(Equal(gods.LockRWMutex))
+		Expect(unsafeLock.Locker()).To(Equal(gods.LockNone))
+	})
+
+	It("CreateWithoutCOW", func() {
+		mu := rwmutex.CreateWithoutCOW("test.Test", []gods.Option{
+			gods.WithLocker(gods.LockSpin), gods.WithMetrics(true), gods.WithHoldLimit(-1), gods.WithDebug(true),
+		})
+		Expect(mu.Locker()).To(Equal(gods.LockSpin))
+		c := rwmutex.CreateWith(gods.NewOptions(mu.Options()...))
+		Expect(c.Locker()).To(Equal(gods.LockSpin))
+		Expect(c.HasMetrics()).To(BeTrue())
+		Expect(c.IsDebug()).To(BeTrue())
+		Expect(c.HoldLimit()).To(Equal(time.Duration(-1)))
+
+		Expect(func() { rwmutex.CreateWithoutCOW("test.Test", []gods.Option{gods.WithLocker(gods.LockCopyOnWrite)}) }).
+			To(PanicWith("gods: test.Test does not support LockCopyOnWrite"))
+		Expect(func() { rwmutex.CreateWithoutCOW("test.Test", []gods.Option{gods.WithCapacity(1)}) }).
+			To(PanicWith("gods: test.Test does not support WithCapacity"))
+	})
+
+	It("OptionsOf", func() {
+		locker := func(options ...any) gods.Locker {
+			return gods.NewOptions(rwmutex.OptionsOf("test.Test", options)...).Locker
+		}
+		Expect(locker()).To(Equal(gods.LockNone))
+		Expect(locker(nil)).To(Equal(gods.LockNone))
+		Expect(locker(true)).To(Equal(gods.LockRWMutex))
+		Expect(locker(false)).To(Equal(gods.LockNone))
+		Expect(locker(gods.WithLocker(gods.LockSpin))).To(Equal(gods.LockSpin))
+		Expect(locker(gods.WithLocker(gods.LockSpin), true)).To(Equal(gods.LockSpin))
+		Expect(func() { locker(true, false) }).To(PanicWith("gods: Safe takes at most one value, got 2"))
+		Expect(func() { locker("safe") }).To(PanicWith("gods: test.Test takes bool or gods.Option, got string"))
+	})
+
+	It("Metrics", func() {
+		mu := rwmutex.CreateWith(gods.Options{Locker: gods.LockRWMutex, Metrics: true})
+		Expect(mu.HasMetrics()).To(BeTrue())
+		mu.Lock()
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				mu.RLock()
+				mu.Count("Get")
+				mu.RUnlock()
+			}()
+		}
+		time.Sleep(10 * time.Millisecond)
+		mu.Count("Set")
+		mu.Unlock()
+		wg.Wait()
+
+		stats := mu.Stats()
+		Expect(stats.WriteLocks).To(Equal(uint64(1)))
+		Expect(stats.ReadLocks).To(Equal(uint64(2)))
+		Expect(stats.WriteHold).To(BeNumerically(">=", 10*time.Millisecond))
+		Expect(stats.ReadWait).To(BeNumerically(">=", 5*time.Millisecond))
+		Expect(stats.ReadHold).To(BeNumerically(">", 0))
+		Expect(stats.Operations).To(Equal(map[string]uint64{"Get": 2, "Set": 1}))
+
+		unsafeLock := rwmutex.CreateWith(gods.Options{Metrics: true})
+		unsafeLock.RLock()
+		unsafeLock.RUnlock()
+		Expect(unsafeLock.Stats().ReadLocks).To(Equal(uint64(1)))
+
+		noMetrics := rwmutex.CreateWith(gods.Options{Locker: gods.LockRWMutex})
+		noMetrics.Count("Get")
+		Expect(noMetrics.HasMetrics()).To(BeFalse())
+		Expect(noMetrics.Stats()).To(Equal(gods.Stats{}))
+	})
+
+	DescribeTable("Debug",
+		func(kind gods.Locker, lock func(mu *rwmutex.RWMutex), unlock func(mu *rwmutex.RWMutex), message string) {
+			mu := rwmutex.CreateWith(gods.Options{Locker: kind, Debug: true})
+			Expect(mu.IsDebug()).To(BeTrue())
+			lock(&mu)
+			if message == "" {
+				Expect(func() { lock(&mu); unlock(&mu) }).NotTo(Panic())
+			} else {
+				Expect(func() { lock(&mu) }).To(PanicWith(ContainSubstring(message)))
+			}
+			unlock(&mu)
+
+			// The lock is not broken by the panic.
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				mu.Lock()
+				mu.Unlock()
+			}()
+			Eventually(done).Should(BeClosed())
+		},
+		Entry("write inside write", gods.LockRWMutex,
+			(*rwmutex.RWMutex).Lock, (*rwmutex.RWMutex).Unlock, "for writing while holding the lock for writing"),
+		Entry("read inside read", gods.LockRWMutex,
+			(*rwmutex.RWMutex).RLock, (*rwmutex.RWMutex).RUnlock, "for reading while holding the lock for reading"),
+		Entry("read inside read of mutex", gods.LockMutex,
+			(*rwmutex.RWMutex).RLock, (*rwmutex.RWMutex).RUnlock, "for reading while holding the lock for reading"),
+		Entry("write inside write of spin", gods.LockSpin,
+			(*rwmutex.RWMutex).Lock, (*rwmutex.RWMutex).Unlock, "for writing while holding the lock for writing"),
+		Entry("write inside write of copy-on-write", gods.LockCopyOnWrite,
+			(*rwmutex.RWMutex).Lock, (*rwmutex.RWMutex).Unlock, "for writing while holding the lock for writing"),
+		Entry("read inside read of copy-on-write", gods.LockCopyOnWrite,
+			(*rwmutex.RWMutex).RLock, (*rwmutex.RWMutex).RUnlock, ""),
+	)
+
+	It("Debug mixed locking", func() {
+		mu := rwmutex.CreateWith(gods.Options{Locker: gods.LockRWMutex, Debug: true})
+		mu.RLock()
+		Expect(mu.Lock).To(PanicWith(ContainSubstring("for writing while holding the lock for reading")))
+		mu.RUnlock()
+		mu.Lock()
+		Expect(mu.RLock).To(PanicWith(ContainSubstring("for reading while holding the lock for writing")))
+		mu.Unlock()
+
+		// Other goroutines may hold the lock for reading at the same time.
+		mu.RLock()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			mu.RLock()
+			mu.RUnlock()
+		}()
+		Eventually(done).Should(BeClosed())
+		mu.RUnlock()
+
+		cow := rwmutex.CreateWith(gods.Options{Locker: gods.LockCopyOnWrite, Debug: true})
+		cow.Lock()
+		Expect(func() { cow.RLock(); cow.RUnlock() }).NotTo(Panic())
+		cow.Unlock()
+
+		unsafeLock := rwmutex.CreateWith(gods.Options{Debug: true})
+		Expect(unsafeLock.IsDebug()).To(BeFalse())
+		Expect(unsafeLock.HoldLimit()).To(BeZero())
+		Expect(mu.HoldLimit()).To(Equal(gods.DefaultHoldLimit))
+	})
+
+	It("Debug hold limit", func() {
+		var buf bytes.Buffer
+		var bufMu sync.Mutex
+		log.SetOutput(writerFunc(func(p []byte) (int, error) {
+			bufMu.Lock()
+			defer bufMu.Unlock()
+			return buf.Write(p)
+		}))
+		defer log.SetOutput(GinkgoWriter)
+		logged := func() string {
+			bufMu.Lock()
+			defer bufMu.Unlock()
+			return buf.String()
+		}
+
+		mu := rwmutex.CreateWith(gods.Options{Locker: gods.LockRWMutex, Debug: true, HoldLimit: 10 * time.Millisecond})
+		mu.Lock()
+		mu.Unlock()
+		mu.RLock()
+		mu.RUnlock()
+		time.Sleep(20 * time.Millisecond)
+		Expect(logged()).To(BeEmpty())
+
+		mu.Lock()
+		Eventually(logged).Should(ContainSubstring("has held the lock for writing longer than 10ms"))
+		mu.Unlock()
+		mu.RLock()
+		Eventually(logged).Should(ContainSubstring("has held the lock for reading longer than 10ms"))
+		Expect(logged()).To(ContainSubstring("rwmutex_test.go"))
+		mu.RUnlock()
+
+		silent := rwmutex.CreateWith(gods.Options{Locker: gods.LockRWMutex, Debug: true, HoldLimit: -1})
+		Expect(silent.HoldLimit()).To(BeNumerically("<", 0))
+	})
+
+	DescribeTable("LockContext",
+		func(kind gods.Locker, lockFreeReads bool) {
+			mu := rwmutex.CreateWith(gods.Options{Locker: kind, Metrics: true})
+			Expect(mu.TryLock()).To(BeTrue())
+			Expect(mu.TryLock()).To(BeFalse())
+			Expect(mu.TryRLock()).To(Equal(lockFreeReads))
+			if lockFreeReads {
+				mu.RUnlock()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			Expect(mu.LockContext(ctx)).To(MatchError(context.DeadlineExceeded))
+			if !lockFreeReads {
+				Expect(mu.RLockContext(ctx)).To(MatchError(context.DeadlineExceeded))
+			}
+
+			go func() {
+				time.Sleep(5 * time.Millisecond)
+				mu.Unlock()
+			}()
+			Expect(mu.LockContext(context.Background())).To(Succeed())
+			mu.Unlock()
+			Expect(mu.RLockContext(context.Background())).To(Succeed())
+			shared := make(chan bool)
+			go func() {
+				ok := mu.TryRLock()
+				if ok {
+					mu.RUnlock()
+				}
+				shared <- ok
+			}()
+			Expect(<-shared).To(Equal(kind != gods.LockMutex && kind != gods.LockSpin))
+			mu.RUnlock()
+			Expect(mu.Stats().WriteLocks).To(Equal(uint64(2)))
+			Expect(mu.Stats().WriteWait).To(BeNumerically(">=", 5*time.Millisecond))
+
+			// A done context does not fail the lock free to acquire.
+			cancel()
+			Expect(mu.LockContext(ctx)).To(Succeed())
+			mu.Unlock()
+		},
+		Entry("mutex", gods.LockMutex, false),
+		Entry("rwmutex", gods.LockRWMutex, false),
+		Entry("spin", gods.LockSpin, false),
+		Entry("copy-on-write", gods.LockCopyOnWrite, true),
+	)
+
+	It("LockContext unsafe", func() {
+		mu := rwmutex.Create()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(mu.TryLock()).To(BeTrue())
+		Expect(mu.TryLock()).To(BeTrue())
+		Expect(mu.LockContext(ctx)).To(Succeed())
+		Expect(mu.RLockContext(ctx)).To(Succeed())
+
+		checked := rwmutex.CreateWith(gods.Options{Locker: gods.LockRWMutex, Debug: true})
+		Expect(checked.TryLock()).To(BeTrue())
+		Expect(checked.TryRLock()).To(BeFalse())
+		Expect(checked.TryLock()).To(BeFalse())
+		checked.Unlock()
+		Expect(checked.RLockContext(ctx)).To(Succeed())
+		checked.RUnlock()
+	})
+
 	It("Benchmark", Serial, func() {
 		safeLock := rwmutex.New(true)
 		unsafeLock := rwmutex.New(false)
+		if safeLock.IsDebug() {
+			Skip("checked locks are not measured")
+		}
 
 		experiment := gmeasure.NewExperiment("LockUnlock")
 		AddReportEntry(experiment.Name, experiment)
@@ -59,3 +346,10 @@ var _ = Describe("RwMutex", func() {
 		Expect(unsafeRLockMedian).To(BeNumerically("<", 200*time.Nanosecond))
 	})
 })
+
+// writerFunc is an io.Writer calling the func.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,56 @@
+package interval
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// Check checks the tree against the properties of the augmented AVL tree,
+// it returns an error describing the first violation.
+func (t *Tree[K, V]) Check() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	size, err := t.check(t.root, nil, nil)
+	if err == nil && size != t.size {
+		err = fmt.Errorf("size %d of %d intervals", t.size, size)
+	}
+	return err
+}
+
+// check checks the subtree rooted at `n` whose intervals are between `lo` and `hi` excluded,
+// it returns the number of its intervals.
+func (t *Tree[K, V]) check(n, lo, hi *node[K, V]) (size int, err error) {
+	if n == nil {
+		return 0, nil
+	}
+	if lo != nil && compare(n.lo, n.hi, lo) <= 0 || hi != nil && compare(n.lo, n.hi, hi) >= 0 {
+		return 0, fmt.Errorf("interval [%v, %v) out of order", n.lo, n.hi)
+	}
+	if cmp.Compare(n.lo, n.hi) >= 0 || len(n.values) == 0 {
+		return 0, fmt.Errorf("empty interval [%v, %v) of %d values", n.lo, n.hi, len(n.values))
+	}
+	if balance := height(n.left) - height(n.right); balance < -1 || balance > 1 {
+		return 0, fmt.Errorf("unbalanced interval [%v, %v)", n.lo, n.hi)
+	}
+	if n.height != 1+max(height(n.left), height(n.right)) {
+		return 0, fmt.Errorf("height %d of interval [%v, %v)", n.height, n.lo, n.hi)
+	}
+	want := n.hi
+	for _, child := range []*node[K, V]{n.left, n.right} {
+		if child != nil && cmp.Compare(child.max, want) > 0 {
+			want = child.max
+		}
+	}
+	if n.max != want {
+		return 0, fmt.Errorf("max %v of interval [%v, %v), want %v", n.max, n.lo, n.hi, want)
+	}
+	left, err := t.check(n.left, lo, n)
+	if err != nil {
+		return 0, err
+	}
+	right, err := t.check(n.right, n, hi)
+	if err != nil {
+		return 0, err
+	}
+	return left + len(n.values) + right, nil
+}
@@ -0,0 +1,253 @@
+package interval_test
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/interval"
+)
+
+func TestInterval(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Interval Suite")
+}
+
+// ranges returns the ranges [lo, hi) of the pairs.
+func ranges(pairs ...[2]int) []interval.Range[int] {
+	r := make([]interval.Range[int], len(pairs))
+	for i, p := range pairs {
+		r[i] = interval.Range[int]{Lo: p[0], Hi: p[1]}
+	}
+	return r
+}
+
+var _ = Describe("Tree", func() {
+	It("Insert, Delete and Contains", func() {
+		t := interval.New[int, string]()
+		Expect(t.IsEmpty()).To(BeTrue())
+		Expect(t.Insert(1, 5, "a")).To(BeTrue())
+		Expect(t.Insert(3, 8, "b")).To(BeTrue())
+		Expect(t.Insert(1, 5, "c")).To(BeTrue())
+		Expect(t.Insert(1, 5, "a")).To(BeFalse())
+		Expect(t.Insert(4, 4, "d")).To(BeFalse())
+		Expect(t.Insert(5, 4, "d")).To(BeFalse())
+		Expect(t.Size()).To(Equal(3))
+		Expect(t.Contains(1, 5, "c")).To(BeTrue())
+		Expect(t.Contains(1, 5, "b")).To(BeFalse())
+		Expect(t.Contains(1, 6, "a")).To(BeFalse())
+		Expect(t.String()).To(Equal("[[1, 5):a [1, 5):c [3, 8):b]"))
+
+		Expect(t.Delete(1, 5, "b")).To(BeFalse())
+		Expect(t.Delete(2, 5, "a")).To(BeFalse())
+		Expect(t.Delete(1, 5, "a")).To(BeTrue())
+		Expect(t.Slice()).To(Equal([]interval.Interval[int, string]{{1, 5, "c"}, {3, 8, "b"}}))
+		Expect(t.Check()).To(Succeed())
+
+		var visited []string
+		t.Each(func(i interval.Interval[int, string]) bool {
+			visited = append(visited, i.Value)
+			return false
+		})
+		Expect(visited).To(Equal([]string{"c"}))
+
+		t.Clear()
+		Expect(t.Size()).To(BeZero())
+		Expect(t.Slice()).To(BeEmpty())
+	})
+
+	It("Overlapping and Stabbing", func() {
+		t := interval.New[int, string]()
+		t.Insert(9, 12, "standup")
+		t.Insert(10, 11, "review")
+		t.Insert(11, 13, "lunch")
+		t.Insert(14, 16, "demo")
+		t.Insert(8, 18, "office")
+
+		Expect(t.Overlapping(11, 14).Slice()).To(Equal([]interval.Interval[int, string]{
+			{8, 18, "office"}, {9, 12, "standup"}, {11, 13, "lunch"},
+		}))
+		Expect(t.Overlapping(16, 20).Slice()).To(Equal([]interval.Interval[int, string]{{8, 18, "office"}}))
+		Expect(t.Overlapping(18, 20).Size()).To(BeZero())
+		Expect(t.Overlapping(0, 8).Size()).To(BeZero())
+		Expect(t.Overlapping(12, 12).Size()).To(BeZero())
+
+		Expect(t.Stabbing(11).Slice()).To(Equal([]interval.Interval[int, string]{
+			{8, 18, "office"}, {9, 12, "standup"}, {11, 13, "lunch"},
+		}))
+		Expect(t.Stabbing(10).Size()).To(Equal(3))
+		Expect(t.Stabbing(16).Slice()).To(Equal([]interval.Interval[int, string]{{8, 18, "office"}}))
+		Expect(t.Stabbing(18).Size()).To(BeZero())
+		Expect(t.Stabbing(7).Size()).To(BeZero())
+	})
+
+	It("matches a naive model after random operations", func() {
+		r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+		t := interval.New[float64, int]()
+		var model []interval.Interval[float64, int]
+		indexOf := func(i interval.Interval[float64, int]) int {
+			for j := range model {
+				if model[j] == i {
+					return j
+				}
+			}
+			return -1
+		}
+		// naive returns the intervals of the model matching `fn` sorted like the tree does.
+		naive := func(fn func(i interval.Interval[float64, int]) bool) []interval.Interval[float64, int] {
+			result := make([]interval.Interval[float64, int], 0)
+			for _, i := range model {
+				if fn(i) {
+					result = append(result, i)
+				}
+			}
+			sort.SliceStable(result, func(a, b int) bool {
+				if result[a].Lo != result[b].Lo {
+					return result[a].Lo < result[b].Lo
+				}
+				return result[a].Hi < result[b].Hi
+			})
+			return result
+		}
+		for n := 0; n < 3000; n++ {
+			lo := float64(r.Intn(100))
+			i := interval.Interval[float64, int]{Lo: lo, Hi: lo + float64(1+r.Intn(20)), Value: r.Intn(3)}
+			if r.Intn(3) == 0 {
+				j := indexOf(i)
+				Expect(t.Delete(i.Lo, i.Hi, i.Value)).To(Equal(j >= 0))
+				if j >= 0 {
+					model = append(model[:j], model[j+1:]...)
+				}
+			} else {
+				Expect(t.Insert(i.Lo, i.Hi, i.Value)).To(Equal(indexOf(i) < 0))
+				if indexOf(i) < 0 {
+					model = append(model, i)
+				}
+			}
+			Expect(t.Check()).To(Succeed())
+			Expect(t.Size()).To(Equal(len(model)))
+
+			lo, hi := float64(r.Intn(120)), float64(r.Intn(120))
+			overlapping := naive(func(i interval.Interval[float64, int]) bool { return lo < hi && i.Lo < hi && lo < i.Hi })
+			Expect(t.Overlapping(lo, hi).Slice()).To(Equal(overlapping))
+			point := float64(r.Intn(120)) + 0.5*float64(r.Intn(2))
+			stabbing := naive(func(i interval.Interval[float64, int]) bool { return i.Lo <= point && point < i.Hi })
+			Expect(t.Stabbing(point).Slice()).To(Equal(stabbing))
+		}
+	})
+
+	It("Safe", func() {
+		Expect(func() { interval.New[int, int](gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: interval.Tree does not support LockCopyOnWrite"))
+		Expect(func() { interval.NewIntervalSet[int](gods.WithSnapshot(true)) }).
+			To(PanicWith("gods: interval.IntervalSet does not support WithSnapshot"))
+		t := interval.New[int, int](gods.WithSafe(true), gods.WithMetrics(true), gods.WithLocker(gods.LockMutex))
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					t.Insert(i, i+10, w)
+					t.Stabbing(i)
+				}
+			}(w)
+		}
+		wg.Wait()
+		Expect(t.Size()).To(Equal(2000))
+		Expect(t.Check()).To(Succeed())
+		Expect(t.Stabbing(100).Size()).To(Equal(40))
+		Expect(t.Stats().Operations).To(HaveKeyWithValue("Insert", uint64(2000)))
+	})
+})
+
+var _ = Describe("IntervalSet", func() {
+	It("Add merges the overlapping and adjacent ranges", func() {
+		s := interval.NewIntervalSet[int]()
+		Expect(s.IsEmpty()).To(BeTrue())
+		s.Add(10, 20)
+		s.Add(30, 40)
+		s.Add(50, 60)
+		s.Add(5, 5)
+		Expect(s.Ranges().Slice()).To(Equal(ranges([2]int{10, 20}, [2]int{30, 40}, [2]int{50, 60})))
+		s.Add(20, 25)
+		Expect(s.Ranges().Slice()).To(Equal(ranges([2]int{10, 25}, [2]int{30, 40}, [2]int{50, 60})))
+		s.Add(35, 55)
+		Expect(s.Ranges().Slice()).To(Equal(ranges([2]int{10, 25}, [2]int{30, 60})))
+		s.Add(0, 5)
+		s.Add(70, 80)
+		s.Add(26, 29)
+		Expect(s.String()).To(Equal("[[0, 5) [10, 25) [26, 29) [30, 60) [70, 80)]"))
+		s.Add(12, 14)
+		Expect(s.Size()).To(Equal(5))
+		s.Add(-10, 100)
+		Expect(s.Ranges().Slice()).To(Equal(ranges([2]int{-10, 100})))
+	})
+
+	It("Remove, Contains and Overlapping", func() {
+		s := interval.NewIntervalSet[int]()
+		s.Add(0, 100)
+		s.Remove(10, 20)
+		s.Remove(30, 40)
+		s.Remove(50, 50)
+		Expect(s.Ranges().Slice()).To(Equal(ranges([2]int{0, 10}, [2]int{20, 30}, [2]int{40, 100})))
+		s.Remove(25, 45)
+		Expect(s.Ranges().Slice()).To(Equal(ranges([2]int{0, 10}, [2]int{20, 25}, [2]int{45, 100})))
+		s.Remove(-5, 0)
+		s.Remove(10, 20)
+		Expect(s.Size()).To(Equal(3))
+
+		Expect(s.Contains(0)).To(BeTrue())
+		Expect(s.Contains(9)).To(BeTrue())
+		Expect(s.Contains(10)).To(BeFalse())
+		Expect(s.Contains(-1)).To(BeFalse())
+		Expect(s.Contains(100)).To(BeFalse())
+		Expect(s.ContainsRange(45, 100)).To(BeTrue())
+		Expect(s.ContainsRange(5, 21)).To(BeFalse())
+		Expect(s.ContainsRange(30, 30)).To(BeTrue())
+
+		Expect(s.Overlapping(9, 21).Slice()).To(Equal(ranges([2]int{0, 10}, [2]int{20, 25})))
+		Expect(s.Overlapping(10, 20).Size()).To(BeZero())
+		Expect(s.Overlapping(21, 20).Size()).To(BeZero())
+
+		s.Remove(-100, 200)
+		Expect(s.IsEmpty()).To(BeTrue())
+		s.Add(1, 2)
+		s.Clear()
+		Expect(s.IsEmpty()).To(BeTrue())
+	})
+
+	It("matches a naive model after random operations", func() {
+		r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+		s := interval.NewIntervalSet[int]()
+		var model [200]bool
+		for n := 0; n < 2000; n++ {
+			lo, hi := r.Intn(200), r.Intn(200)
+			add := r.Intn(3) != 0
+			if add {
+				s.Add(lo, hi)
+			} else {
+				s.Remove(lo, hi)
+			}
+			for i := lo; i < hi; i++ {
+				model[i] = add
+			}
+			var want []interval.Range[int]
+			for i := 0; i < len(model); i++ {
+				if model[i] && (i == 0 || !model[i-1]) {
+					want = append(want, interval.Range[int]{Lo: i, Hi: i + 1})
+				} else if model[i] {
+					want[len(want)-1].Hi = i + 1
+				}
+			}
+			Expect(s.Ranges().Slice()).To(Equal(append([]interval.Range[int]{}, want...)))
+			point := r.Intn(200)
+			Expect(s.Contains(point)).To(Equal(model[point]))
+		}
+	})
+})
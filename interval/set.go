@@ -0,0 +1,168 @@
+package interval
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// Range is a range [Lo, Hi) of an interval set.
+type Range[K cmp.Ordered] struct {
+	Lo K
+	Hi K
+}
+
+// String returns the range as a string.
+func (r Range[K]) String() string {
+	return fmt.Sprintf("[%v, %v)", r.Lo, r.Hi)
+}
+
+// IntervalSet is a set of the points of type K in a union of ranges, which it keeps as disjoint ranges,
+// merging the ones added overlapping or adjacent to others: adding [1, 3) and [3, 5) gives [1, 5).
+type IntervalSet[K cmp.Ordered] struct {
+	mu rwmutex.RWMutex
+	// Disjoint and non-adjacent ranges in ascending order.
+	ranges []Range[K]
+}
+
+// NewIntervalSet creates and returns an empty set.
+// The parameter `options` is used to configure the set, see gods.Option.
+// It is not concurrent-safe in default.
+func NewIntervalSet[K cmp.Ordered](options ...gods.Option) *IntervalSet[K] {
+	return &IntervalSet[K]{mu: rwmutex.CreateWithoutCOW("interval.IntervalSet", options)}
+}
+
+// Add adds the points in [lo, hi) to the set, merging the ranges overlapping or adjacent to it.
+// It does nothing if [lo, hi) is empty.
+func (s *IntervalSet[K]) Add(lo, hi K) {
+	s.mu.Count("Add")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cmp.Compare(lo, hi) >= 0 {
+		return
+	}
+	// The ranges in [i, j) overlap or are adjacent to [lo, hi).
+	i := s.search(func(r Range[K]) bool { return cmp.Compare(r.Hi, lo) >= 0 })
+	j := s.search(func(r Range[K]) bool { return cmp.Compare(r.Lo, hi) > 0 })
+	if i < j {
+		lo, hi = min(lo, s.ranges[i].Lo), max(hi, s.ranges[j-1].Hi)
+	}
+	s.ranges = slices.Replace(s.ranges, i, j, Range[K]{Lo: lo, Hi: hi})
+}
+
+// Remove removes the points in [lo, hi) from the set, cutting the ranges overlapping it.
+// It does nothing if [lo, hi) is empty.
+func (s *IntervalSet[K]) Remove(lo, hi K) {
+	s.mu.Count("Remove")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cmp.Compare(lo, hi) >= 0 {
+		return
+	}
+	// The ranges in [i, j) overlap [lo, hi).
+	i := s.search(func(r Range[K]) bool { return cmp.Compare(r.Hi, lo) > 0 })
+	j := s.search(func(r Range[K]) bool { return cmp.Compare(r.Lo, hi) >= 0 })
+	if i == j {
+		return
+	}
+	rest := make([]Range[K], 0, 2)
+	if first := s.ranges[i]; cmp.Compare(first.Lo, lo) < 0 {
+		rest = append(rest, Range[K]{Lo: first.Lo, Hi: lo})
+	}
+	if last := s.ranges[j-1]; cmp.Compare(last.Hi, hi) > 0 {
+		rest = append(rest, Range[K]{Lo: hi, Hi: last.Hi})
+	}
+	s.ranges = slices.Replace(s.ranges, i, j, rest...)
+}
+
+// Contains checks whether `point` is in the set.
+func (s *IntervalSet[K]) Contains(point K) bool {
+	s.mu.Count("Contains")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := s.search(func(r Range[K]) bool { return cmp.Compare(r.Hi, point) > 0 })
+	return i < len(s.ranges) && cmp.Compare(s.ranges[i].Lo, point) <= 0
+}
+
+// ContainsRange checks whether all the points in [lo, hi) are in the set,
+// which is true if [lo, hi) is empty.
+func (s *IntervalSet[K]) ContainsRange(lo, hi K) bool {
+	s.mu.Count("ContainsRange")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if cmp.Compare(lo, hi) >= 0 {
+		return true
+	}
+	i := s.search(func(r Range[K]) bool { return cmp.Compare(r.Hi, lo) > 0 })
+	return i < len(s.ranges) && cmp.Compare(s.ranges[i].Lo, lo) <= 0 && cmp.Compare(hi, s.ranges[i].Hi) <= 0
+}
+
+// Overlapping returns the ranges of the set overlapping [lo, hi) in ascending order.
+// Note that it returns an empty array if [lo, hi) is empty.
+func (s *IntervalSet[K]) Overlapping(lo, hi K) *array.Array[Range[K]] {
+	s.mu.Count("Overlapping")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if cmp.Compare(lo, hi) >= 0 {
+		return array.New[Range[K]]()
+	}
+	i := s.search(func(r Range[K]) bool { return cmp.Compare(r.Hi, lo) > 0 })
+	j := s.search(func(r Range[K]) bool { return cmp.Compare(r.Lo, hi) >= 0 })
+	return array.NewFrom(append([]Range[K](nil), s.ranges[i:j]...))
+}
+
+// Ranges returns the disjoint ranges of the set in ascending order.
+func (s *IntervalSet[K]) Ranges() *array.Array[Range[K]] {
+	s.mu.Count("Ranges")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return array.NewFrom(append([]Range[K](nil), s.ranges...))
+}
+
+// Size returns the number of disjoint ranges of the set.
+func (s *IntervalSet[K]) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.ranges)
+}
+
+// IsEmpty checks whether the set is empty.
+func (s *IntervalSet[K]) IsEmpty() bool {
+	return s.Size() == 0
+}
+
+// Clear removes all points of the set.
+func (s *IntervalSet[K]) Clear() {
+	s.mu.Count("Clear")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ranges = nil
+}
+
+// String returns the ranges of the set as a string.
+func (s *IntervalSet[K]) String() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := make([]string, len(s.ranges))
+	for i := range s.ranges {
+		r[i] = s.ranges[i].String()
+	}
+	return "[" + strings.Join(r, " ") + "]"
+}
+
+// Stats returns a snapshot of the metrics of the set created with gods.WithMetrics(true).
+func (s *IntervalSet[K]) Stats() gods.Stats {
+	return s.mu.Stats()
+}
+
+// search returns the index of the first range `fn` returns true for,
+// where `fn` is false then true over the ranges in ascending order.
+func (s *IntervalSet[K]) search(fn func(r Range[K]) bool) int {
+	return sort.Search(len(s.ranges), func(i int) bool { return fn(s.ranges[i]) })
+}
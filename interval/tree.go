@@ -0,0 +1,356 @@
+// Package interval implements an interval tree, which finds the intervals overlapping a range or
+// containing a point, and an interval set, which keeps a union of ranges as disjoint ones.
+//
+// The intervals are half-open: the interval [lo, hi) contains the points from lo included to hi excluded,
+// so [1, 3) and [3, 5) do not overlap, and an interval whose lo is not less than its hi is empty.
+package interval
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// Interval is an interval [Lo, Hi) of a tree with its value.
+type Interval[K cmp.Ordered, V comparable] struct {
+	Lo    K
+	Hi    K
+	Value V
+}
+
+// String returns the interval as a string.
+func (i Interval[K, V]) String() string {
+	return fmt.Sprintf("[%v, %v):%v", i.Lo, i.Hi, i.Value)
+}
+
+// node is a node of an AVL tree ordered by the intervals, augmented with the highest hi of its subtree.
+type node[K cmp.Ordered, V comparable] struct {
+	lo, hi K
+	// Values of the interval in the order they are inserted.
+	values []V
+	// Highest hi of the intervals in the subtree.
+	max         K
+	height      int
+	left, right *node[K, V]
+}
+
+// Tree is an interval tree of intervals of type K with values of type V,
+// which holds every value once per interval, and the same interval with different values.
+type Tree[K cmp.Ordered, V comparable] struct {
+	mu   rwmutex.RWMutex
+	root *node[K, V]
+	size int
+}
+
+// New creates and returns an empty tree.
+// The parameter `options` is used to configure the tree, see gods.Option.
+// It is not concurrent-safe in default.
+func New[K cmp.Ordered, V comparable](options ...gods.Option) *Tree[K, V] {
+	return &Tree[K, V]{mu: rwmutex.CreateWithoutCOW("interval.Tree", options)}
+}
+
+// Insert inserts the interval [lo, hi) with `value` into the tree, and reports whether it is inserted.
+// It returns false if the interval is empty, or it is already in the tree with `value`.
+func (t *Tree[K, V]) Insert(lo, hi K, value V) bool {
+	t.mu.Count("Insert")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cmp.Compare(lo, hi) >= 0 {
+		return false
+	}
+	var inserted bool
+	t.root = t.insert(t.root, lo, hi, value, &inserted)
+	if inserted {
+		t.size++
+	}
+	return inserted
+}
+
+// Delete deletes the interval [lo, hi) with `value` from the tree, and reports whether it is in the tree.
+func (t *Tree[K, V]) Delete(lo, hi K, value V) bool {
+	t.mu.Count("Delete")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var deleted bool
+	t.root = t.delete(t.root, lo, hi, value, &deleted)
+	if deleted {
+		t.size--
+	}
+	return deleted
+}
+
+// Contains checks whether the interval [lo, hi) with `value` is in the tree.
+func (t *Tree[K, V]) Contains(lo, hi K, value V) bool {
+	t.mu.Count("Contains")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for n := t.root; n != nil; {
+		switch c := compare(lo, hi, n); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return slices.Index(n.values, value) >= 0
+		}
+	}
+	return false
+}
+
+// Overlapping returns the intervals overlapping [lo, hi), which have points in common with it,
+// in ascending order of their lo, then of their hi.
+// Note that it returns an empty array if [lo, hi) is empty.
+func (t *Tree[K, V]) Overlapping(lo, hi K) *array.Array[Interval[K, V]] {
+	t.mu.Count("Overlapping")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	result := make([]Interval[K, V], 0)
+	if cmp.Compare(lo, hi) < 0 {
+		t.overlapping(t.root, lo, hi, func(n *node[K, V]) bool {
+			return cmp.Compare(n.lo, hi) < 0 && cmp.Compare(lo, n.hi) < 0
+		}, &result)
+	}
+	return array.NewFrom(result)
+}
+
+// Stabbing returns the intervals containing `point`, in ascending order of their lo, then of their hi.
+func (t *Tree[K, V]) Stabbing(point K) *array.Array[Interval[K, V]] {
+	t.mu.Count("Stabbing")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	result := make([]Interval[K, V], 0)
+	t.overlapping(t.root, point, point, func(n *node[K, V]) bool {
+		return cmp.Compare(n.lo, point) <= 0 && cmp.Compare(point, n.hi) < 0
+	}, &result)
+	return array.NewFrom(result)
+}
+
+// Each calls `fn` on every interval in the tree in ascending order of their lo, then of their hi,
+// if `fn` returns true then continue iterating; or false to stop.
+//
+// The tree is locked for reading while `fn` runs, so `fn` must not modify the tree.
+func (t *Tree[K, V]) Each(fn func(interval Interval[K, V]) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	t.each(t.root, fn)
+}
+
+// Slice returns the intervals in the tree in ascending order of their lo, then of their hi.
+func (t *Tree[K, V]) Slice() []Interval[K, V] {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	intervals := make([]Interval[K, V], 0, t.size)
+	t.each(t.root, func(interval Interval[K, V]) bool {
+		intervals = append(intervals, interval)
+		return true
+	})
+	return intervals
+}
+
+// Size returns the number of intervals in the tree, where an interval counts once per value.
+func (t *Tree[K, V]) Size() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.size
+}
+
+// IsEmpty checks whether the tree is empty.
+func (t *Tree[K, V]) IsEmpty() bool {
+	return t.Size() == 0
+}
+
+// Clear deletes all intervals of the tree.
+func (t *Tree[K, V]) Clear() {
+	t.mu.Count("Clear")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.root, t.size = nil, 0
+}
+
+// String returns the intervals in the tree as a string.
+func (t *Tree[K, V]) String() string {
+	intervals := t.Slice()
+	s := make([]string, len(intervals))
+	for i, interval := range intervals {
+		s[i] = interval.String()
+	}
+	return "[" + strings.Join(s, " ") + "]"
+}
+
+// Stats returns a snapshot of the metrics of the tree created with gods.WithMetrics(true).
+func (t *Tree[K, V]) Stats() gods.Stats {
+	return t.mu.Stats()
+}
+
+// insert inserts the interval [lo, hi) with `value` into the subtree `n`, and returns the new root
+// of the subtree. It sets `inserted` to whether the value is not in the subtree yet.
+func (t *Tree[K, V]) insert(n *node[K, V], lo, hi K, value V, inserted *bool) *node[K, V] {
+	if n == nil {
+		*inserted = true
+		return &node[K, V]{lo: lo, hi: hi, values: []V{value}, max: hi, height: 1}
+	}
+	switch c := compare(lo, hi, n); {
+	case c < 0:
+		n.left = t.insert(n.left, lo, hi, value, inserted)
+	case c > 0:
+		n.right = t.insert(n.right, lo, hi, value, inserted)
+	default:
+		if slices.Index(n.values, value) < 0 {
+			n.values = append(n.values, value)
+			*inserted = true
+		}
+		return n
+	}
+	return rebalance(n)
+}
+
+// delete deletes the interval [lo, hi) with `value` from the subtree `n`, and returns the new root
+// of the subtree. It sets `deleted` to whether the value is in the subtree.
+func (t *Tree[K, V]) delete(n *node[K, V], lo, hi K, value V, deleted *bool) *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	switch c := compare(lo, hi, n); {
+	case c < 0:
+		n.left = t.delete(n.left, lo, hi, value, deleted)
+	case c > 0:
+		n.right = t.delete(n.right, lo, hi, value, deleted)
+	default:
+		i := slices.Index(n.values, value)
+		if i < 0 {
+			return n
+		}
+		*deleted = true
+		n.values = slices.Delete(n.values, i, i+1)
+		if len(n.values) > 0 {
+			return n
+		}
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+		// Replace the interval with its successor, which is deleted from the right subtree.
+		var successor *node[K, V]
+		n.right = deleteMin(n.right, &successor)
+		n.lo, n.hi, n.values = successor.lo, successor.hi, successor.values
+	}
+	return rebalance(n)
+}
+
+// overlapping appends the intervals of the subtree `n` matching `match` to `result` in order.
+// It skips the subtrees without intervals overlapping the closed range [lo, hi],
+// so `match` must be false for them.
+func (t *Tree[K, V]) overlapping(n *node[K, V], lo, hi K, match func(n *node[K, V]) bool, result *[]Interval[K, V]) {
+	// No interval of the subtree ends after lo.
+	if n == nil || cmp.Compare(lo, n.max) >= 0 {
+		return
+	}
+	t.overlapping(n.left, lo, hi, match, result)
+	if match(n) {
+		for _, value := range n.values {
+			*result = append(*result, Interval[K, V]{Lo: n.lo, Hi: n.hi, Value: value})
+		}
+	}
+	// The intervals of the right subtree start after the one of `n`, so they all start after hi if it does.
+	if cmp.Compare(n.lo, hi) <= 0 {
+		t.overlapping(n.right, lo, hi, match, result)
+	}
+}
+
+// each calls `fn` on every interval of the subtree `n` in order, and reports whether `fn` returns true for all.
+func (t *Tree[K, V]) each(n *node[K, V], fn func(interval Interval[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	if !t.each(n.left, fn) {
+		return false
+	}
+	for _, value := range n.values {
+		if !fn(Interval[K, V]{Lo: n.lo, Hi: n.hi, Value: value}) {
+			return false
+		}
+	}
+	return t.each(n.right, fn)
+}
+
+// deleteMin deletes the lowest node of the subtree `n`, which is set to `min`, and returns the new root
+// of the subtree.
+func deleteMin[K cmp.Ordered, V comparable](n *node[K, V], min **node[K, V]) *node[K, V] {
+	if n.left == nil {
+		*min = n
+		return n.right
+	}
+	n.left = deleteMin(n.left, min)
+	return rebalance(n)
+}
+
+// rebalance updates the height and the highest hi of `n` from its children, rotates it if its
+// subtrees differ in height by 2, and returns the new root of the subtree.
+func rebalance[K cmp.Ordered, V comparable](n *node[K, V]) *node[K, V] {
+	update(n)
+	switch balance := height(n.left) - height(n.right); {
+	case balance > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case balance < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+// rotateLeft rotates the subtree `n` to the left, and returns its new root.
+func rotateLeft[K cmp.Ordered, V comparable](n *node[K, V]) *node[K, V] {
+	r := n.right
+	n.right, r.left = r.left, n
+	update(n)
+	update(r)
+	return r
+}
+
+// rotateRight rotates the subtree `n` to the right, and returns its new root.
+func rotateRight[K cmp.Ordered, V comparable](n *node[K, V]) *node[K, V] {
+	l := n.left
+	n.left, l.right = l.right, n
+	update(n)
+	update(l)
+	return l
+}
+
+// update updates the height and the highest hi of `n` from its children.
+func update[K cmp.Ordered, V comparable](n *node[K, V]) {
+	n.height = 1 + max(height(n.left), height(n.right))
+	n.max = n.hi
+	for _, child := range []*node[K, V]{n.left, n.right} {
+		if child != nil && cmp.Compare(child.max, n.max) > 0 {
+			n.max = child.max
+		}
+	}
+}
+
+// height returns the height of the subtree `n`, which is 0 if it is empty.
+func height[K cmp.Ordered, V comparable](n *node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// compare compares the interval [lo, hi) with the one of `n` by their lo, then by their hi.
+func compare[K cmp.Ordered, V comparable](lo, hi K, n *node[K, V]) int {
+	if c := cmp.Compare(lo, n.lo); c != 0 {
+		return c
+	}
+	return cmp.Compare(hi, n.hi)
+}
@@ -0,0 +1,314 @@
+package lockfree_test
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/lockfree"
+	"github.com/lazybabe/gods/stack"
+)
+
+func TestLockfree(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lockfree Suite")
+}
+
+// operation is a completed push or pop of a concurrent history.
+type operation struct {
+	push      bool
+	value     int
+	found     bool
+	call, ret int64
+}
+
+// linearizable checks whether the concurrent `history` equals some sequential history of
+// a queue or, if `lifo` is true, a stack, which respects the real-time order of operations.
+func linearizable(history []operation, lifo bool) bool {
+	done := make([]bool, len(history))
+	var search func(model []int, left int) bool
+	search = func(model []int, left int) bool {
+		if left == 0 {
+			return true
+		}
+		// Only the operations called before any pending operation returned may go first.
+		deadline := int64(1<<63 - 1)
+		for i, op := range history {
+			if !done[i] && op.ret < deadline {
+				deadline = op.ret
+			}
+		}
+		for i, op := range history {
+			if done[i] || op.call > deadline {
+				continue
+			}
+			next := model
+			switch {
+			case op.push:
+				next = append(append([]int{}, model...), op.value)
+			case len(model) == 0:
+				if op.found {
+					continue
+				}
+			case !op.found:
+				continue
+			case lifo:
+				if model[len(model)-1] != op.value {
+					continue
+				}
+				next = model[:len(model)-1]
+			default:
+				if model[0] != op.value {
+					continue
+				}
+				next = model[1:]
+			}
+			done[i] = true
+			if search(next, left-1) {
+				return true
+			}
+			done[i] = false
+		}
+		return false
+	}
+	return search(nil, len(history))
+}
+
+// record runs `goroutines` goroutines doing `ops` random pushes or pops each, and returns the history.
+func record(goroutines, ops int, push func(int), pop func() (int, bool)) []operation {
+	var (
+		clock   atomic.Int64
+		mu      sync.Mutex
+		history []operation
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+	)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			<-start
+			for i := 0; i < ops; i++ {
+				op := operation{push: (g+i)%2 == 0, value: g*ops + i}
+				op.call = clock.Add(1)
+				if op.push {
+					push(op.value)
+				} else {
+					op.value, op.found = pop()
+				}
+				op.ret = clock.Add(1)
+				mu.Lock()
+				history = append(history, op)
+				mu.Unlock()
+			}
+		}(g)
+	}
+	close(start)
+	wg.Wait()
+	return history
+}
+
+var _ = Describe("Queue", func() {
+	It("Enqueue|Dequeue", func() {
+		q := lockfree.NewQueue[int]()
+		Expect(q.IsEmpty()).To(BeTrue())
+		_, found := q.Dequeue()
+		Expect(found).To(BeFalse())
+		_, found = q.Peek()
+		Expect(found).To(BeFalse())
+		q.Enqueue(1)
+		q.Enqueue(2)
+		q.Enqueue(3)
+		Expect(q.Size()).To(Equal(3))
+		v, found := q.Peek()
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal(1))
+		for i := 1; i <= 3; i++ {
+			v, found = q.Dequeue()
+			Expect(found).To(BeTrue())
+			Expect(v).To(Equal(i))
+		}
+		Expect(q.IsEmpty()).To(BeTrue())
+		Expect(q.Size()).To(BeZero())
+	})
+
+	It("Linearizability", func() {
+		for round := 0; round < 200; round++ {
+			q := lockfree.NewQueue[int]()
+			history := record(3, 4, q.Enqueue, q.Dequeue)
+			Expect(linearizable(history, false)).To(BeTrue(), "history: %+v", history)
+		}
+	})
+
+	It("Concurrent", func() {
+		const producers, consumers, items = 4, 4, 2000
+		q := lockfree.NewQueue[int]()
+		var wg sync.WaitGroup
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func(p int) {
+				defer wg.Done()
+				for i := 0; i < items; i++ {
+					q.Enqueue(p*items + i)
+				}
+			}(p)
+		}
+		results := make([][]int, consumers)
+		var consumed atomic.Int64
+		for c := 0; c < consumers; c++ {
+			wg.Add(1)
+			go func(c int) {
+				defer wg.Done()
+				for consumed.Load() < producers*items {
+					if v, ok := q.Dequeue(); ok {
+						results[c] = append(results[c], v)
+						consumed.Add(1)
+					}
+				}
+			}(c)
+		}
+		wg.Wait()
+
+		seen := make(map[int]bool, producers*items)
+		for _, result := range results {
+			// Every consumer sees the items of every producer in the enqueued order.
+			last := make(map[int]int)
+			for _, v := range result {
+				Expect(seen[v]).To(BeFalse())
+				seen[v] = true
+				if prev, ok := last[v/items]; ok {
+					Expect(v).To(BeNumerically(">", prev))
+				}
+				last[v/items] = v
+			}
+		}
+		Expect(seen).To(HaveLen(producers * items))
+		Expect(q.IsEmpty()).To(BeTrue())
+	})
+})
+
+var _ = Describe("Stack", func() {
+	It("Push|Pop", func() {
+		var s lockfree.Stack[int]
+		Expect(s.IsEmpty()).To(BeTrue())
+		_, found := s.Pop()
+		Expect(found).To(BeFalse())
+		_, found = s.Peek()
+		Expect(found).To(BeFalse())
+		s.Push(1)
+		s.Push(2)
+		s.Push(3)
+		Expect(s.Size()).To(Equal(3))
+		v, found := s.Peek()
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal(3))
+		for i := 3; i >= 1; i-- {
+			v, found = s.Pop()
+			Expect(found).To(BeTrue())
+			Expect(v).To(Equal(i))
+		}
+		Expect(s.IsEmpty()).To(BeTrue())
+	})
+
+	It("Linearizability", func() {
+		for round := 0; round < 200; round++ {
+			s := lockfree.NewStack[int]()
+			history := record(3, 4, s.Push, s.Pop)
+			Expect(linearizable(history, true)).To(BeTrue(), "history: %+v", history)
+		}
+	})
+
+	It("Concurrent", func() {
+		const goroutines, items = 8, 2000
+		s := lockfree.NewStack[int]()
+		var wg sync.WaitGroup
+		popped := make([][]int, goroutines)
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < items; i++ {
+					s.Push(g*items + i)
+					if v, ok := s.Pop(); ok {
+						popped[g] = append(popped[g], v)
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		seen := make(map[int]bool, goroutines*items)
+		for _, values := range popped {
+			for _, v := range values {
+				Expect(seen[v]).To(BeFalse())
+				seen[v] = true
+			}
+		}
+		for v, ok := s.Pop(); ok; v, ok = s.Pop() {
+			Expect(seen[v]).To(BeFalse())
+			seen[v] = true
+		}
+		Expect(seen).To(HaveLen(goroutines * items))
+	})
+})
+
+var _ = Describe("Benchmark", Serial, func() {
+	workers := runtime.GOMAXPROCS(0)
+
+	// run calls `op` from all workers concurrently.
+	run := func(op func(i int)) {
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					op(i)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	It("Queue", func() {
+		lockFree := lockfree.NewQueue[int]()
+		locked := array.New[int](gods.WithSafe(true))
+		experiment := gmeasure.NewExperiment("Queue")
+		AddReportEntry(experiment.Name, experiment)
+		experiment.Sample(func(idx int) {
+			experiment.MeasureDuration("LockFree", func() {
+				run(func(i int) { lockFree.Enqueue(i); lockFree.Dequeue() })
+			})
+			experiment.MeasureDuration("RWMutex", func() {
+				run(func(i int) { locked.PushRight(i); locked.PopLeft() })
+			})
+		}, gmeasure.SamplingConfig{N: 200, Duration: time.Second})
+		Expect(lockFree.IsEmpty()).To(BeTrue())
+		Expect(locked.Size()).To(BeZero())
+	})
+
+	It("Stack", func() {
+		lockFree := lockfree.NewStack[int]()
+		locked := stack.New[int](gods.WithSafe(true))
+		experiment := gmeasure.NewExperiment("Stack")
+		AddReportEntry(experiment.Name, experiment)
+		experiment.Sample(func(idx int) {
+			experiment.MeasureDuration("LockFree", func() {
+				run(func(i int) { lockFree.Push(i); lockFree.Pop() })
+			})
+			experiment.MeasureDuration("RWMutex", func() {
+				run(func(i int) { locked.Push(i); locked.Pop() })
+			})
+		}, gmeasure.SamplingConfig{N: 200, Duration: time.Second})
+		Expect(lockFree.IsEmpty()).To(BeTrue())
+		Expect(locked.IsEmpty()).To(BeTrue())
+	})
+})
@@ -0,0 +1,98 @@
+package lockfree
+
+import (
+	"sync/atomic"
+)
+
+// Queue is an unbounded multi-producer multi-consumer FIFO queue,
+// which is the non-blocking algorithm of Michael and Scott built on sync/atomic.
+// It is always concurrent-safe and never locks.
+type Queue[T any] struct {
+	head atomic.Pointer[queueNode[T]] // Sentinel, head.next is the front item.
+	tail atomic.Pointer[queueNode[T]]
+	size atomic.Int64
+}
+
+// queueNode is a node of the linked list of a queue.
+type queueNode[T any] struct {
+	value T
+	next  atomic.Pointer[queueNode[T]]
+}
+
+// NewQueue creates and returns an empty queue.
+func NewQueue[T any]() *Queue[T] {
+	q := &Queue[T]{}
+	sentinel := &queueNode[T]{}
+	q.head.Store(sentinel)
+	q.tail.Store(sentinel)
+	return q
+}
+
+// Enqueue places `value` at the back of the queue.
+func (q *Queue[T]) Enqueue(value T) {
+	n := &queueNode[T]{value: value}
+	for {
+		tail := q.tail.Load()
+		next := tail.next.Load()
+		if tail != q.tail.Load() {
+			continue
+		}
+		if next != nil {
+			// The tail is lagging behind, help the other producer to swing it.
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		if tail.next.CompareAndSwap(nil, n) {
+			q.tail.CompareAndSwap(tail, n)
+			q.size.Add(1)
+			return
+		}
+	}
+}
+
+// Dequeue removes and returns the item at the front of the queue.
+// Note that if the queue is empty, the `found` is false.
+func (q *Queue[T]) Dequeue() (value T, found bool) {
+	for {
+		head := q.head.Load()
+		tail := q.tail.Load()
+		next := head.next.Load()
+		if head != q.head.Load() {
+			continue
+		}
+		if next == nil {
+			return value, false
+		}
+		if head == tail {
+			// The tail is lagging behind, help the producer to swing it.
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		value = next.value
+		if q.head.CompareAndSwap(head, next) {
+			q.size.Add(-1)
+			return value, true
+		}
+	}
+}
+
+// Peek returns the item at the front of the queue without removing it.
+// Note that if the queue is empty, the `found` is false.
+func (q *Queue[T]) Peek() (value T, found bool) {
+	next := q.head.Load().next.Load()
+	if next == nil {
+		return value, false
+	}
+	return next.value, true
+}
+
+// Size returns the number of items in the queue.
+// It is only a hint while other goroutines are using the queue.
+func (q *Queue[T]) Size() int {
+	return int(q.size.Load())
+}
+
+// IsEmpty returns true if the queue is empty, otherwise returns false.
+func (q *Queue[T]) IsEmpty() bool {
+	return q.head.Load().next.Load() == nil
+}
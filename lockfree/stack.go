@@ -0,0 +1,72 @@
+package lockfree
+
+import (
+	"sync/atomic"
+)
+
+// Stack is an unbounded LIFO stack, which is the non-blocking algorithm of Treiber
+// built on sync/atomic. It is always concurrent-safe and never locks.
+// The zero value is an empty stack ready to use.
+type Stack[T any] struct {
+	top  atomic.Pointer[stackNode[T]]
+	size atomic.Int64
+}
+
+// stackNode is a node of the linked list of a stack, which is immutable once pushed.
+type stackNode[T any] struct {
+	value T
+	next  *stackNode[T]
+}
+
+// NewStack creates and returns an empty stack.
+func NewStack[T any]() *Stack[T] {
+	return &Stack[T]{}
+}
+
+// Push places `value` at the top of the stack.
+func (s *Stack[T]) Push(value T) {
+	n := &stackNode[T]{value: value}
+	for {
+		n.next = s.top.Load()
+		if s.top.CompareAndSwap(n.next, n) {
+			s.size.Add(1)
+			return
+		}
+	}
+}
+
+// Pop removes the stack's top element and returns it.
+// Note that if the stack is empty, the `found` is false.
+func (s *Stack[T]) Pop() (value T, found bool) {
+	for {
+		top := s.top.Load()
+		if top == nil {
+			return value, false
+		}
+		if s.top.CompareAndSwap(top, top.next) {
+			s.size.Add(-1)
+			return top.value, true
+		}
+	}
+}
+
+// Peek returns the stack's top element but does not remove it.
+// Note that if the stack is empty, the `found` is false.
+func (s *Stack[T]) Peek() (value T, found bool) {
+	top := s.top.Load()
+	if top == nil {
+		return value, false
+	}
+	return top.value, true
+}
+
+// Size returns the number of elements in the stack.
+// It is only a hint while other goroutines are using the stack.
+func (s *Stack[T]) Size() int {
+	return int(s.size.Load())
+}
+
+// IsEmpty returns true if the stack is empty, otherwise returns false.
+func (s *Stack[T]) IsEmpty() bool {
+	return s.top.Load() == nil
+}
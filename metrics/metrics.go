@@ -0,0 +1,25 @@
+// Package metrics publishes the metrics of containers with expvar.
+//
+// Importing this package registers the expvar handler at /debug/vars like expvar does,
+// so it is separated from the containers.
+package metrics
+
+import (
+	"expvar"
+
+	"github.com/lazybabe/gods"
+)
+
+// Var returns an expvar.Var reporting the metrics of `provider` as JSON,
+// where the durations are in nanoseconds.
+func Var(provider gods.StatsProvider) expvar.Var {
+	return expvar.Func(func() any {
+		return provider.Stats()
+	})
+}
+
+// Publish publishes the metrics of `provider` as the expvar variable `name`.
+// It panics if `name` is already published, like expvar.Publish does.
+func Publish(name string, provider gods.StatsProvider) {
+	expvar.Publish(name, Var(provider))
+}
@@ -0,0 +1,36 @@
+package metrics_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Metrics Suite")
+}
+
+var _ = Describe("Metrics", func() {
+	It("Publish", func() {
+		a := array.New[int](gods.WithSafe(true), gods.WithMetrics(true))
+		metrics.Publish("array", a)
+		a.PushRight(1)
+		a.Get(0)
+		a.Get(1)
+
+		var stats gods.Stats
+		Expect(json.Unmarshal([]byte(expvar.Get("array").String()), &stats)).To(Succeed())
+		Expect(stats.ReadLocks).To(Equal(uint64(2)))
+		Expect(stats.WriteLocks).To(Equal(uint64(1)))
+		Expect(stats.Operations).To(Equal(map[string]uint64{"PushRight": 1, "Get": 2}))
+		Expect(func() { metrics.Publish("array", a) }).To(Panic())
+	})
+})
@@ -0,0 +1,250 @@
+package gods
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultHoldLimit is the duration after which a checked lock held is reported, see WithDebug.
+const DefaultHoldLimit = time.Second
+
+// Option configures a container at construction.
+// It is one of the values returned by the With functions, where the last option wins.
+//
+// Every container supports the locking options WithSafe, WithLocker, WithMetrics, WithDebug and
+// WithHoldLimit. The other options, and LockCopyOnWrite, are supported only by the containers which
+// document them, and a container panics if it is created with an option it does not support.
+type Option func(o *Options)
+
+// Options is the configuration of a container built from Option values.
+type Options struct {
+	// Locker is the locking strategy, which is LockNone in default.
+	Locker Locker
+	// Capacity is the number of items to preallocate room for.
+	Capacity int
+	// Comparator is the func(a, b T) int given by WithComparator, or nil.
+	Comparator any
+	// Hooks is the Hooks[T] given by WithHooks, or nil.
+	Hooks any
+	// Metrics specifies whether recording the lock and operation metrics, see Stats.
+	Metrics bool
+	// Debug specifies whether using checked locks, see WithDebug.
+	Debug bool
+	// HoldLimit is the duration after which a checked lock held is reported,
+	// which is DefaultHoldLimit if zero, and no report if negative.
+	HoldLimit time.Duration
+	// Snapshot specifies whether iterating over a snapshot of the items, see WithSnapshot.
+	Snapshot bool
+	// Features given, which are checked against the ones supported by a container, see Check.
+	features Feature
+}
+
+// Feature is an option which is not supported by every container, see Option.
+type Feature uint
+
+const (
+	// FeatureCapacity is the option WithCapacity.
+	FeatureCapacity Feature = 1 << iota
+	// FeatureComparator is the option WithComparator.
+	FeatureComparator
+	// FeatureHooks is the option WithHooks.
+	FeatureHooks
+	// FeatureSnapshot is the option WithSnapshot.
+	FeatureSnapshot
+	// FeatureCopyOnWrite is the locking strategy LockCopyOnWrite.
+	FeatureCopyOnWrite
+)
+
+// featureNames are the names of the features in the order of their bits.
+var featureNames = []string{"WithCapacity", "WithComparator", "WithHooks", "WithSnapshot", "LockCopyOnWrite"}
+
+// with returns the features with `feature` added if `given` is true, or else removed.
+func (f Feature) with(feature Feature, given bool) Feature {
+	if given {
+		return f | feature
+	}
+	return f &^ feature
+}
+
+// String returns the names of the features joined by "|".
+func (f Feature) String() string {
+	var names []string
+	for i, name := range featureNames {
+		if f&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if rest := f &^ (1<<len(featureNames) - 1); rest != 0 || len(names) == 0 {
+		names = append(names, fmt.Sprintf("Feature(%d)", uint(rest)))
+	}
+	return strings.Join(names, "|")
+}
+
+// Hooks are the callbacks of a container called when items are inserted or removed.
+// They are called while the container is locked, so they must not use the container.
+type Hooks[T any] struct {
+	// OnInsert is called with every item inserted into the container.
+	OnInsert func(value T)
+	// OnRemove is called with every item removed from the container,
+	// including the items overwritten or cleared.
+	OnRemove func(value T)
+}
+
+// WithSafe specifies whether using the container in concurrent-safety.
+// If `safe` is true, it uses LockRWMutex unless another locking strategy is selected.
+func WithSafe(safe bool) Option {
+	return func(o *Options) {
+		if !safe {
+			o.Locker = LockNone
+		} else if o.Locker == LockNone {
+			o.Locker = LockRWMutex
+		}
+	}
+}
+
+// WithLocker specifies the locking strategy of the container,
+// LockNone means the container is not concurrent-safe.
+func WithLocker(locker Locker) Option {
+	return func(o *Options) {
+		o.Locker = locker
+	}
+}
+
+// WithCapacity specifies the number of items to preallocate room for.
+func WithCapacity(capacity int) Option {
+	return func(o *Options) {
+		o.Capacity = capacity
+		o.features = o.features.with(FeatureCapacity, capacity != 0)
+	}
+}
+
+// WithComparator specifies the comparator of the items, which returns a negative number
+// if a < b, zero if a == b, or a positive number if a > b.
+func WithComparator[T any](comparator func(a, b T) int) Option {
+	return func(o *Options) {
+		if comparator == nil {
+			o.Comparator = nil
+		} else {
+			o.Comparator = comparator
+		}
+		o.features = o.features.with(FeatureComparator, comparator != nil)
+	}
+}
+
+// WithHooks specifies the callbacks called when items are inserted or removed.
+func WithHooks[T any](hooks Hooks[T]) Option {
+	return func(o *Options) {
+		o.Hooks = hooks
+		o.features = o.features.with(FeatureHooks, hooks.OnInsert != nil || hooks.OnRemove != nil)
+	}
+}
+
+// WithMetrics specifies whether recording the lock and operation metrics of the container,
+// which are reported by its Stats method. It is false in default, as the metrics cost
+// a few clock reads and atomic operations on every operation.
+func WithMetrics(enabled bool) Option {
+	return func(o *Options) {
+		o.Metrics = enabled
+	}
+}
+
+// WithDebug specifies whether using checked locks in the container, which track the goroutines
+// holding the lock, and panic on the locking which would deadlock: locking the container again
+// inside its own callbacks or hooks. The locks held longer than the hold limit are logged
+// along with the stack acquiring them, see WithHoldLimit.
+// It is false in default, unless built with the tag `godsdebug`, which enables it for all
+// the safe containers. It takes effect only if the container is concurrent-safe.
+func WithDebug(enabled bool) Option {
+	return func(o *Options) {
+		o.Debug = enabled
+	}
+}
+
+// WithHoldLimit specifies the duration after which a checked lock held is reported,
+// which is DefaultHoldLimit if zero, and no report if negative. See WithDebug.
+func WithHoldLimit(limit time.Duration) Option {
+	return func(o *Options) {
+		o.HoldLimit = limit
+	}
+}
+
+// WithSnapshot specifies whether the container iterates over a snapshot of its items,
+// so the callbacks of Each run without holding the lock, and may use or modify the container.
+// The callbacks see all the items as of the moment the iteration starts, and none of
+// the changes made during the iteration, at the cost of copying the items on every iteration.
+// It is false in default, where the container is locked for reading while the callbacks run.
+// In copy-on-write usage, the iteration never copies nor locks, whether it is true or not.
+func WithSnapshot(enabled bool) Option {
+	return func(o *Options) {
+		o.Snapshot = enabled
+		o.features = o.features.with(FeatureSnapshot, enabled)
+	}
+}
+
+// Safe returns the option of the former `safe ...bool` argument of the constructors, which is WithSafe
+// of the value given, or does nothing if there is none, so `New(safe...)` is migrated to `New(gods.Safe(safe...))`.
+// The constructors which took the bool, such as array.New, still take a single bool as well as the options.
+// It panics if more than one value is given, which the former constructors silently ignored.
+//
+// Deprecated: use WithSafe instead.
+func Safe(safe ...bool) Option {
+	if len(safe) > 1 {
+		panic(fmt.Sprintf("gods: Safe takes at most one value, got %d", len(safe)))
+	}
+	if len(safe) == 0 {
+		return func(o *Options) {}
+	}
+	return WithSafe(safe[0])
+}
+
+// NewOptions creates and returns the configuration built from `options`.
+func NewOptions(options ...Option) Options {
+	var o Options
+	for _, option := range options {
+		if option != nil {
+			option(&o)
+		}
+	}
+	return o
+}
+
+// Check panics if `o` has an option which is not in the features `supported` by `container`,
+// which names the container in the panic message, such as "array.Array".
+func (o Options) Check(container string, supported Feature) {
+	given := o.features
+	if o.Locker == LockCopyOnWrite {
+		given |= FeatureCopyOnWrite
+	}
+	if unsupported := given &^ supported; unsupported != 0 {
+		panic(fmt.Sprintf("gods: %s does not support %v", container, unsupported))
+	}
+}
+
+// ComparatorOf returns the comparator of items of type T in `o`, or nil if there is none.
+// It panics if the comparator is not of items of type T.
+func ComparatorOf[T any](o Options) func(a, b T) int {
+	if o.Comparator == nil {
+		return nil
+	}
+	comparator, ok := o.Comparator.(func(a, b T) int)
+	if !ok {
+		var zero T
+		panic(fmt.Sprintf("gods: comparator %T is not of %T", o.Comparator, zero))
+	}
+	return comparator
+}
+
+// HooksOf returns the hooks of items of type T in `o`, which are empty if there are none.
+// It panics if the hooks are not of items of type T.
+func HooksOf[T any](o Options) Hooks[T] {
+	if o.Hooks == nil {
+		return Hooks[T]{}
+	}
+	hooks, ok := o.Hooks.(Hooks[T])
+	if !ok {
+		var zero T
+		panic(fmt.Sprintf("gods: hooks %T are not of %T", o.Hooks, zero))
+	}
+	return hooks
+}
@@ -0,0 +1,161 @@
+// Package rangequery implements trees answering aggregate queries over ranges of a sequence
+// in logarithmic time: a Fenwick tree of prefix sums, and a segment tree of any associative
+// aggregate with lazy range updates.
+//
+// The ranges are half-open: the range [from, to) holds the values at the indexes from `from`
+// included to `to` excluded, like the slice expressions do.
+package rangequery
+
+import (
+	"fmt"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// Number is a constraint that permits any integer or floating-point type.
+type Number interface {
+	Integer | ~float32 | ~float64
+}
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Fenwick is a Fenwick tree, also known as a binary indexed tree, of a sequence of numbers of type T,
+// which updates a value and sums a range in O(log n).
+type Fenwick[T Number] struct {
+	mu rwmutex.RWMutex
+	// Partial sums, where tree[i] is the sum of the values in [i&(i+1), i].
+	tree []T
+}
+
+// NewFenwick creates and returns a tree of `n` zeros.
+// The parameter `options` is used to configure the tree, see gods.Option.
+// It is not concurrent-safe in default.
+func NewFenwick[T Number](n int, options ...gods.Option) *Fenwick[T] {
+	if n < 0 {
+		n = 0
+	}
+	return &Fenwick[T]{mu: rwmutex.CreateWithoutCOW("rangequery.Fenwick", options), tree: make([]T, n)}
+}
+
+// NewFenwickFrom creates and returns a tree of the values of `a` in O(n).
+// The parameter `options` is used to configure the tree, see gods.Option.
+// It is not concurrent-safe in default.
+func NewFenwickFrom[T Number](a *array.Array[T], options ...gods.Option) *Fenwick[T] {
+	tree := a.Slice()
+	for i := range tree {
+		if j := i | (i + 1); j < len(tree) {
+			tree[j] += tree[i]
+		}
+	}
+	return &Fenwick[T]{mu: rwmutex.CreateWithoutCOW("rangequery.Fenwick", options), tree: tree}
+}
+
+// Add adds `delta` to the value at `index`.
+func (f *Fenwick[T]) Add(index int, delta T) error {
+	f.mu.Count("Add")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.doAddWithoutLock(index, delta)
+}
+
+// doAddWithoutLock adds `delta` to the value at `index` without lock.
+func (f *Fenwick[T]) doAddWithoutLock(index int, delta T) error {
+	if index < 0 || index >= len(f.tree) {
+		return fmt.Errorf("index %d out of tree range %d", index, len(f.tree))
+	}
+	for i := index; i < len(f.tree); i |= i + 1 {
+		f.tree[i] += delta
+	}
+	return nil
+}
+
+// Set sets the value at `index` to `value`.
+func (f *Fenwick[T]) Set(index int, value T) error {
+	f.mu.Count("Set")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if index < 0 || index >= len(f.tree) {
+		return fmt.Errorf("index %d out of tree range %d", index, len(f.tree))
+	}
+	return f.doAddWithoutLock(index, value-f.prefixSum(index+1)+f.prefixSum(index))
+}
+
+// Get returns the value at `index`.
+// Note that if `index` is out of range, the `found` is false.
+func (f *Fenwick[T]) Get(index int) (value T, found bool) {
+	f.mu.Count("Get")
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if index < 0 || index >= len(f.tree) {
+		return
+	}
+	return f.prefixSum(index+1) - f.prefixSum(index), true
+}
+
+// PrefixSum returns the sum of the values in [0, to),
+// where `to` is clamped to the range of the tree.
+func (f *Fenwick[T]) PrefixSum(to int) T {
+	f.mu.Count("PrefixSum")
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.prefixSum(clamp(to, len(f.tree)))
+}
+
+// RangeSum returns the sum of the values in [from, to), which is 0 if it is empty,
+// where `from` and `to` are clamped to the range of the tree.
+func (f *Fenwick[T]) RangeSum(from, to int) T {
+	f.mu.Count("RangeSum")
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	from, to = clamp(from, len(f.tree)), clamp(to, len(f.tree))
+	if from >= to {
+		return 0
+	}
+	return f.prefixSum(to) - f.prefixSum(from)
+}
+
+// Size returns the number of values of the tree.
+func (f *Fenwick[T]) Size() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.tree)
+}
+
+// Slice returns the values of the tree.
+func (f *Fenwick[T]) Slice() []T {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	values := append([]T(nil), f.tree...)
+	// Undo the partial sums from the end, as every one only covers values before it.
+	for i := len(values) - 1; i >= 0; i-- {
+		if j := i | (i + 1); j < len(values) {
+			values[j] -= values[i]
+		}
+	}
+	return values
+}
+
+// Stats returns a snapshot of the metrics of the tree created with gods.WithMetrics(true).
+func (f *Fenwick[T]) Stats() gods.Stats {
+	return f.mu.Stats()
+}
+
+// prefixSum returns the sum of the values in [0, to), where `to` is in the range of the tree.
+func (f *Fenwick[T]) prefixSum(to int) T {
+	var sum T
+	for i := to - 1; i >= 0; i = i&(i+1) - 1 {
+		sum += f.tree[i]
+	}
+	return sum
+}
+
+// clamp returns `index` clamped to [0, n].
+func clamp(index, n int) int {
+	return min(max(index, 0), n)
+}
@@ -0,0 +1,59 @@
+package rangequery
+
+// Sum returns the operations of a segment tree of sums, which adds an update to every value of a range.
+func Sum[T Number]() Operations[T, T] {
+	return Operations[T, T]{
+		Combine: func(left, right T) T { return left + right },
+		Apply:   func(value T, update T, length int) T { return value + update*T(length) },
+		Compose: func(first, second T) T { return first + second },
+	}
+}
+
+// Min returns the operations of a segment tree of minimums, which adds an update to every value of a range.
+func Min[T Number]() Operations[T, T] {
+	return Operations[T, T]{
+		Combine: func(left, right T) T { return min(left, right) },
+		Apply:   func(value T, update T, _ int) T { return value + update },
+		Compose: func(first, second T) T { return first + second },
+	}
+}
+
+// Max returns the operations of a segment tree of maximums, which adds an update to every value of a range.
+func Max[T Number]() Operations[T, T] {
+	return Operations[T, T]{
+		Combine: func(left, right T) T { return max(left, right) },
+		Apply:   func(value T, update T, _ int) T { return value + update },
+		Compose: func(first, second T) T { return first + second },
+	}
+}
+
+// GCD returns the operations of a segment tree of greatest common divisors, which sets every value
+// of a range to an update. The divisor of several values is not negative, and the one of a value is itself.
+func GCD[T Integer]() Operations[T, T] {
+	return Operations[T, T]{
+		Combine: gcd[T],
+		Apply: func(_ T, update T, length int) T {
+			if length == 1 {
+				return update
+			}
+			return abs(update)
+		},
+		Compose: func(_, second T) T { return second },
+	}
+}
+
+// gcd returns the greatest common divisor of `a` and `b`, which is not negative.
+func gcd[T Integer](a, b T) T {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return abs(a)
+}
+
+// abs returns the absolute value of `v`.
+func abs[T Integer](v T) T {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,234 @@
+package rangequery_test
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/rangequery"
+)
+
+func TestRangeQuery(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RangeQuery Suite")
+}
+
+// mustQuery returns `result` and checks that it is found.
+func mustQuery[T any](result T, found bool) T {
+	ExpectWithOffset(1, found).To(BeTrue())
+	return result
+}
+
+var _ = Describe("Fenwick", func() {
+	It("Add, Set and sums", func() {
+		f := rangequery.NewFenwickFrom(array.NewFrom([]int{3, 1, 4, 1, 5, 9, 2, 6}))
+		Expect(f.Size()).To(Equal(8))
+		Expect(f.Slice()).To(Equal([]int{3, 1, 4, 1, 5, 9, 2, 6}))
+		Expect(f.PrefixSum(0)).To(Equal(0))
+		Expect(f.PrefixSum(3)).To(Equal(8))
+		Expect(f.PrefixSum(8)).To(Equal(31))
+		Expect(f.PrefixSum(100)).To(Equal(31))
+		Expect(f.PrefixSum(-1)).To(Equal(0))
+		Expect(f.RangeSum(2, 6)).To(Equal(19))
+		Expect(f.RangeSum(-5, 2)).To(Equal(4))
+		Expect(f.RangeSum(6, 2)).To(BeZero())
+		Expect(mustQuery(f.Get(5))).To(Equal(9))
+		_, found := f.Get(8)
+		Expect(found).To(BeFalse())
+
+		Expect(f.Add(2, 10)).To(Succeed())
+		Expect(f.Set(5, 0)).To(Succeed())
+		Expect(f.Slice()).To(Equal([]int{3, 1, 14, 1, 5, 0, 2, 6}))
+		Expect(f.RangeSum(2, 6)).To(Equal(20))
+		Expect(f.Add(8, 1)).To(MatchError("index 8 out of tree range 8"))
+		Expect(f.Set(-1, 1)).To(MatchError("index -1 out of tree range 8"))
+
+		e := rangequery.NewFenwick[float64](3)
+		Expect(e.Slice()).To(Equal([]float64{0, 0, 0}))
+		Expect(e.Add(1, 0.5)).To(Succeed())
+		Expect(e.RangeSum(0, 3)).To(Equal(0.5))
+		Expect(rangequery.NewFenwick[int](-1).Size()).To(BeZero())
+	})
+
+	It("matches a naive model after random operations", func() {
+		r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+		model := make([]int64, 1+r.Intn(300))
+		for i := range model {
+			model[i] = int64(r.Intn(2000) - 1000)
+		}
+		f := rangequery.NewFenwickFrom(array.NewFrom(model))
+		for n := 0; n < 2000; n++ {
+			i, v := r.Intn(len(model)), int64(r.Intn(2000)-1000)
+			if r.Intn(2) == 0 {
+				Expect(f.Add(i, v)).To(Succeed())
+				model[i] += v
+			} else {
+				Expect(f.Set(i, v)).To(Succeed())
+				model[i] = v
+			}
+			from, to := r.Intn(len(model)+1), r.Intn(len(model)+1)
+			var sum int64
+			for j := from; j < to; j++ {
+				sum += model[j]
+			}
+			Expect(f.RangeSum(from, to)).To(Equal(sum))
+		}
+		Expect(f.Slice()).To(Equal(model))
+	})
+})
+
+var _ = Describe("SegmentTree", func() {
+	It("Sum", func() {
+		t := rangequery.NewSegmentTreeFrom(array.NewFrom([]int{3, 1, 4, 1, 5, 9, 2, 6}), rangequery.Sum[int]())
+		Expect(t.Size()).To(Equal(8))
+		Expect(mustQuery(t.Query(0, 8))).To(Equal(31))
+		Expect(mustQuery(t.Query(2, 6))).To(Equal(19))
+		Expect(mustQuery(t.Query(-3, 100))).To(Equal(31))
+		_, found := t.Query(4, 4)
+		Expect(found).To(BeFalse())
+
+		t.Update(1, 5, 10)
+		Expect(t.Slice()).To(Equal([]int{3, 11, 14, 11, 15, 9, 2, 6}))
+		Expect(mustQuery(t.Query(0, 3))).To(Equal(28))
+		t.Update(0, 8, -1)
+		t.Update(3, 4, 100)
+		Expect(mustQuery(t.Query(2, 5))).To(Equal(13 + 110 + 14))
+		Expect(t.Set(3, 0)).To(Succeed())
+		Expect(mustQuery(t.Get(3))).To(Equal(0))
+		Expect(mustQuery(t.Get(4))).To(Equal(14))
+		_, found = t.Get(8)
+		Expect(found).To(BeFalse())
+		_, found = t.Get(-1)
+		Expect(found).To(BeFalse())
+		Expect(t.Set(8, 0)).To(MatchError("index 8 out of tree range 8"))
+	})
+
+	It("Min, Max and GCD", func() {
+		values := array.NewFrom([]int{5, -2, 7, 3, 8, 1})
+		mins := rangequery.NewSegmentTreeFrom(values, rangequery.Min[int]())
+		Expect(mustQuery(mins.Query(2, 6))).To(Equal(1))
+		mins.Update(4, 6, -10)
+		Expect(mustQuery(mins.Query(2, 6))).To(Equal(-9))
+		Expect(mustQuery(mins.Query(0, 3))).To(Equal(-2))
+
+		maxs := rangequery.NewSegmentTreeFrom(values, rangequery.Max[int]())
+		Expect(mustQuery(maxs.Query(0, 4))).To(Equal(7))
+		maxs.Update(0, 2, 5)
+		Expect(mustQuery(maxs.Query(0, 4))).To(Equal(10))
+
+		gcds := rangequery.NewSegmentTreeFrom(array.NewFrom([]int{12, 18, -24, 7, 14}), rangequery.GCD[int]())
+		Expect(mustQuery(gcds.Query(0, 3))).To(Equal(6))
+		Expect(mustQuery(gcds.Query(0, 4))).To(Equal(1))
+		Expect(mustQuery(gcds.Query(3, 5))).To(Equal(7))
+		gcds.Update(1, 4, -9)
+		Expect(gcds.Slice()).To(Equal([]int{12, -9, -9, -9, 14}))
+		Expect(mustQuery(gcds.Query(0, 4))).To(Equal(3))
+		Expect(mustQuery(gcds.Query(1, 3))).To(Equal(9))
+	})
+
+	It("Custom operations", func() {
+		// Products of 2x2 matrices, which are associative but not commutative, without range updates.
+		type matrix [4]int
+		mul := func(a, b matrix) matrix {
+			return matrix{a[0]*b[0] + a[1]*b[2], a[0]*b[1] + a[1]*b[3], a[2]*b[0] + a[3]*b[2], a[2]*b[1] + a[3]*b[3]}
+		}
+		fib := matrix{1, 1, 1, 0}
+		t := rangequery.NewSegmentTree(10, rangequery.Operations[matrix, struct{}]{Combine: mul})
+		for i := 0; i < 10; i++ {
+			Expect(t.Set(i, fib)).To(Succeed())
+		}
+		Expect(mustQuery(t.Query(0, 10))[1]).To(Equal(55))
+		Expect(t.Set(0, matrix{0, 1, 1, 0})).To(Succeed())
+		Expect(mustQuery(t.Query(0, 2))).To(Equal(matrix{1, 0, 1, 1}))
+		Expect(func() { t.Update(0, 1, struct{}{}) }).To(Panic())
+		Expect(func() { rangequery.NewSegmentTree[int, int](1, rangequery.Operations[int, int]{}) }).To(Panic())
+
+		empty := rangequery.NewSegmentTree(0, rangequery.Sum[int]())
+		_, found := empty.Query(0, 1)
+		Expect(found).To(BeFalse())
+		empty.Update(0, 1, 1)
+		Expect(empty.Slice()).To(BeEmpty())
+	})
+
+	DescribeTable("matches a naive model after random operations",
+		func(ops rangequery.Operations[int64, int64], assign bool, combine func(a, b int64) int64) {
+			r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+			model := make([]int64, 1+r.Intn(200))
+			for i := range model {
+				model[i] = int64(r.Intn(200) - 100)
+			}
+			t := rangequery.NewSegmentTreeFrom(array.NewFrom(model), ops)
+			for n := 0; n < 2000; n++ {
+				from, to, v := r.Intn(len(model)+1), r.Intn(len(model)+1), int64(r.Intn(200)-100)
+				switch r.Intn(3) {
+				case 0:
+					i := r.Intn(len(model))
+					Expect(t.Set(i, v)).To(Succeed())
+					model[i] = v
+				case 1:
+					t.Update(from, to, v)
+					for i := from; i < to; i++ {
+						if assign {
+							model[i] = v
+						} else {
+							model[i] += v
+						}
+					}
+				}
+				from, to = r.Intn(len(model)), r.Intn(len(model)+1)
+				result, found := t.Query(from, to)
+				Expect(found).To(Equal(from < to))
+				if from < to {
+					want := model[from]
+					for i := from + 1; i < to; i++ {
+						want = combine(want, model[i])
+					}
+					Expect(result).To(Equal(want), "[%d, %d)", from, to)
+				}
+			}
+			Expect(t.Slice()).To(Equal(model))
+		},
+		Entry("sum", rangequery.Sum[int64](), false, func(a, b int64) int64 { return a + b }),
+		Entry("min", rangequery.Min[int64](), false, func(a, b int64) int64 { return min(a, b) }),
+		Entry("max", rangequery.Max[int64](), false, func(a, b int64) int64 { return max(a, b) }),
+		Entry("gcd", rangequery.GCD[int64](), true, func(a, b int64) int64 {
+			for b != 0 {
+				a, b = b, a%b
+			}
+			return max(a, -a)
+		}),
+	)
+
+	It("Safe", func() {
+		Expect(func() { rangequery.NewSegmentTree(10, rangequery.Sum[int](), gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: rangequery.SegmentTree does not support LockCopyOnWrite"))
+		Expect(func() { rangequery.NewFenwick[int](10, gods.WithCapacity(10)) }).
+			To(PanicWith("gods: rangequery.Fenwick does not support WithCapacity"))
+		t := rangequery.NewSegmentTree(1000, rangequery.Sum[int](), gods.WithSafe(true), gods.WithMetrics(true),
+			gods.WithLocker(gods.LockSpin))
+		f := rangequery.NewFenwick[int](1000, gods.WithSafe(true))
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					t.Update(i, i+500, 1)
+					t.Query(0, 1000)
+					Expect(f.Add(i, 1)).To(Succeed())
+					f.PrefixSum(1000)
+				}
+			}()
+		}
+		wg.Wait()
+		Expect(mustQuery(t.Query(0, 1000))).To(Equal(4 * 500 * 500))
+		Expect(f.PrefixSum(1000)).To(Equal(2000))
+		Expect(t.Stats().Operations).To(HaveKeyWithValue("Update", uint64(2000)))
+	})
+})
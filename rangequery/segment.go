@@ -0,0 +1,245 @@
+package rangequery
+
+import (
+	"fmt"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// Operations are the operations of a segment tree of values of type T with updates of type U.
+type Operations[T, U any] struct {
+	// Combine returns the aggregate of two adjacent ranges from theirs, it must be associative.
+	Combine func(left, right T) T
+	// Apply returns the aggregate of a range of `length` values after `update` is applied to every one,
+	// from the aggregate `value` before. It may be nil if the tree is not updated by ranges.
+	Apply func(value T, update U, length int) T
+	// Compose returns the update which is `first` then `second` applied.
+	// It may be nil if the tree is not updated by ranges.
+	Compose func(first, second U) U
+}
+
+// SegmentTree is a segment tree of a sequence of values of type T, which aggregates a range by an
+// associative operation, such as a sum, a minimum or a greatest common divisor, in O(log n).
+// It also updates a range by updates of type U in O(log n), which are applied to the values lazily.
+type SegmentTree[T, U any] struct {
+	mu  rwmutex.RWMutex
+	ops Operations[T, U]
+	n   int
+	// Aggregates of the segments, where the segment 0 is [0, n),
+	// and the children of the segment i are the segments 2i+1 and 2i+2 halving it.
+	tree []T
+	// Updates of the segments not applied to their children yet, which are the ones pending.
+	lazy    []U
+	pending []bool
+}
+
+// NewSegmentTree creates and returns a tree of `n` zero values with the operations `ops`.
+// The parameter `options` is used to configure the tree, see gods.Option.
+// It is not concurrent-safe in default.
+func NewSegmentTree[T, U any](n int, ops Operations[T, U], options ...gods.Option) *SegmentTree[T, U] {
+	if n < 0 {
+		n = 0
+	}
+	return newSegmentTree(make([]T, n), ops, options)
+}
+
+// NewSegmentTreeFrom creates and returns a tree of the values of `a` with the operations `ops` in O(n).
+// The parameter `options` is used to configure the tree, see gods.Option.
+// It is not concurrent-safe in default.
+func NewSegmentTreeFrom[T comparable, U any](a *array.Array[T], ops Operations[T, U],
+	options ...gods.Option) *SegmentTree[T, U] {
+	return newSegmentTree(a.Slice(), ops, options)
+}
+
+// newSegmentTree creates and returns a tree of `values`.
+func newSegmentTree[T, U any](values []T, ops Operations[T, U], opts []gods.Option) *SegmentTree[T, U] {
+	if ops.Combine == nil {
+		panic("rangequery: nil Combine")
+	}
+	t := &SegmentTree[T, U]{mu: rwmutex.CreateWithoutCOW("rangequery.SegmentTree", opts), ops: ops, n: len(values)}
+	if t.n > 0 {
+		// A segment tree of n values has less than 4n segments.
+		t.tree = make([]T, 4*t.n)
+		t.lazy = make([]U, 4*t.n)
+		t.pending = make([]bool, 4*t.n)
+		t.build(0, 0, t.n, values)
+	}
+	return t
+}
+
+// Query returns the aggregate of the values in [from, to),
+// where `from` and `to` are clamped to the range of the tree.
+// Note that if the range is empty, the `found` is false.
+func (t *SegmentTree[T, U]) Query(from, to int) (result T, found bool) {
+	t.mu.Count("Query")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	from, to = clamp(from, t.n), clamp(to, t.n)
+	if from >= to {
+		return
+	}
+	var none U
+	return t.query(0, 0, t.n, from, to, none, false), true
+}
+
+// Get returns the value at `index`.
+// Note that if `index` is out of range, the `found` is false.
+func (t *SegmentTree[T, U]) Get(index int) (value T, found bool) {
+	if index < 0 {
+		return
+	}
+	return t.Query(index, index+1)
+}
+
+// Set sets the value at `index` to `value`.
+func (t *SegmentTree[T, U]) Set(index int, value T) error {
+	t.mu.Count("Set")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if index < 0 || index >= t.n {
+		return fmt.Errorf("index %d out of tree range %d", index, t.n)
+	}
+	t.set(0, 0, t.n, index, value)
+	return nil
+}
+
+// Update applies `update` to the values in [from, to),
+// where `from` and `to` are clamped to the range of the tree.
+// It panics if the operations of the tree have no Apply or Compose.
+func (t *SegmentTree[T, U]) Update(from, to int, update U) {
+	t.mu.Count("Update")
+	if t.ops.Apply == nil || t.ops.Compose == nil {
+		panic("rangequery: nil Apply or Compose")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	from, to = clamp(from, t.n), clamp(to, t.n)
+	if from < to {
+		t.update(0, 0, t.n, from, to, update)
+	}
+}
+
+// Size returns the number of values of the tree.
+func (t *SegmentTree[T, U]) Size() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.n
+}
+
+// Slice returns the values of the tree, with all the updates applied.
+func (t *SegmentTree[T, U]) Slice() []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	values := make([]T, t.n)
+	for i := range values {
+		var none U
+		values[i] = t.query(0, 0, t.n, i, i+1, none, false)
+	}
+	return values
+}
+
+// Stats returns a snapshot of the metrics of the tree created with gods.WithMetrics(true).
+func (t *SegmentTree[T, U]) Stats() gods.Stats {
+	return t.mu.Stats()
+}
+
+// build builds the segment `i` of [lo, hi) from `values`.
+func (t *SegmentTree[T, U]) build(i, lo, hi int, values []T) {
+	if hi-lo == 1 {
+		t.tree[i] = values[lo]
+		return
+	}
+	mid := lo + (hi-lo)/2
+	t.build(2*i+1, lo, mid, values)
+	t.build(2*i+2, mid, hi, values)
+	t.tree[i] = t.ops.Combine(t.tree[2*i+1], t.tree[2*i+2])
+}
+
+// query returns the aggregate of the values in [from, to) within the segment `i` of [lo, hi),
+// after `update` is applied to them if `updated` is true, which is the composition of the updates
+// pending in the ancestors of the segment. It does not change the tree, so it only needs the read lock.
+func (t *SegmentTree[T, U]) query(i, lo, hi, from, to int, update U, updated bool) T {
+	if from <= lo && hi <= to {
+		if updated {
+			return t.ops.Apply(t.tree[i], update, hi-lo)
+		}
+		return t.tree[i]
+	}
+	// The update pending in the segment is older than the ones of its ancestors.
+	if t.pending[i] {
+		if updated {
+			update = t.ops.Compose(t.lazy[i], update)
+		} else {
+			update, updated = t.lazy[i], true
+		}
+	}
+	mid := lo + (hi-lo)/2
+	switch {
+	case to <= mid:
+		return t.query(2*i+1, lo, mid, from, to, update, updated)
+	case from >= mid:
+		return t.query(2*i+2, mid, hi, from, to, update, updated)
+	}
+	return t.ops.Combine(t.query(2*i+1, lo, mid, from, to, update, updated),
+		t.query(2*i+2, mid, hi, from, to, update, updated))
+}
+
+// set sets the value at `index` within the segment `i` of [lo, hi) to `value`.
+func (t *SegmentTree[T, U]) set(i, lo, hi, index int, value T) {
+	if hi-lo == 1 {
+		t.tree[i] = value
+		return
+	}
+	t.push(i, lo, hi)
+	mid := lo + (hi-lo)/2
+	if index < mid {
+		t.set(2*i+1, lo, mid, index, value)
+	} else {
+		t.set(2*i+2, mid, hi, index, value)
+	}
+	t.tree[i] = t.ops.Combine(t.tree[2*i+1], t.tree[2*i+2])
+}
+
+// update applies `update` to the values in [from, to) within the segment `i` of [lo, hi).
+func (t *SegmentTree[T, U]) update(i, lo, hi, from, to int, update U) {
+	if from <= lo && hi <= to {
+		t.apply(i, lo, hi, update)
+		return
+	}
+	t.push(i, lo, hi)
+	mid := lo + (hi-lo)/2
+	if from < mid {
+		t.update(2*i+1, lo, mid, from, to, update)
+	}
+	if to > mid {
+		t.update(2*i+2, mid, hi, from, to, update)
+	}
+	t.tree[i] = t.ops.Combine(t.tree[2*i+1], t.tree[2*i+2])
+}
+
+// apply applies `update` to the segment `i` of [lo, hi), and keeps it pending for its children.
+func (t *SegmentTree[T, U]) apply(i, lo, hi int, update U) {
+	t.tree[i] = t.ops.Apply(t.tree[i], update, hi-lo)
+	if hi-lo == 1 {
+		return
+	}
+	if t.pending[i] {
+		t.lazy[i] = t.ops.Compose(t.lazy[i], update)
+	} else {
+		t.lazy[i], t.pending[i] = update, true
+	}
+}
+
+// push applies the update pending in the segment `i` of [lo, hi) to its children.
+func (t *SegmentTree[T, U]) push(i, lo, hi int) {
+	if !t.pending[i] {
+		return
+	}
+	mid := lo + (hi-lo)/2
+	t.apply(2*i+1, lo, mid, t.lazy[i])
+	t.apply(2*i+2, mid, hi, t.lazy[i])
+	var none U
+	t.lazy[i], t.pending[i] = none, false
+}
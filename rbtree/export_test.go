@@ -0,0 +1,54 @@
+package rbtree
+
+import "fmt"
+
+// Check checks the tree against the properties of the binary search and red-black trees,
+// it returns an error describing the first violation.
+func (t *Tree[K, V]) Check() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if colorOf(t.root) != black {
+		return fmt.Errorf("red root")
+	}
+	if t.root != nil && t.root.parent != nil {
+		return fmt.Errorf("root has a parent")
+	}
+	size, _, err := t.check(t.root)
+	if err == nil && size != t.size {
+		err = fmt.Errorf("size %d of %d nodes", t.size, size)
+	}
+	return err
+}
+
+// check checks the subtree rooted at `n`, it returns the number of its nodes and its black height.
+func (t *Tree[K, V]) check(n *node[K, V]) (size int, height int, err error) {
+	if n == nil {
+		return 0, 1, nil
+	}
+	for _, child := range []*node[K, V]{n.left, n.right} {
+		if child != nil && child.parent != n {
+			return 0, 0, fmt.Errorf("node %v has a child %v of another parent", n.key, child.key)
+		}
+	}
+	if n.left != nil && t.comparator(n.left.key, n.key) >= 0 || n.right != nil && t.comparator(n.right.key, n.key) <= 0 {
+		return 0, 0, fmt.Errorf("node %v is out of order", n.key)
+	}
+	if n.color == red && (colorOf(n.left) == red || colorOf(n.right) == red) {
+		return 0, 0, fmt.Errorf("red node %v has a red child", n.key)
+	}
+	leftSize, leftHeight, err := t.check(n.left)
+	if err != nil {
+		return 0, 0, err
+	}
+	rightSize, rightHeight, err := t.check(n.right)
+	if err != nil {
+		return 0, 0, err
+	}
+	if leftHeight != rightHeight {
+		return 0, 0, fmt.Errorf("node %v has black heights %d and %d", n.key, leftHeight, rightHeight)
+	}
+	if n.color == black {
+		leftHeight++
+	}
+	return leftSize + rightSize + 1, leftHeight, nil
+}
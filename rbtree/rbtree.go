@@ -0,0 +1,656 @@
+// Package rbtree implements a red-black tree, a balanced binary search tree
+// which keeps the keys sorted and rebalances in O(1) rotations on every write.
+package rbtree
+
+import (
+	"fmt"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// color is the color of a node, a nil node is black.
+type color bool
+
+const (
+	red   color = false
+	black color = true
+)
+
+// node is a node of the tree.
+type node[K, V any] struct {
+	key                 K
+	value               V
+	color               color
+	left, right, parent *node[K, V]
+}
+
+// Tree is a sorted map of keys to values, based on a red-black tree.
+// It looks up, inserts and removes in O(log n), and iterates the keys in ascending or descending order.
+type Tree[K, V any] struct {
+	mu         rwmutex.RWMutex
+	root       *node[K, V]
+	size       int
+	comparator func(a, b K) int
+}
+
+// New creates and returns an empty tree of keys sorted by `comparator`, which returns a negative number
+// if a < b, zero if a == b, or a positive number if a > b. See gods.Compare for the ordered types.
+// The parameter `options` is used to configure the tree, see gods.Option.
+// It is not concurrent-safe in default.
+func New[K, V any](comparator func(a, b K) int, options ...gods.Option) *Tree[K, V] {
+	if comparator == nil {
+		panic("rbtree: nil comparator")
+	}
+	return &Tree[K, V]{
+		mu:         rwmutex.CreateWithoutCOW("rbtree.Tree", options),
+		comparator: comparator,
+	}
+}
+
+// NewFrom creates and returns a tree of keys sorted by `comparator` with the items of `data`.
+// The parameter `options` is used to configure the tree, see gods.Option.
+// It is not concurrent-safe in default.
+func NewFrom[K comparable, V any](data map[K]V, comparator func(a, b K) int, options ...gods.Option) *Tree[K, V] {
+	t := New[K, V](comparator, options...)
+	for k, v := range data {
+		t.doPutWithoutLock(k, v)
+	}
+	return t
+}
+
+// Put sets `value` for `key`, it replaces the value if `key` is already in the tree.
+func (t *Tree[K, V]) Put(key K, value V) {
+	t.mu.Count("Put")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.doPutWithoutLock(key, value)
+}
+
+// PutIfAbsent sets `value` for `key` only if `key` is not in the tree,
+// and reports whether `value` is set.
+func (t *Tree[K, V]) PutIfAbsent(key K, value V) bool {
+	t.mu.Count("PutIfAbsent")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.lookup(key) != nil {
+		return false
+	}
+	t.doPutWithoutLock(key, value)
+	return true
+}
+
+// PutAll sets `value` for every key of `keys` atomically,
+// it replaces the values of the keys already in the tree.
+func (t *Tree[K, V]) PutAll(keys []K, value V) {
+	t.mu.Count("PutAll")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range keys {
+		t.doPutWithoutLock(key, value)
+	}
+}
+
+// ReplaceAll replaces all items of the tree with `keys`, setting `value` for every one, atomically.
+func (t *Tree[K, V]) ReplaceAll(keys []K, value V) {
+	t.mu.Count("ReplaceAll")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.root, t.size = nil, 0
+	for _, key := range keys {
+		t.doPutWithoutLock(key, value)
+	}
+}
+
+// doPutWithoutLock sets `value` for `key` without locking.
+func (t *Tree[K, V]) doPutWithoutLock(key K, value V) {
+	var parent *node[K, V]
+	n, cmp := t.root, 0
+	for n != nil {
+		parent = n
+		cmp = t.comparator(key, n.key)
+		switch {
+		case cmp < 0:
+			n = n.left
+		case cmp > 0:
+			n = n.right
+		default:
+			n.value = value
+			return
+		}
+	}
+	n = &node[K, V]{key: key, value: value, color: red, parent: parent}
+	switch {
+	case parent == nil:
+		t.root = n
+	case cmp < 0:
+		parent.left = n
+	default:
+		parent.right = n
+	}
+	t.size++
+	t.insertFixup(n)
+}
+
+// Get returns the value of `key`.
+// If `key` is not in the tree, the `found` is false.
+func (t *Tree[K, V]) Get(key K) (value V, found bool) {
+	t.mu.Count("Get")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if n := t.lookup(key); n != nil {
+		return n.value, true
+	}
+	return
+}
+
+// Contains checks whether `key` is in the tree.
+func (t *Tree[K, V]) Contains(key K) bool {
+	_, found := t.Get(key)
+	return found
+}
+
+// Remove deletes `key` from the tree and returns its value.
+// If `key` is not in the tree, the `found` is false.
+func (t *Tree[K, V]) Remove(key K) (value V, found bool) {
+	t.mu.Count("Remove")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := t.lookup(key)
+	if n == nil {
+		return
+	}
+	t.delete(n)
+	return n.value, true
+}
+
+// RemoveAll deletes every key of `keys` from the tree atomically,
+// and returns the number of the deleted keys.
+func (t *Tree[K, V]) RemoveAll(keys ...K) (removed int) {
+	t.mu.Count("RemoveAll")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range keys {
+		if n := t.lookup(key); n != nil {
+			t.delete(n)
+			removed++
+		}
+	}
+	return
+}
+
+// Size returns the number of keys in the tree.
+func (t *Tree[K, V]) Size() int {
+	t.mu.Count("Size")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.size
+}
+
+// IsEmpty checks whether the tree is empty.
+func (t *Tree[K, V]) IsEmpty() bool {
+	return t.Size() == 0
+}
+
+// Clear deletes all keys of the tree.
+func (t *Tree[K, V]) Clear() {
+	t.mu.Count("Clear")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.root, t.size = nil, 0
+}
+
+// Min returns the smallest key of the tree and its value.
+// Note that if the tree is empty, the `found` is false.
+func (t *Tree[K, V]) Min() (key K, value V, found bool) {
+	t.mu.Count("Min")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return entry(minimum(t.root))
+}
+
+// Max returns the largest key of the tree and its value.
+// Note that if the tree is empty, the `found` is false.
+func (t *Tree[K, V]) Max() (key K, value V, found bool) {
+	t.mu.Count("Max")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return entry(maximum(t.root))
+}
+
+// Floor returns the largest key less than or equal to `key` and its value.
+// Note that if there is no such key, the `found` is false.
+func (t *Tree[K, V]) Floor(key K) (floor K, value V, found bool) {
+	t.mu.Count("Floor")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return entry(t.floor(key))
+}
+
+// Ceiling returns the smallest key greater than or equal to `key` and its value.
+// Note that if there is no such key, the `found` is false.
+func (t *Tree[K, V]) Ceiling(key K) (ceiling K, value V, found bool) {
+	t.mu.Count("Ceiling")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return entry(t.ceiling(key))
+}
+
+// Lower returns the largest key less than `key` and its value.
+// Note that if there is no such key, the `found` is false.
+func (t *Tree[K, V]) Lower(key K) (lower K, value V, found bool) {
+	t.mu.Count("Lower")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	n := t.floor(key)
+	if n != nil && t.comparator(n.key, key) == 0 {
+		n = predecessor(n)
+	}
+	return entry(n)
+}
+
+// Higher returns the smallest key greater than `key` and its value.
+// Note that if there is no such key, the `found` is false.
+func (t *Tree[K, V]) Higher(key K) (higher K, value V, found bool) {
+	t.mu.Count("Higher")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	n := t.ceiling(key)
+	if n != nil && t.comparator(n.key, key) == 0 {
+		n = successor(n)
+	}
+	return entry(n)
+}
+
+// PollFirst removes the smallest key of the tree and returns it with its value.
+// Note that if the tree is empty, the `found` is false.
+func (t *Tree[K, V]) PollFirst() (key K, value V, found bool) {
+	t.mu.Count("PollFirst")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := minimum(t.root)
+	if n != nil {
+		t.delete(n)
+	}
+	return entry(n)
+}
+
+// PollLast removes the largest key of the tree and returns it with its value.
+// Note that if the tree is empty, the `found` is false.
+func (t *Tree[K, V]) PollLast() (key K, value V, found bool) {
+	t.mu.Count("PollLast")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := maximum(t.root)
+	if n != nil {
+		t.delete(n)
+	}
+	return entry(n)
+}
+
+// Ascend calls `fn` on every item of the tree in ascending order of the keys,
+// if `fn` returns true then continue iterating; or false to stop.
+// The tree is locked for reading while `fn` runs, so `fn` must not modify the tree.
+func (t *Tree[K, V]) Ascend(fn func(key K, value V) bool) {
+	t.mu.Count("Ascend")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for n := minimum(t.root); n != nil && fn(n.key, n.value); n = successor(n) {
+	}
+}
+
+// AscendFrom calls `fn` on every item of the tree whose key is greater than or equal to `from`
+// in ascending order of the keys, if `fn` returns true then continue iterating; or false to stop.
+// The tree is locked for reading while `fn` runs, so `fn` must not modify the tree.
+func (t *Tree[K, V]) AscendFrom(from K, fn func(key K, value V) bool) {
+	t.mu.Count("AscendFrom")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for n := t.ceiling(from); n != nil && fn(n.key, n.value); n = successor(n) {
+	}
+}
+
+// AscendRange calls `fn` on every item of the tree whose key is in the range [from, to)
+// in ascending order of the keys, if `fn` returns true then continue iterating; or false to stop.
+// The tree is locked for reading while `fn` runs, so `fn` must not modify the tree.
+func (t *Tree[K, V]) AscendRange(from, to K, fn func(key K, value V) bool) {
+	t.mu.Count("AscendRange")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for n := t.ceiling(from); n != nil && t.comparator(n.key, to) < 0 && fn(n.key, n.value); n = successor(n) {
+	}
+}
+
+// Descend calls `fn` on every item of the tree in descending order of the keys,
+// if `fn` returns true then continue iterating; or false to stop.
+// The tree is locked for reading while `fn` runs, so `fn` must not modify the tree.
+func (t *Tree[K, V]) Descend(fn func(key K, value V) bool) {
+	t.mu.Count("Descend")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for n := maximum(t.root); n != nil && fn(n.key, n.value); n = predecessor(n) {
+	}
+}
+
+// Keys returns all keys of the tree in ascending order.
+func (t *Tree[K, V]) Keys() []K {
+	keys := make([]K, 0, t.Size())
+	t.Ascend(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Values returns all values of the tree in ascending order of their keys.
+func (t *Tree[K, V]) Values() []V {
+	values := make([]V, 0, t.Size())
+	t.Ascend(func(_ K, value V) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// Clone returns a new tree, which is a copy of current tree with the same configuration.
+func (t *Tree[K, V]) Clone() *Tree[K, V] {
+	t.mu.Count("Clone")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	newTree := New[K, V](t.comparator, t.mu.Options()...)
+	newTree.root, newTree.size = clone(t.root, nil), t.size
+	return newTree
+}
+
+// Stats returns a snapshot of the metrics of the tree created with gods.WithMetrics(true).
+func (t *Tree[K, V]) Stats() gods.Stats {
+	return t.mu.Stats()
+}
+
+// String returns the items of the tree as a string in ascending order of the keys.
+func (t *Tree[K, V]) String() string {
+	out := make([]string, 0)
+	t.Ascend(func(key K, value V) bool {
+		out = append(out, fmt.Sprintf(`%v:%v`, key, value))
+		return true
+	})
+	return fmt.Sprintf("map%v", out)
+}
+
+// lookup returns the node of `key`, or nil if `key` is not in the tree.
+func (t *Tree[K, V]) lookup(key K) *node[K, V] {
+	n := t.root
+	for n != nil {
+		cmp := t.comparator(key, n.key)
+		switch {
+		case cmp < 0:
+			n = n.left
+		case cmp > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// floor returns the node of the largest key less than or equal to `key`, or nil if there is none.
+func (t *Tree[K, V]) floor(key K) (floor *node[K, V]) {
+	n := t.root
+	for n != nil {
+		cmp := t.comparator(key, n.key)
+		switch {
+		case cmp < 0:
+			n = n.left
+		case cmp > 0:
+			floor, n = n, n.right
+		default:
+			return n
+		}
+	}
+	return floor
+}
+
+// ceiling returns the node of the smallest key greater than or equal to `key`, or nil if there is none.
+func (t *Tree[K, V]) ceiling(key K) (ceiling *node[K, V]) {
+	n := t.root
+	for n != nil {
+		cmp := t.comparator(key, n.key)
+		switch {
+		case cmp < 0:
+			ceiling, n = n, n.left
+		case cmp > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return ceiling
+}
+
+// insertFixup restores the red-black properties after inserting the red node `n`.
+func (t *Tree[K, V]) insertFixup(n *node[K, V]) {
+	for colorOf(n.parent) == red {
+		grandparent := n.parent.parent
+		if n.parent == grandparent.left {
+			if uncle := grandparent.right; colorOf(uncle) == red {
+				n.parent.color, uncle.color, grandparent.color = black, black, red
+				n = grandparent
+				continue
+			}
+			if n == n.parent.right {
+				n = n.parent
+				t.rotateLeft(n)
+			}
+			n.parent.color, grandparent.color = black, red
+			t.rotateRight(grandparent)
+		} else {
+			if uncle := grandparent.left; colorOf(uncle) == red {
+				n.parent.color, uncle.color, grandparent.color = black, black, red
+				n = grandparent
+				continue
+			}
+			if n == n.parent.left {
+				n = n.parent
+				t.rotateRight(n)
+			}
+			n.parent.color, grandparent.color = black, red
+			t.rotateLeft(grandparent)
+		}
+	}
+	t.root.color = black
+}
+
+// delete removes the node `n` from the tree.
+func (t *Tree[K, V]) delete(n *node[K, V]) {
+	t.size--
+	// The child replacing the removed node, and its parent, as the child may be nil.
+	var child, parent *node[K, V]
+	removed := n.color
+	switch {
+	case n.left == nil:
+		child, parent = n.right, n.parent
+		t.transplant(n, n.right)
+	case n.right == nil:
+		child, parent = n.left, n.parent
+		t.transplant(n, n.left)
+	default:
+		// Move the successor to the place of `n`.
+		next := minimum(n.right)
+		removed = next.color
+		child = next.right
+		if next.parent == n {
+			parent = next
+		} else {
+			parent = next.parent
+			t.transplant(next, next.right)
+			next.right = n.right
+			next.right.parent = next
+		}
+		t.transplant(n, next)
+		next.left = n.left
+		next.left.parent = next
+		next.color = n.color
+	}
+	n.left, n.right, n.parent = nil, nil, nil
+	if removed == black {
+		t.deleteFixup(child, parent)
+	}
+}
+
+// deleteFixup restores the red-black properties after removing a black node,
+// where `n` is the child replacing it, which is possibly nil, and `parent` is its parent.
+func (t *Tree[K, V]) deleteFixup(n, parent *node[K, V]) {
+	for n != t.root && colorOf(n) == black {
+		if n == parent.left {
+			sibling := parent.right
+			if sibling.color == red {
+				sibling.color, parent.color = black, red
+				t.rotateLeft(parent)
+				sibling = parent.right
+			}
+			if colorOf(sibling.left) == black && colorOf(sibling.right) == black {
+				sibling.color = red
+				n, parent = parent, parent.parent
+				continue
+			}
+			if colorOf(sibling.right) == black {
+				sibling.left.color, sibling.color = black, red
+				t.rotateRight(sibling)
+				sibling = parent.right
+			}
+			sibling.color, parent.color, sibling.right.color = parent.color, black, black
+			t.rotateLeft(parent)
+		} else {
+			sibling := parent.left
+			if sibling.color == red {
+				sibling.color, parent.color = black, red
+				t.rotateRight(parent)
+				sibling = parent.left
+			}
+			if colorOf(sibling.left) == black && colorOf(sibling.right) == black {
+				sibling.color = red
+				n, parent = parent, parent.parent
+				continue
+			}
+			if colorOf(sibling.left) == black {
+				sibling.right.color, sibling.color = black, red
+				t.rotateLeft(sibling)
+				sibling = parent.left
+			}
+			sibling.color, parent.color, sibling.left.color = parent.color, black, black
+			t.rotateRight(parent)
+		}
+		n = t.root
+	}
+	if n != nil {
+		n.color = black
+	}
+}
+
+// transplant replaces the subtree rooted at `old` with the subtree rooted at `n`, which is possibly nil.
+func (t *Tree[K, V]) transplant(old, n *node[K, V]) {
+	switch {
+	case old.parent == nil:
+		t.root = n
+	case old == old.parent.left:
+		old.parent.left = n
+	default:
+		old.parent.right = n
+	}
+	if n != nil {
+		n.parent = old.parent
+	}
+}
+
+// rotateLeft rotates the subtree rooted at `n` to the left, so its right child becomes the root.
+func (t *Tree[K, V]) rotateLeft(n *node[K, V]) {
+	right := n.right
+	n.right = right.left
+	if right.left != nil {
+		right.left.parent = n
+	}
+	t.transplant(n, right)
+	right.left, n.parent = n, right
+}
+
+// rotateRight rotates the subtree rooted at `n` to the right, so its left child becomes the root.
+func (t *Tree[K, V]) rotateRight(n *node[K, V]) {
+	left := n.left
+	n.left = left.right
+	if left.right != nil {
+		left.right.parent = n
+	}
+	t.transplant(n, left)
+	left.right, n.parent = n, left
+}
+
+// colorOf returns the color of `n`, where a nil node is black.
+func colorOf[K, V any](n *node[K, V]) color {
+	if n == nil {
+		return black
+	}
+	return n.color
+}
+
+// entry returns the key and value of `n`, the `found` is false if `n` is nil.
+func entry[K, V any](n *node[K, V]) (key K, value V, found bool) {
+	if n == nil {
+		return
+	}
+	return n.key, n.value, true
+}
+
+// minimum returns the node of the smallest key in the subtree rooted at `n`, or nil if `n` is nil.
+func minimum[K, V any](n *node[K, V]) *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+// maximum returns the node of the largest key in the subtree rooted at `n`, or nil if `n` is nil.
+func maximum[K, V any](n *node[K, V]) *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n
+}
+
+// successor returns the node of the next larger key, or nil if `n` has the largest key.
+func successor[K, V any](n *node[K, V]) *node[K, V] {
+	if n.right != nil {
+		return minimum(n.right)
+	}
+	for n.parent != nil && n == n.parent.right {
+		n = n.parent
+	}
+	return n.parent
+}
+
+// predecessor returns the node of the next smaller key, or nil if `n` has the smallest key.
+func predecessor[K, V any](n *node[K, V]) *node[K, V] {
+	if n.left != nil {
+		return maximum(n.left)
+	}
+	for n.parent != nil && n == n.parent.left {
+		n = n.parent
+	}
+	return n.parent
+}
+
+// clone returns a copy of the subtree rooted at `n` whose root has the parent `parent`.
+func clone[K, V any](n, parent *node[K, V]) *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	c := &node[K, V]{key: n.key, value: n.value, color: n.color, parent: parent}
+	c.left, c.right = clone(n.left, c), clone(n.right, c)
+	return c
+}
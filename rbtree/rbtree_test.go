@@ -0,0 +1,250 @@
+package rbtree_test
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/rbtree"
+)
+
+func TestRBTree(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RBTree Suite")
+}
+
+// sortedKeys returns the keys of `m` in ascending order.
+func sortedKeys(m map[int]string) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+var _ = Describe("Tree", func() {
+	It("Put, Get and Remove", func() {
+		t := rbtree.New[int, string](gods.Compare[int])
+		Expect(t.IsEmpty()).To(BeTrue())
+		t.Put(2, "b")
+		t.Put(1, "a")
+		t.Put(3, "c")
+		t.Put(2, "B")
+		Expect(t.Size()).To(Equal(3))
+		Expect(t.Keys()).To(Equal([]int{1, 2, 3}))
+		Expect(t.Values()).To(Equal([]string{"a", "B", "c"}))
+		Expect(t.String()).To(Equal("map[1:a 2:B 3:c]"))
+
+		v, found := t.Get(2)
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal("B"))
+		_, found = t.Get(4)
+		Expect(found).To(BeFalse())
+		Expect(t.Contains(4)).To(BeFalse())
+
+		Expect(t.PutIfAbsent(2, "x")).To(BeFalse())
+		Expect(t.PutIfAbsent(4, "d")).To(BeTrue())
+		Expect(t.Keys()).To(Equal([]int{1, 2, 3, 4}))
+
+		v, found = t.Remove(1)
+		Expect(found).To(BeTrue())
+		Expect(v).To(Equal("a"))
+		Expect(t.Remove(1)).To(BeZero())
+		Expect(t.Keys()).To(Equal([]int{2, 3, 4}))
+		Expect(t.Check()).To(Succeed())
+
+		t.Clear()
+		Expect(t.Size()).To(BeZero())
+		Expect(t.Keys()).To(BeEmpty())
+	})
+
+	It("PutAll, ReplaceAll and RemoveAll", func() {
+		t := rbtree.New[int, string](gods.Compare[int], gods.WithMetrics(true))
+		t.PutAll([]int{3, 1, 2, 1}, "x")
+		Expect(t.Keys()).To(Equal([]int{1, 2, 3}))
+		Expect(t.Values()).To(Equal([]string{"x", "x", "x"}))
+		Expect(t.RemoveAll(1, 3, 4)).To(Equal(2))
+		Expect(t.Keys()).To(Equal([]int{2}))
+		t.ReplaceAll([]int{5, 4}, "y")
+		Expect(t.Keys()).To(Equal([]int{4, 5}))
+		Expect(t.Check()).To(Succeed())
+		Expect(t.Stats().Operations).To(HaveKeyWithValue("PutAll", uint64(1)))
+		Expect(t.Stats().Operations).To(HaveKeyWithValue("RemoveAll", uint64(1)))
+		Expect(t.Stats().Operations).To(HaveKeyWithValue("ReplaceAll", uint64(1)))
+	})
+
+	It("Min, Max, Floor, Ceiling, Lower and Higher", func() {
+		t := rbtree.NewFrom(map[int]string{10: "a", 20: "b", 30: "c"}, gods.Compare[int])
+		check := func(key int, value string, found bool) func(int, string, bool) {
+			return func(k int, v string, f bool) {
+				ExpectWithOffset(1, f).To(Equal(found))
+				if found {
+					ExpectWithOffset(1, k).To(Equal(key))
+					ExpectWithOffset(1, v).To(Equal(value))
+				}
+			}
+		}
+		check(10, "a", true)(t.Min())
+		check(30, "c", true)(t.Max())
+		check(20, "b", true)(t.Floor(20))
+		check(20, "b", true)(t.Floor(25))
+		check(0, "", false)(t.Floor(5))
+		check(30, "c", true)(t.Floor(99))
+		check(20, "b", true)(t.Ceiling(20))
+		check(30, "c", true)(t.Ceiling(25))
+		check(10, "a", true)(t.Ceiling(5))
+		check(0, "", false)(t.Ceiling(31))
+		check(10, "a", true)(t.Lower(20))
+		check(20, "b", true)(t.Lower(25))
+		check(0, "", false)(t.Lower(10))
+		check(30, "c", true)(t.Higher(20))
+		check(10, "a", true)(t.Higher(5))
+		check(0, "", false)(t.Higher(30))
+
+		empty := rbtree.New[int, string](gods.Compare[int])
+		check(0, "", false)(empty.Min())
+		check(0, "", false)(empty.Max())
+		check(0, "", false)(empty.Floor(1))
+		check(0, "", false)(empty.Ceiling(1))
+		check(0, "", false)(empty.Lower(1))
+		check(0, "", false)(empty.Higher(1))
+	})
+
+	It("PollFirst and PollLast", func() {
+		t := rbtree.NewFrom(map[int]string{1: "a", 2: "b", 3: "c"}, gods.Compare[int])
+		key, value, found := t.PollFirst()
+		Expect(found).To(BeTrue())
+		Expect(key).To(Equal(1))
+		Expect(value).To(Equal("a"))
+		key, value, found = t.PollLast()
+		Expect(found).To(BeTrue())
+		Expect(key).To(Equal(3))
+		Expect(value).To(Equal("c"))
+		Expect(t.Keys()).To(Equal([]int{2}))
+		t.PollLast()
+		_, _, found = t.PollFirst()
+		Expect(found).To(BeFalse())
+		_, _, found = t.PollLast()
+		Expect(found).To(BeFalse())
+		Expect(t.Check()).To(Succeed())
+	})
+
+	It("Ascend, Descend and ranges", func() {
+		t := rbtree.New[int, string](gods.Compare[int])
+		for i := 0; i < 10; i++ {
+			t.Put(i*2, "")
+		}
+		collect := func(each func(fn func(int, string) bool), limit int) []int {
+			keys := make([]int, 0)
+			each(func(k int, _ string) bool {
+				keys = append(keys, k)
+				return len(keys) < limit
+			})
+			return keys
+		}
+		Expect(collect(t.Ascend, 100)).To(Equal([]int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}))
+		Expect(collect(t.Ascend, 3)).To(Equal([]int{0, 2, 4}))
+		Expect(collect(t.Descend, 3)).To(Equal([]int{18, 16, 14}))
+		from := func(from int) func(fn func(int, string) bool) {
+			return func(fn func(int, string) bool) { t.AscendFrom(from, fn) }
+		}
+		Expect(collect(from(13), 100)).To(Equal([]int{14, 16, 18}))
+		Expect(collect(from(14), 2)).To(Equal([]int{14, 16}))
+		Expect(collect(from(19), 100)).To(BeEmpty())
+		between := func(from, to int) func(fn func(int, string) bool) {
+			return func(fn func(int, string) bool) { t.AscendRange(from, to, fn) }
+		}
+		Expect(collect(between(4, 10), 100)).To(Equal([]int{4, 6, 8}))
+		Expect(collect(between(3, 11), 100)).To(Equal([]int{4, 6, 8, 10}))
+		Expect(collect(between(3, 11), 1)).To(Equal([]int{4}))
+		Expect(collect(between(10, 4), 100)).To(BeEmpty())
+	})
+
+	It("Clone", func() {
+		t := rbtree.NewFrom(map[int]string{1: "a", 2: "b"}, gods.Compare[int], gods.WithSafe(true))
+		c := t.Clone()
+		c.Put(3, "c")
+		t.Remove(1)
+		Expect(t.Keys()).To(Equal([]int{2}))
+		Expect(c.Keys()).To(Equal([]int{1, 2, 3}))
+		Expect(c.Check()).To(Succeed())
+	})
+
+	It("Comparator", func() {
+		t := rbtree.New[string, int](func(a, b string) int { return gods.Compare(b, a) })
+		t.Put("a", 1)
+		t.Put("c", 3)
+		t.Put("b", 2)
+		Expect(t.Keys()).To(Equal([]string{"c", "b", "a"}))
+		Expect(func() { rbtree.New[string, int](nil) }).To(Panic())
+	})
+
+	It("Locker", func() {
+		Expect(func() { rbtree.New[int, string](gods.Compare[int], gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: rbtree.Tree does not support LockCopyOnWrite"))
+		t := rbtree.New[int, string](gods.Compare[int], gods.WithLocker(gods.LockSpin))
+		Expect(t.Clone().Check()).To(Succeed())
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					t.Put(w*100+i, "")
+					t.Get(i)
+				}
+			}(w)
+		}
+		wg.Wait()
+		Expect(t.Size()).To(Equal(400))
+		Expect(t.Check()).To(Succeed())
+	})
+
+	It("Stats", func() {
+		t := rbtree.New[int, string](gods.Compare[int], gods.WithMetrics(true))
+		t.Put(1, "a")
+		t.Get(1)
+		t.Get(2)
+		Expect(t.Stats().Operations).To(HaveKeyWithValue("Put", uint64(1)))
+		Expect(t.Stats().Operations).To(HaveKeyWithValue("Get", uint64(2)))
+	})
+
+	It("keeps the red-black properties after random operations", func() {
+		r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+		t := rbtree.New[int, string](gods.Compare[int])
+		m := make(map[int]string)
+		for i := 0; i < 5000; i++ {
+			key := r.Intn(500)
+			switch r.Intn(5) {
+			case 0, 1:
+				_, found := t.Remove(key)
+				_, ok := m[key]
+				Expect(found).To(Equal(ok))
+				delete(m, key)
+			case 2:
+				if k, _, found := t.PollFirst(); found {
+					Expect(k).To(Equal(sortedKeys(m)[0]))
+					delete(m, k)
+				}
+			default:
+				t.Put(key, "v")
+				m[key] = "v"
+			}
+			Expect(t.Check()).To(Succeed())
+		}
+		Expect(t.Keys()).To(Equal(sortedKeys(m)))
+		for len(m) > 0 {
+			k, _, _ := t.PollLast()
+			delete(m, k)
+			Expect(t.Check()).To(Succeed())
+		}
+		Expect(t.IsEmpty()).To(BeTrue())
+	})
+})
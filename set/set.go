@@ -1,46 +1,89 @@
 package set
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
+	"sync/atomic"
 
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/iterator"
 	"github.com/lazybabe/gods/internal/rwmutex"
 )
 
-// Set is a unordered collection of unique members.
+var _ gods.Collection[int] = (*Set[int])(nil)
+
+// Set is a unordered collection of unique members, which supports all the options, see gods.Option.
 type Set[T comparable] struct {
 	mu   rwmutex.RWMutex
 	data map[T]struct{}
+	// Copy of data published to the readers in copy-on-write usage.
+	view       atomic.Pointer[map[T]struct{}]
+	comparator func(a, b T) int
+	hooks      gods.Hooks[T]
+	// Whether iterating over a snapshot of the set without holding the lock.
+	snapshot bool
 }
 
 // New returns an empty set.
-// The parameter `safe` is used to specify whether using set in concurrent-safety,
-// which is false in default.
-func New[T comparable](safe ...bool) *Set[T] {
-	return &Set[T]{
-		mu:   rwmutex.Create(safe...),
-		data: make(map[T]struct{}),
-	}
+// The parameter `options` is used to configure the set, see gods.Option.
+// It also takes the former `safe` bool, which is deprecated in favor of gods.WithSafe.
+// It is not concurrent-safe in default.
+func New[T comparable](options ...any) *Set[T] {
+	return NewFrom[T](nil, options...)
 }
 
 // NewFrom returns a set from `items`.
-func NewFrom[T comparable](items []T, safe ...bool) *Set[T] {
-	data := make(map[T]struct{})
+// The parameter `options` is used to configure the set, see gods.Option.
+// It also takes the former `safe` bool, which is deprecated in favor of gods.WithSafe.
+// It is not concurrent-safe in default.
+func NewFrom[T comparable](items []T, options ...any) *Set[T] {
+	return newFrom(items, rwmutex.OptionsOf("set.Set", options))
+}
+
+// newFrom returns a set from `items` configured by `options`.
+func newFrom[T comparable](items []T, options []gods.Option) *Set[T] {
+	o := gods.NewOptions(options...)
+	data := make(map[T]struct{}, o.Capacity)
 	for i := range items {
 		data[items[i]] = struct{}{}
 	}
-	return &Set[T]{
-		mu:   rwmutex.Create(safe...),
-		data: data,
+	s := &Set[T]{
+		mu:         rwmutex.CreateWith(o),
+		data:       data,
+		comparator: gods.ComparatorOf[T](o),
+		hooks:      gods.HooksOf[T](o),
+		snapshot:   o.Snapshot,
 	}
+	s.publish()
+	return s
 }
 
 // Each calls 'fn' on every item in the set in no particular order,
 // if `fn` returns true then continue iterating; or false to stop.
+//
+// The set is locked for reading while `fn` runs, so `fn` must not modify the set,
+// unless the set is created with gods.WithSnapshot(true) or in copy-on-write usage,
+// where `fn` runs without holding the lock over the items as of the moment Each is called.
 func (s *Set[T]) Each(fn func(item T) bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for v := range s.data {
+	s.mu.Count("Each")
+	if s.snapshot {
+		for _, v := range s.snapshotItems() {
+			if !fn(v) {
+				break
+			}
+		}
+		return
+	}
+	data := s.rlock()
+	if s.mu.IsCopyOnWrite() {
+		// The published items are never modified, iterate over them without holding the lock.
+		s.mu.RUnlock()
+	} else {
+		defer s.mu.RUnlock()
+	}
+	for v := range data {
 		if !fn(v) {
 			break
 		}
@@ -49,90 +92,185 @@ func (s *Set[T]) Each(fn func(item T) bool) {
 
 // Add adds one or multiple items to the set.
 func (s *Set[T]) Add(items ...T) {
+	s.mu.Count("Add")
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
+	s.doAddWithoutLock(items...)
+}
+
+// AddCtx is like Add, but it returns ctx.Err() if `ctx` is done before the set is locked.
+func (s *Set[T]) AddCtx(ctx context.Context, items ...T) error {
+	s.mu.Count("AddCtx")
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.unlock()
+	s.doAddWithoutLock(items...)
+	return nil
+}
+
+// doAddWithoutLock adds items to the set without lock.
+func (s *Set[T]) doAddWithoutLock(items ...T) {
 	if s.data == nil {
 		s.data = make(map[T]struct{})
 	}
 	for i := range items {
+		if _, ok := s.data[items[i]]; ok {
+			continue
+		}
 		s.data[items[i]] = struct{}{}
+		if s.hooks.OnInsert != nil {
+			s.hooks.OnInsert(items[i])
+		}
 	}
 }
 
 // Remove deletes one or multiple items from set.
 func (s *Set[T]) Remove(items ...T) {
+	s.mu.Count("Remove")
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
+	s.doRemoveWithoutLock(items...)
+}
+
+// RemoveCtx is like Remove, but it returns ctx.Err() if `ctx` is done before the set is locked.
+func (s *Set[T]) RemoveCtx(ctx context.Context, items ...T) error {
+	s.mu.Count("RemoveCtx")
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.unlock()
+	s.doRemoveWithoutLock(items...)
+	return nil
+}
+
+// doRemoveWithoutLock deletes items from the set without lock.
+func (s *Set[T]) doRemoveWithoutLock(items ...T) {
 	if s.data != nil {
 		for i := range items {
+			if _, ok := s.data[items[i]]; !ok {
+				continue
+			}
 			delete(s.data, items[i])
+			if s.hooks.OnRemove != nil {
+				s.hooks.OnRemove(items[i])
+			}
 		}
 	}
 }
 
 // Contains checks whether the set contains `item`.
 func (s *Set[T]) Contains(item T) bool {
-	s.mu.RLock()
+	s.mu.Count("Contains")
+	data := s.rlock()
 	defer s.mu.RUnlock()
-	_, ok := s.data[item]
+	_, ok := data[item]
 	return ok
 }
 
+// ContainsCtx is like Contains, but it returns ctx.Err() if `ctx` is done before the set is locked.
+func (s *Set[T]) ContainsCtx(ctx context.Context, item T) (bool, error) {
+	s.mu.Count("ContainsCtx")
+	data, err := s.rlockContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer s.mu.RUnlock()
+	_, ok := data[item]
+	return ok, nil
+}
+
 // Size returns the number of items in the set.
 func (s *Set[T]) Size() int {
-	s.mu.RLock()
+	s.mu.Count("Size")
+	data := s.rlock()
 	defer s.mu.RUnlock()
-	return len(s.data)
+	return len(data)
+}
+
+// IsEmpty checks whether the set is empty.
+func (s *Set[T]) IsEmpty() bool {
+	return s.Size() == 0
+}
+
+// Iterator returns an iterator over the items of the set in no particular order,
+// which are the ones as of the moment Iterator is called.
+func (s *Set[T]) Iterator() gods.Iterator[T] {
+	s.mu.Count("Iterator")
+	return iterator.New(s.snapshotItems())
 }
 
 // Clear deletes all items of the set.
 func (s *Set[T]) Clear() {
+	s.mu.Count("Clear")
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
+	if s.hooks.OnRemove != nil {
+		for k := range s.data {
+			s.hooks.OnRemove(k)
+		}
+	}
 	s.data = make(map[T]struct{})
 }
 
 // Slice returns all items of the set as slice.
 func (s *Set[T]) Slice() []T {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	slice := make([]T, 0, len(s.data))
-	for k := range s.data {
-		slice = append(slice, k)
-	}
-	return slice
+	s.mu.Count("Slice")
+	return s.snapshotItems()
 }
 
 // String returns items as a string.
+// The items are sorted by the comparator given by gods.WithComparator, or else by their formats.
 func (s *Set[T]) String() string {
+	if s.comparator != nil {
+		items := s.Slice()
+		sort.Slice(items, func(i, j int) bool { return s.comparator(items[i], items[j]) < 0 })
+		return fmt.Sprintf("%v", items)
+	}
 	out := make([]string, 0, s.Size())
 	s.Each(func(v T) bool { out = append(out, fmt.Sprintf(`%v`, v)); return true })
 	sort.Strings(out)
 	return fmt.Sprintf("%v", out)
 }
 
+// Stats returns a snapshot of the metrics of the set created with gods.WithMetrics(true).
+func (s *Set[T]) Stats() gods.Stats {
+	return s.mu.Stats()
+}
+
 // Clone returns a new set by deep copy.
 func (s *Set[T]) Clone() *Set[T] {
-	return NewFrom(s.Slice(), s.mu.IsSafe())
+	return newFrom(s.Slice(), s.options())
+}
+
+// Reset deletes all items of the set like Clear, it implements gods.Container.
+func (s *Set[T]) Reset() {
+	s.Clear()
+}
+
+// Copy returns a new set like Clone, it implements gods.Container.
+func (s *Set[T]) Copy() gods.Container[T] {
+	return s.Clone()
 }
 
 // Equal checks whether the two sets equal.
 func (s *Set[T]) Equal(other *Set[T]) bool {
+	s.mu.Count("Equal")
 	if other == nil {
 		return false
 	}
 	if s == other {
 		return true
 	}
-	s.mu.RLock()
+	data := s.rlock()
 	defer s.mu.RUnlock()
-	other.mu.RLock()
+	otherData := other.rlock()
 	defer other.mu.RUnlock()
-	if len(s.data) != len(other.data) {
+	if len(data) != len(otherData) {
 		return false
 	}
-	for key := range s.data {
-		if _, ok := other.data[key]; !ok {
+	for key := range data {
+		if _, ok := otherData[key]; !ok {
 			return false
 		}
 	}
@@ -141,18 +279,19 @@ func (s *Set[T]) Equal(other *Set[T]) bool {
 
 // IsSubsetOf checks whether the current set is a sub-set of `other`.
 func (s *Set[T]) IsSubsetOf(other *Set[T]) bool {
+	s.mu.Count("IsSubsetOf")
 	if other == nil {
 		return false
 	}
 	if s == other {
 		return true
 	}
-	s.mu.RLock()
+	data := s.rlock()
 	defer s.mu.RUnlock()
-	other.mu.RLock()
+	otherData := other.rlock()
 	defer other.mu.RUnlock()
-	for key := range s.data {
-		if _, ok := other.data[key]; !ok {
+	for key := range data {
+		if _, ok := otherData[key]; !ok {
 			return false
 		}
 	}
@@ -161,15 +300,19 @@ func (s *Set[T]) IsSubsetOf(other *Set[T]) bool {
 
 // Union returns a new set which is the union of `set` and `other`.
 // Which means, all the items in `newSet` are in `set` or in `other`.
+// The hooks of `newSet`, which are the ones of `set`, are called on the items added from `other`.
 func (s *Set[T]) Union(others ...*Set[T]) *Set[T] {
+	s.mu.Count("Union")
 	newSet := s.Clone()
+	newSet.mu.Lock()
+	defer newSet.unlock()
 	for _, other := range others {
 		if other == nil {
 			continue
 		}
-		other.mu.RLock()
-		for k, v := range other.data {
-			newSet.data[k] = v
+		otherData := other.rlock()
+		for k := range otherData {
+			newSet.doAddWithoutLock(k)
 		}
 		other.mu.RUnlock()
 	}
@@ -178,15 +321,19 @@ func (s *Set[T]) Union(others ...*Set[T]) *Set[T] {
 
 // Diff returns a new set which is the difference set from `set` to `other`.
 // Which means, all the items in `newSet` are in `set` but not in `other`.
+// The hooks of `newSet`, which are the ones of `set`, are called on the items removed.
 func (s *Set[T]) Diff(others ...*Set[T]) *Set[T] {
+	s.mu.Count("Diff")
 	newSet := s.Clone()
+	newSet.mu.Lock()
+	defer newSet.unlock()
 	for _, other := range others {
 		if other == nil {
 			continue
 		}
-		other.mu.RLock()
-		for k := range other.data {
-			delete(newSet.data, k)
+		otherData := other.rlock()
+		for k := range otherData {
+			newSet.doRemoveWithoutLock(k)
 		}
 		other.mu.RUnlock()
 	}
@@ -195,19 +342,105 @@ func (s *Set[T]) Diff(others ...*Set[T]) *Set[T] {
 
 // Intersect returns a new set which is the intersection from `set` to `other`.
 // Which means, all the items in `newSet` are in `set` and also in `other`.
+// The hooks of `newSet`, which are the ones of `set`, are called on the items removed.
 func (s *Set[T]) Intersect(others ...*Set[T]) *Set[T] {
-	newSet := s.Clone()
+	s.mu.Count("Intersect")
 	for _, other := range others {
 		if other == nil {
-			return New[T](s.mu.IsSafe())
+			return newFrom[T](nil, s.options())
 		}
-		other.mu.RLock()
+	}
+	newSet := s.Clone()
+	newSet.mu.Lock()
+	defer newSet.unlock()
+	for _, other := range others {
+		otherData := other.rlock()
 		for k := range newSet.data {
-			if _, ok := other.data[k]; !ok {
-				delete(newSet.data, k)
+			if _, ok := otherData[k]; !ok {
+				newSet.doRemoveWithoutLock(k)
 			}
 		}
 		other.mu.RUnlock()
 	}
 	return newSet
 }
+
+// MarshalJSON implements the interface MarshalJSON for json.Marshal,
+// which encodes the items as an array in no particular order.
+func (s *Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.snapshotItems())
+}
+
+// UnmarshalJSON implements the interface UnmarshalJSON for json.Unmarshal,
+// which replaces all items of the set with the decoded ones.
+func (s *Set[T]) UnmarshalJSON(b []byte) error {
+	var items []T
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.unlock()
+	for k := range s.data {
+		delete(s.data, k)
+		if s.hooks.OnRemove != nil {
+			s.hooks.OnRemove(k)
+		}
+	}
+	s.doAddWithoutLock(items...)
+	return nil
+}
+
+// options returns the options which configure a copy of the set.
+func (s *Set[T]) options() []gods.Option {
+	return append(s.mu.Options(),
+		gods.WithComparator(s.comparator), gods.WithHooks(s.hooks), gods.WithSnapshot(s.snapshot))
+}
+
+// rlock locks the set for reading, and returns the items to read.
+// In copy-on-write usage, it returns the items published by the last writer without locking.
+func (s *Set[T]) rlock() map[T]struct{} {
+	s.mu.RLock()
+	if s.mu.IsCopyOnWrite() {
+		return *s.view.Load()
+	}
+	return s.data
+}
+
+// rlockContext is like rlock, but it returns ctx.Err() if `ctx` is done before the set is locked.
+func (s *Set[T]) rlockContext(ctx context.Context) (map[T]struct{}, error) {
+	if err := s.mu.RLockContext(ctx); err != nil {
+		return nil, err
+	}
+	if s.mu.IsCopyOnWrite() {
+		return *s.view.Load(), nil
+	}
+	return s.data, nil
+}
+
+// snapshotItems returns a copy of the items as of now.
+func (s *Set[T]) snapshotItems() []T {
+	data := s.rlock()
+	defer s.mu.RUnlock()
+	slice := make([]T, 0, len(data))
+	for k := range data {
+		slice = append(slice, k)
+	}
+	return slice
+}
+
+// unlock publishes the items in copy-on-write usage, and unlocks the set for writing.
+func (s *Set[T]) unlock() {
+	s.publish()
+	s.mu.Unlock()
+}
+
+// publish publishes a copy of the items to the readers in copy-on-write usage.
+func (s *Set[T]) publish() {
+	if s.mu.IsCopyOnWrite() {
+		view := make(map[T]struct{}, len(s.data))
+		for k := range s.data {
+			view[k] = struct{}{}
+		}
+		s.view.Store(&view)
+	}
+}
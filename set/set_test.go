@@ -1,11 +1,18 @@
 package set_test
 
 import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sync"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/containertest"
 	"github.com/lazybabe/gods/set"
 )
 
@@ -160,4 +167,334 @@ var _ = Describe("Set", func() {
 		Expect(s1.Intersect(s2, s3)).To(Equal(set.NewFrom([]int{1})))
 		Expect(s1.Intersect(s2, nil)).To(Equal(set.New[int]()))
 	})
+
+	DescribeTable("Locker",
+		func(locker gods.Locker) {
+			s := set.New[int](gods.WithLocker(locker))
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						s.Add(i*100 + j)
+						s.Size()
+						s.Contains(i)
+						s.Slice()
+					}
+				}(i)
+			}
+			wg.Wait()
+			Expect(s.Size()).To(Equal(800))
+
+			other := set.NewFrom([]int{0, 1, 1000}, gods.WithLocker(locker))
+			Expect(s.Union(other).Size()).To(Equal(801))
+			Expect(s.Diff(other).Size()).To(Equal(798))
+			Expect(s.Intersect(other).Slice()).To(ConsistOf(0, 1))
+			Expect(s.Intersect(other).Contains(0)).To(BeTrue())
+			Expect(s.Intersect(other, nil).Size()).To(BeZero())
+			Expect(s.Intersect(other).IsSubsetOf(s)).To(BeTrue())
+			Expect(s.Clone().Equal(s)).To(BeTrue())
+			s.Remove(0)
+			Expect(s.Contains(0)).To(BeFalse())
+			s.Clear()
+			Expect(s.Size()).To(BeZero())
+		},
+		Entry("mutex", gods.LockMutex),
+		Entry("rwmutex", gods.LockRWMutex),
+		Entry("spin", gods.LockSpin),
+		Entry("copy-on-write", gods.LockCopyOnWrite),
+	)
+
+	It("Options", func() {
+		s := set.NewFrom([]int{3, 1, 20}, gods.WithCapacity(16), gods.WithComparator(func(a, b int) int { return a - b }))
+		Expect(s.String()).To(Equal(`[1 3 20]`))
+		Expect(s.Union(set.NewFrom([]int{10})).String()).To(Equal(`[1 3 10 20]`))
+		Expect(set.New[int](gods.WithSafe(true)).Clone().Intersect(nil).Size()).To(BeZero())
+	})
+
+	It("Hooks", func() {
+		var inserted, removed []int
+		s := set.NewFrom([]int{1}, gods.WithHooks(gods.Hooks[int]{
+			OnInsert: func(v int) { inserted = append(inserted, v) },
+			OnRemove: func(v int) { removed = append(removed, v) },
+		}))
+		s.Add(1, 2, 2, 3)
+		s.Remove(3, 4)
+		Expect(inserted).To(Equal([]int{2, 3}))
+		Expect(removed).To(Equal([]int{3}))
+		// The new sets of Union, Diff and Intersect share the hooks of the set.
+		union := s.Union(set.NewFrom([]int{1, 5}))
+		Expect(inserted).To(Equal([]int{2, 3, 5}))
+		Expect(union.Slice()).To(ConsistOf(1, 2, 5))
+		s.Diff(set.NewFrom([]int{2, 9}))
+		Expect(removed).To(Equal([]int{3, 2}))
+		s.Intersect(set.NewFrom([]int{1}))
+		Expect(removed).To(Equal([]int{3, 2, 2}))
+		Expect(s.Slice()).To(ConsistOf(1, 2))
+		removed = nil
+		s.Clear()
+		Expect(removed).To(ConsistOf(1, 2))
+	})
+
+	It("Stats", func() {
+		s := set.New[int](gods.WithMetrics(true))
+		s.Add(1, 2)
+		s.Union(set.NewFrom([]int{3}))
+		stats := s.Stats()
+		Expect(stats.WriteLocks).To(Equal(uint64(1)))
+		Expect(stats.Operations).To(Equal(map[string]uint64{"Add": 1, "Union": 1, "Slice": 1}))
+	})
+
+	It("Ctx", func() {
+		held, release := make(chan struct{}), make(chan struct{})
+		s := set.NewFrom([]int{1, 2}, gods.WithSafe(true), gods.WithHooks(gods.Hooks[int]{
+			OnInsert: func(v int) {
+				if v < 0 {
+					held <- struct{}{}
+					<-release
+				}
+			},
+		}))
+		go s.Add(-1)
+		<-held
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		Expect(s.AddCtx(ctx, 3)).To(MatchError(context.DeadlineExceeded))
+		Expect(s.RemoveCtx(ctx, 1)).To(MatchError(context.DeadlineExceeded))
+		_, err := s.ContainsCtx(ctx, 1)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		close(release)
+
+		ctx = context.Background()
+		Expect(s.AddCtx(ctx, 3)).To(Succeed())
+		Expect(s.RemoveCtx(ctx, 1)).To(Succeed())
+		Expect(s.ContainsCtx(ctx, 3)).To(BeTrue())
+		Expect(s.ContainsCtx(ctx, 1)).To(BeFalse())
+		Expect(s.Slice()).To(ConsistOf(-1, 2, 3))
+	})
+
+	It("Each snapshot", func() {
+		s := set.NewFrom([]int{1, 2, 3}, gods.WithSafe(true), gods.WithDebug(true), gods.WithSnapshot(true))
+		var seen []int
+		s.Each(func(v int) bool {
+			seen = append(seen, v)
+			s.Remove(v)
+			s.Add(v * 10)
+			return true
+		})
+		Expect(seen).To(ConsistOf(1, 2, 3))
+		Expect(s.Slice()).To(ConsistOf(10, 20, 30))
+		Expect(s.Clone().Stats()).To(Equal(gods.Stats{}))
+
+		// Each locks the set for reading only, readers are not blocked by a slow callback.
+		shared := set.NewFrom([]int{1}, gods.WithSafe(true))
+		entered, read := make(chan struct{}), make(chan struct{})
+		go shared.Each(func(v int) bool {
+			close(entered)
+			<-read
+			return true
+		})
+		<-entered
+		Expect(shared.Contains(1)).To(BeTrue())
+		close(read)
+
+		cow := set.NewFrom([]int{1, 2}, gods.WithLocker(gods.LockCopyOnWrite), gods.WithDebug(true))
+		cow.Each(func(v int) bool {
+			cow.Add(v * 10)
+			return true
+		})
+		Expect(cow.Slice()).To(ConsistOf(1, 2, 10, 20))
+	})
+})
+
+var _ = containertest.Describe(containertest.Subject[int, *set.Set[int]]{
+	Name:     "Set",
+	New:      func(safe bool) *set.Set[int] { return set.New[int](gods.WithSafe(safe)) },
+	Add:      func(s *set.Set[int], item int) { s.Add(item) },
+	Remove:   func(s *set.Set[int], item int) { s.Remove(item) },
+	Generate: func(r *rand.Rand) int { return r.Intn(32) },
+	Unique:   true,
+})
+
+// mustFind returns `item` and checks that it is found.
+func mustFind[T any](item T, found bool) T {
+	ExpectWithOffset(1, found).To(BeTrue())
+	return item
+}
+
+var _ = Describe("TreeSet", func() {
+	It("Add, Remove and Contains", func() {
+		s := set.NewTreeSet(gods.Compare[int])
+		Expect(s.IsEmpty()).To(BeTrue())
+		s.Add(3, 1, 2, 3)
+		Expect(s.Size()).To(Equal(3))
+		Expect(s.Slice()).To(Equal([]int{1, 2, 3}))
+		Expect(s.String()).To(Equal("[1 2 3]"))
+		Expect(s.Contains(2)).To(BeTrue())
+		s.Remove(2, 4)
+		Expect(s.Contains(2)).To(BeFalse())
+		Expect(s.Slice()).To(Equal([]int{1, 3}))
+
+		var items []int
+		s.Each(func(item int) bool {
+			items = append(items, item)
+			return false
+		})
+		Expect(items).To(Equal([]int{1}))
+		s.Clear()
+		Expect(s.IsEmpty()).To(BeTrue())
+	})
+
+	It("First, Last, Floor and Ceiling", func() {
+		s := set.NewTreeSetFrom([]int{10, 20, 30}, gods.Compare[int])
+		Expect(mustFind(s.First())).To(Equal(10))
+		Expect(mustFind(s.Last())).To(Equal(30))
+		Expect(mustFind(s.Floor(25))).To(Equal(20))
+		Expect(mustFind(s.Floor(20))).To(Equal(20))
+		Expect(mustFind(s.Ceiling(25))).To(Equal(30))
+		Expect(mustFind(s.Ceiling(5))).To(Equal(10))
+		_, found := s.Floor(5)
+		Expect(found).To(BeFalse())
+		_, found = s.Ceiling(35)
+		Expect(found).To(BeFalse())
+		_, found = set.NewTreeSet(gods.Compare[int]).First()
+		Expect(found).To(BeFalse())
+		Expect(func() { set.NewTreeSet(gods.Compare[int], gods.WithHooks(gods.Hooks[int]{OnInsert: func(int) {}})) }).
+			To(PanicWith("gods: set.TreeSet does not support WithHooks"))
+	})
+
+	It("PollFirst and PollLast", func() {
+		s := set.NewTreeSetFrom([]int{2, 3, 1}, gods.Compare[int], gods.WithSafe(true))
+		Expect(mustFind(s.PollFirst())).To(Equal(1))
+		Expect(mustFind(s.PollLast())).To(Equal(3))
+		Expect(mustFind(s.PollLast())).To(Equal(2))
+		_, found := s.PollFirst()
+		Expect(found).To(BeFalse())
+		_, found = s.PollLast()
+		Expect(found).To(BeFalse())
+	})
+
+	It("HeadSet, TailSet and SubSet", func() {
+		s := set.NewTreeSetFrom([]int{1, 2, 3, 4, 5}, gods.Compare[int])
+		Expect(s.HeadSet(3, false).Slice()).To(Equal([]int{1, 2}))
+		Expect(s.HeadSet(3, true).Slice()).To(Equal([]int{1, 2, 3}))
+		Expect(s.HeadSet(0, true).Slice()).To(BeEmpty())
+		Expect(s.TailSet(3, false).Slice()).To(Equal([]int{4, 5}))
+		Expect(s.TailSet(3, true).Slice()).To(Equal([]int{3, 4, 5}))
+		Expect(s.TailSet(6, true).Slice()).To(BeEmpty())
+		Expect(s.SubSet(2, true, 4, false).Slice()).To(Equal([]int{2, 3}))
+		Expect(s.SubSet(2, false, 4, true).Slice()).To(Equal([]int{3, 4}))
+		Expect(s.SubSet(0, true, 9, true).Slice()).To(Equal([]int{1, 2, 3, 4, 5}))
+		Expect(s.SubSet(4, true, 2, true).Slice()).To(BeEmpty())
+		Expect(s.SubSet(4, true, 2, true).IsEmpty()).To(BeTrue())
+	})
+
+	It("Views", func() {
+		s := set.NewTreeSetFrom([]int{10, 20, 30, 40, 50}, gods.Compare[int], gods.WithSafe(true))
+		sub := s.SubSet(20, true, 40, false)
+		Expect(sub.Size()).To(Equal(2))
+		Expect(sub.Contains(20)).To(BeTrue())
+		Expect(sub.Contains(40)).To(BeFalse())
+		Expect(sub.Contains(10)).To(BeFalse())
+		Expect(mustFind(sub.First())).To(Equal(20))
+		Expect(mustFind(sub.Last())).To(Equal(30))
+		Expect(mustFind(sub.Floor(99))).To(Equal(30))
+		Expect(mustFind(sub.Floor(25))).To(Equal(20))
+		Expect(mustFind(sub.Ceiling(0))).To(Equal(20))
+		Expect(mustFind(sub.Ceiling(25))).To(Equal(30))
+		_, found := sub.Floor(15)
+		Expect(found).To(BeFalse())
+		_, found = sub.Ceiling(35)
+		Expect(found).To(BeFalse())
+
+		s.Add(25)
+		Expect(sub.Slice()).To(Equal([]int{20, 25, 30}))
+		sub.Add(35)
+		Expect(s.Slice()).To(Equal([]int{10, 20, 25, 30, 35, 40, 50}))
+		Expect(func() { sub.Add(36, 40) }).To(PanicWith("set: 40 is out of the range of the view"))
+		Expect(s.Contains(36)).To(BeFalse())
+		sub.Remove(10, 25)
+		Expect(s.Slice()).To(Equal([]int{10, 20, 30, 35, 40, 50}))
+		Expect(json.Unmarshal([]byte("[10]"), sub)).NotTo(Succeed())
+
+		nested := sub.TailSet(30, false)
+		Expect(nested.Slice()).To(Equal([]int{35}))
+		Expect(sub.HeadSet(99, true).Slice()).To(Equal([]int{20, 30, 35}))
+		Expect(sub.TailSet(0, true).Slice()).To(Equal([]int{20, 30, 35}))
+		Expect(mustFind(nested.PollFirst())).To(Equal(35))
+		_, found = nested.PollLast()
+		Expect(found).To(BeFalse())
+		Expect(mustFind(sub.PollLast())).To(Equal(30))
+
+		clone := sub.Clone()
+		clone.Add(99)
+		Expect(clone.Slice()).To(Equal([]int{20, 99}))
+		Expect(s.Contains(99)).To(BeFalse())
+
+		Expect(json.Unmarshal([]byte("[21,22]"), sub)).To(Succeed())
+		Expect(s.Slice()).To(Equal([]int{10, 21, 22, 40, 50}))
+		sub.Clear()
+		Expect(sub.IsEmpty()).To(BeTrue())
+		Expect(s.Slice()).To(Equal([]int{10, 40, 50}))
+	})
+
+	It("Union, Diff, Intersect and IsSubsetOf", func() {
+		s1 := set.NewTreeSetFrom([]int{1, 2, 3}, gods.Compare[int])
+		s2 := set.NewTreeSetFrom([]int{3, 4}, gods.Compare[int])
+		s3 := set.NewTreeSetFrom([]int{3, 5}, gods.Compare[int])
+		Expect(s1.Union(s2, nil, s3).Slice()).To(Equal([]int{1, 2, 3, 4, 5}))
+		Expect(s1.Diff(s2, nil).Slice()).To(Equal([]int{1, 2}))
+		Expect(s1.Intersect(s2, s3).Slice()).To(Equal([]int{3}))
+		Expect(s1.Intersect(s2, nil).Slice()).To(BeEmpty())
+		Expect(s1.Intersect().Slice()).To(Equal([]int{1, 2, 3}))
+		Expect(s1.Slice()).To(Equal([]int{1, 2, 3}))
+
+		Expect(s1.Intersect(s2).IsSubsetOf(s1)).To(BeTrue())
+		Expect(s1.IsSubsetOf(s1)).To(BeTrue())
+		Expect(s1.IsSubsetOf(s2)).To(BeFalse())
+		Expect(s1.IsSubsetOf(nil)).To(BeFalse())
+		Expect(s1.Equal(s1.Clone())).To(BeTrue())
+		Expect(s1.Equal(s2)).To(BeFalse())
+		Expect(s1.Equal(s1.Union(s2))).To(BeFalse())
+		Expect(s1.Equal(nil)).To(BeFalse())
+	})
+
+	It("Comparator", func() {
+		s := set.NewTreeSetFrom([]string{"b", "c", "a"}, func(a, b string) int { return gods.Compare(b, a) })
+		Expect(s.Slice()).To(Equal([]string{"c", "b", "a"}))
+		Expect(s.HeadSet("b", true).Slice()).To(Equal([]string{"c", "b"}))
+		Expect(s.Union(set.NewTreeSetFrom([]string{"d"}, gods.Compare[string])).Slice()).To(Equal([]string{"d", "c", "b", "a"}))
+	})
+
+	It("JSON", func() {
+		s := set.NewTreeSetFrom([]int{3, 1, 2}, gods.Compare[int])
+		b, err := json.Marshal(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal("[1,2,3]"))
+		decoded := set.NewTreeSetFrom([]int{9}, gods.Compare[int])
+		Expect(json.Unmarshal([]byte("[5,4]"), decoded)).To(Succeed())
+		Expect(decoded.Slice()).To(Equal([]int{4, 5}))
+	})
+
+	It("Atomic bulk operations", func() {
+		s := set.NewTreeSet(gods.Compare[int], gods.WithSafe(true), gods.WithMetrics(true))
+		s.Add(1, 2, 3)
+		s.Remove(1, 2)
+		Expect(json.Unmarshal([]byte("[5,4]"), s)).To(Succeed())
+		Expect(s.Slice()).To(Equal([]int{4, 5}))
+		stats := s.Stats()
+		Expect(stats.Operations).To(Equal(map[string]uint64{"Add": 1, "Remove": 1, "UnmarshalJSON": 1, "Slice": 1}))
+		Expect(stats.WriteLocks).To(Equal(uint64(3)))
+	})
+})
+
+var _ = containertest.Describe(containertest.Subject[int, *set.TreeSet[int]]{
+	Name:     "TreeSet",
+	New:      func(safe bool) *set.TreeSet[int] { return set.NewTreeSet(gods.Compare[int], gods.WithSafe(safe)) },
+	Add:      func(s *set.TreeSet[int], item int) { s.Add(item) },
+	Remove:   func(s *set.TreeSet[int], item int) { s.Remove(item) },
+	Generate: func(r *rand.Rand) int { return r.Intn(32) },
+	Unique:   true,
 })
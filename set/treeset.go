@@ -0,0 +1,513 @@
+package set
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/iterator"
+	"github.com/lazybabe/gods/internal/rwmutex"
+	"github.com/lazybabe/gods/rbtree"
+)
+
+var _ gods.Collection[int] = (*TreeSet[int])(nil)
+
+// TreeSet is a sorted collection of unique members based on a red-black tree.
+// Unlike Set, it keeps the items sorted by a comparator, and looks up, adds and removes in O(log n).
+//
+// HeadSet, TailSet and SubSet return views of a range of the set, which share its items and its lock,
+// so the changes of either are visible in the other.
+type TreeSet[T any] struct {
+	// Shared by the set and its views, the tree is not concurrent-safe itself as it is guarded by mu.
+	mu         *rwmutex.RWMutex
+	tree       *rbtree.Tree[T, struct{}]
+	comparator func(a, b T) int
+	options    []gods.Option
+	// Bounds of the range of a view, which are unset for the set itself.
+	lo, hi bound[T]
+}
+
+// bound is a lower or upper bound of the range of a view.
+type bound[T any] struct {
+	item      T
+	inclusive bool
+	set       bool
+}
+
+// NewTreeSet returns an empty set of items sorted by `comparator`, which returns a negative number
+// if a < b, zero if a == b, or a positive number if a > b. See gods.Compare for the ordered types.
+// The parameter `options` is used to configure the set, see gods.Option.
+// It is not concurrent-safe in default.
+func NewTreeSet[T any](comparator func(a, b T) int, options ...gods.Option) *TreeSet[T] {
+	mu := rwmutex.CreateWithoutCOW("set.TreeSet", options)
+	return &TreeSet[T]{
+		mu:         &mu,
+		tree:       rbtree.New[T, struct{}](comparator),
+		comparator: comparator,
+		options:    options,
+	}
+}
+
+// NewTreeSetFrom returns a set of items sorted by `comparator` from `items`.
+// The parameter `options` is used to configure the set, see gods.Option.
+// It is not concurrent-safe in default.
+func NewTreeSetFrom[T any](items []T, comparator func(a, b T) int, options ...gods.Option) *TreeSet[T] {
+	s := NewTreeSet(comparator, options...)
+	s.tree.PutAll(items, struct{}{})
+	return s
+}
+
+// Each calls 'fn' on every item in the set in ascending order,
+// if `fn` returns true then continue iterating; or false to stop.
+// The set is locked for reading while `fn` runs, so `fn` must not modify the set.
+func (s *TreeSet[T]) Each(fn func(item T) bool) {
+	s.mu.Count("Each")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.ascend(fn)
+}
+
+// Add adds one or multiple items to the set atomically.
+// It panics if the set is a view and an item is out of its range, in which case no item is added.
+func (s *TreeSet[T]) Add(items ...T) {
+	s.mu.Count("Add")
+	for _, item := range items {
+		if !s.inRange(item) {
+			panic(fmt.Sprintf("set: %v is out of the range of the view", item))
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tree.PutAll(items, struct{}{})
+}
+
+// Remove deletes one or multiple items from the set atomically.
+// If the set is a view, the items out of its range are ignored.
+func (s *TreeSet[T]) Remove(items ...T) {
+	s.mu.Count("Remove")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tree.RemoveAll(s.filter(items)...)
+}
+
+// Contains checks whether the set contains `item`.
+func (s *TreeSet[T]) Contains(item T) bool {
+	s.mu.Count("Contains")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.inRange(item) && s.tree.Contains(item)
+}
+
+// Size returns the number of items in the set.
+// Note that it counts the items of a view, so it is O(k) for the k items of the view.
+func (s *TreeSet[T]) Size() int {
+	s.mu.Count("Size")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.isView() {
+		return len(s.doSliceWithoutLock())
+	}
+	return s.tree.Size()
+}
+
+// IsEmpty checks whether the set is empty.
+func (s *TreeSet[T]) IsEmpty() bool {
+	s.mu.Count("IsEmpty")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, found := s.first()
+	return !found
+}
+
+// Clear deletes all items of the set, which are the items in the range of a view.
+func (s *TreeSet[T]) Clear() {
+	s.mu.Count("Clear")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isView() {
+		s.tree.RemoveAll(s.doSliceWithoutLock()...)
+		return
+	}
+	s.tree.Clear()
+}
+
+// First returns the smallest item of the set.
+// Note that if the set is empty, the `found` is false.
+func (s *TreeSet[T]) First() (item T, found bool) {
+	s.mu.Count("First")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.first()
+}
+
+// Last returns the largest item of the set.
+// Note that if the set is empty, the `found` is false.
+func (s *TreeSet[T]) Last() (item T, found bool) {
+	s.mu.Count("Last")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.last()
+}
+
+// Floor returns the largest item less than or equal to `item`.
+// Note that if there is no such item, the `found` is false.
+func (s *TreeSet[T]) Floor(item T) (floor T, found bool) {
+	s.mu.Count("Floor")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.aboveHi(item) {
+		return s.last()
+	}
+	floor, _, found = s.tree.Floor(item)
+	if found && s.belowLo(floor) {
+		var zero T
+		return zero, false
+	}
+	return
+}
+
+// Ceiling returns the smallest item greater than or equal to `item`.
+// Note that if there is no such item, the `found` is false.
+func (s *TreeSet[T]) Ceiling(item T) (ceiling T, found bool) {
+	s.mu.Count("Ceiling")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.belowLo(item) {
+		return s.first()
+	}
+	ceiling, _, found = s.tree.Ceiling(item)
+	if found && s.aboveHi(ceiling) {
+		var zero T
+		return zero, false
+	}
+	return
+}
+
+// PollFirst removes and returns the smallest item of the set.
+// Note that if the set is empty, the `found` is false.
+func (s *TreeSet[T]) PollFirst() (item T, found bool) {
+	s.mu.Count("PollFirst")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item, found = s.first(); found {
+		s.tree.Remove(item)
+	}
+	return
+}
+
+// PollLast removes and returns the largest item of the set.
+// Note that if the set is empty, the `found` is false.
+func (s *TreeSet[T]) PollLast() (item T, found bool) {
+	s.mu.Count("PollLast")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item, found = s.last(); found {
+		s.tree.Remove(item)
+	}
+	return
+}
+
+// HeadSet returns a view of the items less than `to`, or equal to it if `inclusive` is true.
+// The view is within the range of the set if it is a view itself.
+func (s *TreeSet[T]) HeadSet(to T, inclusive bool) *TreeSet[T] {
+	return s.view(bound[T]{}, bound[T]{item: to, inclusive: inclusive, set: true})
+}
+
+// TailSet returns a view of the items greater than `from`, or equal to it if `inclusive` is true.
+// The view is within the range of the set if it is a view itself.
+func (s *TreeSet[T]) TailSet(from T, inclusive bool) *TreeSet[T] {
+	return s.view(bound[T]{item: from, inclusive: inclusive, set: true}, bound[T]{})
+}
+
+// SubSet returns a view of the items from `from` to `to`, where `fromInclusive` and `toInclusive`
+// specify whether including the items equal to `from` and `to`.
+// The view is within the range of the set if it is a view itself.
+func (s *TreeSet[T]) SubSet(from T, fromInclusive bool, to T, toInclusive bool) *TreeSet[T] {
+	return s.view(bound[T]{item: from, inclusive: fromInclusive, set: true},
+		bound[T]{item: to, inclusive: toInclusive, set: true})
+}
+
+// Iterator returns an iterator over the items of the set in ascending order,
+// which are the ones as of the moment Iterator is called.
+func (s *TreeSet[T]) Iterator() gods.Iterator[T] {
+	return iterator.New(s.Slice())
+}
+
+// ReverseIterator returns an iterator over the items of the set in descending order,
+// which are the ones as of the moment ReverseIterator is called.
+func (s *TreeSet[T]) ReverseIterator() gods.ReverseIterator[T] {
+	return iterator.NewReverse(s.Slice())
+}
+
+// Slice returns all items of the set as slice in ascending order.
+func (s *TreeSet[T]) Slice() []T {
+	s.mu.Count("Slice")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doSliceWithoutLock()
+}
+
+// String returns items as a string in ascending order.
+func (s *TreeSet[T]) String() string {
+	return fmt.Sprintf("%v", s.Slice())
+}
+
+// Stats returns a snapshot of the metrics of the set created with gods.WithMetrics(true),
+// which are shared with its views.
+func (s *TreeSet[T]) Stats() gods.Stats {
+	return s.mu.Stats()
+}
+
+// Clone returns a new set by deep copy, which is a set of the items in the range of a view.
+func (s *TreeSet[T]) Clone() *TreeSet[T] {
+	s.mu.Count("Clone")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.isView() {
+		return s.from(s.doSliceWithoutLock())
+	}
+	newSet := NewTreeSet(s.comparator, s.options...)
+	newSet.tree = s.tree.Clone()
+	return newSet
+}
+
+// Reset deletes all items of the set like Clear, it implements gods.Container.
+func (s *TreeSet[T]) Reset() {
+	s.Clear()
+}
+
+// Copy returns a new set like Clone, it implements gods.Container.
+func (s *TreeSet[T]) Copy() gods.Container[T] {
+	return s.Clone()
+}
+
+// Equal checks whether the two sets equal.
+func (s *TreeSet[T]) Equal(other *TreeSet[T]) bool {
+	if other == nil {
+		return false
+	}
+	if s == other {
+		return true
+	}
+	items := s.Slice()
+	return len(items) == other.Size() && other.containsAll(items)
+}
+
+// IsSubsetOf checks whether the current set is a sub-set of `other`.
+func (s *TreeSet[T]) IsSubsetOf(other *TreeSet[T]) bool {
+	if other == nil {
+		return false
+	}
+	if s == other {
+		return true
+	}
+	return other.containsAll(s.Slice())
+}
+
+// Union returns a new set which is the union of `set` and `other`.
+// Which means, all the items in `newSet` are in `set` or in `other`.
+func (s *TreeSet[T]) Union(others ...*TreeSet[T]) *TreeSet[T] {
+	newSet := s.Clone()
+	for _, other := range others {
+		if other != nil {
+			newSet.Add(other.Slice()...)
+		}
+	}
+	return newSet
+}
+
+// Diff returns a new set which is the difference set from `set` to `other`.
+// Which means, all the items in `newSet` are in `set` but not in `other`.
+func (s *TreeSet[T]) Diff(others ...*TreeSet[T]) *TreeSet[T] {
+	newSet := s.Clone()
+	for _, other := range others {
+		if other != nil {
+			newSet.Remove(other.Slice()...)
+		}
+	}
+	return newSet
+}
+
+// Intersect returns a new set which is the intersection from `set` to `other`.
+// Which means, all the items in `newSet` are in `set` and also in `other`.
+func (s *TreeSet[T]) Intersect(others ...*TreeSet[T]) *TreeSet[T] {
+	var items []T
+	for _, item := range s.Slice() {
+		found := true
+		for _, other := range others {
+			if other == nil || !other.Contains(item) {
+				found = false
+				break
+			}
+		}
+		if found {
+			items = append(items, item)
+		}
+	}
+	return s.from(items)
+}
+
+// MarshalJSON implements the interface MarshalJSON for json.Marshal,
+// which encodes the items as an array in ascending order.
+func (s *TreeSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Slice())
+}
+
+// UnmarshalJSON implements the interface UnmarshalJSON for json.Unmarshal,
+// which replaces all items of the set with the decoded ones atomically.
+// It fails if the set is a view and a decoded item is out of its range.
+// Note that the set must be created by NewTreeSet, as it needs the comparator.
+func (s *TreeSet[T]) UnmarshalJSON(b []byte) error {
+	var items []T
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if !s.inRange(item) {
+			return fmt.Errorf("set: %v is out of the range of the view", item)
+		}
+	}
+	s.mu.Count("UnmarshalJSON")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isView() {
+		s.tree.RemoveAll(s.doSliceWithoutLock()...)
+		s.tree.PutAll(items, struct{}{})
+		return nil
+	}
+	s.tree.ReplaceAll(items, struct{}{})
+	return nil
+}
+
+// view returns a view of the items of the set between `lo` and `hi`,
+// whose bounds are kept within the ones of the set.
+func (s *TreeSet[T]) view(lo, hi bound[T]) *TreeSet[T] {
+	v := *s
+	if lo.set && !s.belowLo(lo.item) {
+		v.lo = lo
+	}
+	if hi.set && !s.aboveHi(hi.item) {
+		v.hi = hi
+	}
+	return &v
+}
+
+// isView checks whether the set is a view of a range.
+func (s *TreeSet[T]) isView() bool {
+	return s.lo.set || s.hi.set
+}
+
+// belowLo checks whether `item` is below the lower bound of the range of the set.
+func (s *TreeSet[T]) belowLo(item T) bool {
+	if !s.lo.set {
+		return false
+	}
+	cmp := s.comparator(item, s.lo.item)
+	return cmp < 0 || cmp == 0 && !s.lo.inclusive
+}
+
+// aboveHi checks whether `item` is above the upper bound of the range of the set.
+func (s *TreeSet[T]) aboveHi(item T) bool {
+	if !s.hi.set {
+		return false
+	}
+	cmp := s.comparator(item, s.hi.item)
+	return cmp > 0 || cmp == 0 && !s.hi.inclusive
+}
+
+// inRange checks whether `item` is in the range of the set.
+func (s *TreeSet[T]) inRange(item T) bool {
+	return !s.belowLo(item) && !s.aboveHi(item)
+}
+
+// filter returns the items of `items` in the range of the set.
+func (s *TreeSet[T]) filter(items []T) []T {
+	if !s.isView() {
+		return items
+	}
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		if s.inRange(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// first returns the smallest item in the range of the set without lock.
+func (s *TreeSet[T]) first() (item T, found bool) {
+	switch {
+	case !s.lo.set:
+		item, _, found = s.tree.Min()
+	case s.lo.inclusive:
+		item, _, found = s.tree.Ceiling(s.lo.item)
+	default:
+		item, _, found = s.tree.Higher(s.lo.item)
+	}
+	if found && s.aboveHi(item) {
+		var zero T
+		return zero, false
+	}
+	return
+}
+
+// last returns the largest item in the range of the set without lock.
+func (s *TreeSet[T]) last() (item T, found bool) {
+	switch {
+	case !s.hi.set:
+		item, _, found = s.tree.Max()
+	case s.hi.inclusive:
+		item, _, found = s.tree.Floor(s.hi.item)
+	default:
+		item, _, found = s.tree.Lower(s.hi.item)
+	}
+	if found && s.belowLo(item) {
+		var zero T
+		return zero, false
+	}
+	return
+}
+
+// ascend calls `fn` on every item in the range of the set in ascending order without lock,
+// if `fn` returns true then continue iterating; or false to stop.
+func (s *TreeSet[T]) ascend(fn func(item T) bool) {
+	visit := func(item T, _ struct{}) bool {
+		if s.aboveHi(item) {
+			return false
+		}
+		return s.belowLo(item) || fn(item)
+	}
+	if s.lo.set {
+		s.tree.AscendFrom(s.lo.item, visit)
+		return
+	}
+	s.tree.Ascend(visit)
+}
+
+// doSliceWithoutLock returns the items in the range of the set in ascending order without lock.
+func (s *TreeSet[T]) doSliceWithoutLock() []T {
+	if !s.isView() {
+		return s.tree.Keys()
+	}
+	items := make([]T, 0)
+	s.ascend(func(item T) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// from returns a new set of `items` with the same configuration.
+func (s *TreeSet[T]) from(items []T) *TreeSet[T] {
+	return NewTreeSetFrom(items, s.comparator, s.options...)
+}
+
+// containsAll checks whether the set contains all of `items`.
+func (s *TreeSet[T]) containsAll(items []T) bool {
+	for _, item := range items {
+		if !s.Contains(item) {
+			return false
+		}
+	}
+	return true
+}
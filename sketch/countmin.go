@@ -0,0 +1,221 @@
+// Package sketch implements streaming summaries of the frequencies of keys in bounded memory:
+// a Count-Min sketch, which estimates the count of any key, and a Space-Saving Top-K,
+// which tracks the most frequent keys.
+package sketch
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/bloom"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// ErrIncompatible is returned when merging Count-Min sketches of different widths or depths.
+var ErrIncompatible = errors.New("incompatible sketches")
+
+// CountMin is a Count-Min sketch of keys of type T, which estimates the count of a key
+// from the counters it is added to, one in each row, by the lowest of them.
+// The estimates never underestimate the counts, and overestimate them by at most
+// epsilon times the total count with a probability of 1-delta.
+type CountMin[T any] struct {
+	mu    rwmutex.RWMutex
+	width uint64
+	depth uint32
+	// Counters of the rows one after another.
+	counters []uint64
+	total    uint64
+	hasher   bloom.Hasher[T]
+}
+
+// NewCountMin creates and returns an empty Count-Min sketch which overestimates the counts by at most
+// `epsilon` times the total count with a probability of 1-`delta`, with e/epsilon counters in each
+// of ln(1/delta) rows. The keys are hashed by `hasher`, or by bloom.Default[T] if it is nil.
+// The parameter `options` is used to configure the sketch, see gods.Option.
+// It is not concurrent-safe in default.
+func NewCountMin[T any](epsilon float64, delta float64, hasher bloom.Hasher[T], options ...gods.Option) *CountMin[T] {
+	if !(epsilon > 0) || epsilon >= 1 {
+		epsilon = 0.001
+	}
+	if !(delta > 0) || delta >= 1 {
+		delta = 0.01
+	}
+	width := math.Ceil(math.E / epsilon)
+	depth := math.Ceil(math.Log(1 / delta))
+	return NewCountMinWithSize(uint64(width), uint32(depth), hasher, options...)
+}
+
+// NewCountMinWithSize creates and returns an empty Count-Min sketch of `depth` rows of `width` counters,
+// which are at least 1. The keys are hashed by `hasher`, or by bloom.Default[T] if it is nil.
+// The parameter `options` is used to configure the sketch, see gods.Option.
+// It is not concurrent-safe in default.
+func NewCountMinWithSize[T any](width uint64, depth uint32, hasher bloom.Hasher[T],
+	options ...gods.Option) *CountMin[T] {
+	if width < 1 {
+		width = 1
+	}
+	if depth < 1 {
+		depth = 1
+	}
+	if hasher == nil {
+		hasher = bloom.Default[T]()
+	}
+	return &CountMin[T]{
+		mu:       rwmutex.CreateWithoutCOW("sketch.CountMin", options),
+		width:    width,
+		depth:    depth,
+		counters: make([]uint64, width*uint64(depth)),
+		hasher:   hasher,
+	}
+}
+
+// Width returns the number of counters in each row of the sketch.
+func (s *CountMin[T]) Width() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.width
+}
+
+// Depth returns the number of rows of the sketch.
+func (s *CountMin[T]) Depth() uint32 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.depth
+}
+
+// Add adds `count` occurrences of `key` to the sketch, which increments all the counters of `key`.
+func (s *CountMin[T]) Add(key T, count uint64) {
+	s.mu.Count("Add")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.each(s.hasher(key), func(i uint64) {
+		s.counters[i] = saturatingAdd(s.counters[i], count)
+	})
+	s.total = saturatingAdd(s.total, count)
+}
+
+// AddConservative adds `count` occurrences of `key` to the sketch with the conservative update,
+// which only raises the counters of `key` lower than its new estimate up to it.
+// It gives lower overestimates than Add, and the sketch may be updated by both.
+func (s *CountMin[T]) AddConservative(key T, count uint64) {
+	s.mu.Count("AddConservative")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := s.hasher(key)
+	estimate := saturatingAdd(s.doEstimateWithoutLock(h), count)
+	s.each(h, func(i uint64) {
+		if s.counters[i] < estimate {
+			s.counters[i] = estimate
+		}
+	})
+	s.total = saturatingAdd(s.total, count)
+}
+
+// Estimate returns the estimated count of `key`, which is not lower than the count added.
+func (s *CountMin[T]) Estimate(key T) uint64 {
+	s.mu.Count("Estimate")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doEstimateWithoutLock(s.hasher(key))
+}
+
+// doEstimateWithoutLock returns the lowest counter of the key of hash `h` without locking.
+func (s *CountMin[T]) doEstimateWithoutLock(h uint64) uint64 {
+	estimate := uint64(math.MaxUint64)
+	s.each(h, func(i uint64) {
+		if s.counters[i] < estimate {
+			estimate = s.counters[i]
+		}
+	})
+	return estimate
+}
+
+// Total returns the total count added to the sketch.
+func (s *CountMin[T]) Total() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.total
+}
+
+// Merge adds the counts added to all of `others` to the sketch, which must not be nil and have
+// the same width and depth as the sketch, or else it returns ErrIncompatible, and the sketch is not changed.
+// The hashers of the sketches must be the same.
+func (s *CountMin[T]) Merge(others ...*CountMin[T]) error {
+	s.mu.Count("Merge")
+	copies := make([]*CountMin[T], 0, len(others))
+	for _, other := range others {
+		if other == nil {
+			return ErrIncompatible
+		}
+		copies = append(copies, other.copy())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, other := range copies {
+		if other.width != s.width || other.depth != s.depth {
+			return ErrIncompatible
+		}
+	}
+	for _, other := range copies {
+		for i, counter := range other.counters {
+			s.counters[i] = saturatingAdd(s.counters[i], counter)
+		}
+		s.total = saturatingAdd(s.total, other.total)
+	}
+	return nil
+}
+
+// Clear removes all counts of the sketch.
+func (s *CountMin[T]) Clear() {
+	s.mu.Count("Clear")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.counters {
+		s.counters[i] = 0
+	}
+	s.total = 0
+}
+
+// Clone returns a new sketch, which is a copy of current sketch.
+func (s *CountMin[T]) Clone() *CountMin[T] {
+	c := s.copy()
+	c.mu = rwmutex.CreateWith(gods.NewOptions(s.mu.Options()...))
+	return c
+}
+
+// Stats returns a snapshot of the metrics of the sketch created with gods.WithMetrics(true).
+func (s *CountMin[T]) Stats() gods.Stats {
+	return s.mu.Stats()
+}
+
+// copy returns a copy of the sketch with a zero lock.
+func (s *CountMin[T]) copy() *CountMin[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &CountMin[T]{
+		width:    s.width,
+		depth:    s.depth,
+		counters: append([]uint64(nil), s.counters...),
+		total:    s.total,
+		hasher:   s.hasher,
+	}
+}
+
+// each calls `fn` on the counters of the key of hash `h`, one in each row.
+// The columns are derived from `h` by double hashing.
+func (s *CountMin[T]) each(h uint64, fn func(i uint64)) {
+	h2 := bits.RotateLeft64(h, 32) | 1
+	for row := uint64(0); row < uint64(s.depth); row++ {
+		fn(row*s.width + (h+row*h2)%s.width)
+	}
+}
+
+// saturatingAdd returns a+b, or math.MaxUint64 if it overflows.
+func saturatingAdd(a, b uint64) uint64 {
+	if sum, carry := bits.Add64(a, b, 0); carry == 0 {
+		return sum
+	}
+	return math.MaxUint64
+}
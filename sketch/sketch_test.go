@@ -0,0 +1,226 @@
+package sketch_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/sketch"
+)
+
+func TestSketch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sketch Suite")
+}
+
+// zipf returns `n` keys drawn from a Zipf distribution over [0, 10000) and their exact counts.
+func zipf(n int) ([]uint64, map[uint64]uint64) {
+	z := rand.NewZipf(rand.New(rand.NewSource(GinkgoRandomSeed())), 1.2, 1, 9999)
+	keys := make([]uint64, n)
+	counts := make(map[uint64]uint64)
+	for i := range keys {
+		keys[i] = z.Uint64()
+		counts[keys[i]]++
+	}
+	return keys, counts
+}
+
+var _ = Describe("CountMin", func() {
+	It("Size", func() {
+		s := sketch.NewCountMin[int](0.01, 0.001, nil)
+		Expect(s.Width()).To(Equal(uint64(272)))
+		Expect(s.Depth()).To(Equal(uint32(7)))
+		s = sketch.NewCountMin[int](0, 2, nil)
+		Expect(s.Width()).To(Equal(uint64(2719)))
+		Expect(s.Depth()).To(Equal(uint32(5)))
+		s = sketch.NewCountMinWithSize[int](0, 0, nil)
+		Expect(s.Width()).To(Equal(uint64(1)))
+		Expect(s.Depth()).To(Equal(uint32(1)))
+		s.Add(1, 2)
+		s.Add(2, 3)
+		Expect(s.Estimate(1)).To(Equal(uint64(5)))
+	})
+
+	DescribeTable("Estimate",
+		func(add func(s *sketch.CountMin[uint64], key uint64, count uint64)) {
+			keys, counts := zipf(100000)
+			s := sketch.NewCountMin[uint64](0.001, 0.01, nil)
+			for _, key := range keys {
+				add(s, key, 1)
+			}
+			Expect(s.Total()).To(Equal(uint64(len(keys))))
+			var over int
+			for key, count := range counts {
+				estimate := s.Estimate(key)
+				Expect(estimate).To(BeNumerically(">=", count))
+				if estimate > count+uint64(0.001*float64(len(keys))) {
+					over++
+				}
+			}
+			Expect(over).To(BeNumerically("<=", len(counts)/100+1))
+			Expect(s.Estimate(10000)).To(BeNumerically("<=", 100))
+		},
+		Entry("Add", (*sketch.CountMin[uint64]).Add),
+		Entry("AddConservative", (*sketch.CountMin[uint64]).AddConservative),
+	)
+
+	It("AddConservative", func() {
+		keys, counts := zipf(100000)
+		s, conservative := sketch.NewCountMinWithSize[uint64](200, 4, nil), sketch.NewCountMinWithSize[uint64](200, 4, nil)
+		for _, key := range keys {
+			s.Add(key, 1)
+			conservative.AddConservative(key, 1)
+		}
+		var errors, conservativeErrors uint64
+		for key, count := range counts {
+			Expect(conservative.Estimate(key)).To(BeNumerically(">=", count))
+			Expect(conservative.Estimate(key)).To(BeNumerically("<=", s.Estimate(key)))
+			errors += s.Estimate(key) - count
+			conservativeErrors += conservative.Estimate(key) - count
+		}
+		Expect(conservativeErrors).To(BeNumerically("<", errors*3/4))
+	})
+
+	It("Merge", func() {
+		a, b := sketch.NewCountMinWithSize[string](100, 3, nil), sketch.NewCountMinWithSize[string](100, 3, nil)
+		a.Add("x", 3)
+		b.Add("x", 4)
+		b.Add("y", 1)
+		Expect(a.Merge(b)).To(Succeed())
+		Expect(a.Estimate("x")).To(BeNumerically(">=", 7))
+		Expect(a.Estimate("y")).To(BeNumerically(">=", 1))
+		Expect(a.Total()).To(Equal(uint64(8)))
+		Expect(b.Total()).To(Equal(uint64(5)))
+
+		Expect(a.Merge(b, sketch.NewCountMinWithSize[string](100, 4, nil))).To(MatchError(sketch.ErrIncompatible))
+		Expect(a.Merge(nil)).To(MatchError(sketch.ErrIncompatible))
+		Expect(a.Total()).To(Equal(uint64(8)))
+		Expect(a.Merge(a)).To(Succeed())
+		Expect(a.Total()).To(Equal(uint64(16)))
+
+		c := a.Clone()
+		c.Clear()
+		Expect(c.Estimate("x")).To(BeZero())
+		Expect(a.Estimate("x")).To(BeNumerically(">=", 14))
+	})
+
+	It("Saturation", func() {
+		s := sketch.NewCountMinWithSize[int](10, 2, nil)
+		s.Add(1, 1<<63)
+		s.Add(1, 1<<63)
+		s.AddConservative(1, 1)
+		Expect(s.Estimate(1)).To(Equal(^uint64(0)))
+		Expect(s.Total()).To(Equal(^uint64(0)))
+	})
+
+	It("Safe", func() {
+		Expect(func() { sketch.NewCountMin[int](0.01, 0.01, nil, gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: sketch.CountMin does not support LockCopyOnWrite"))
+		Expect(func() { sketch.NewTopK[int](10, gods.WithCapacity(10)) }).
+			To(PanicWith("gods: sketch.TopK does not support WithCapacity"))
+		s := sketch.NewCountMin[int](0.01, 0.01, nil, gods.WithSafe(true), gods.WithMetrics(true),
+			gods.WithLocker(gods.LockMutex))
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					s.AddConservative(i%10, 1)
+					s.Estimate(i % 10)
+				}
+			}()
+		}
+		wg.Wait()
+		Expect(s.Total()).To(Equal(uint64(4000)))
+		Expect(s.Estimate(3)).To(BeNumerically(">=", 400))
+		Expect(s.Stats().Operations).To(HaveKeyWithValue("AddConservative", uint64(4000)))
+		Expect(s.Stats().WriteLocks).To(Equal(uint64(4000)))
+	})
+})
+
+var _ = Describe("TopK", func() {
+	It("Add, Get and List", func() {
+		t := sketch.NewTopK[string](3)
+		Expect(t.K()).To(Equal(3))
+		Expect(t.List().Size()).To(BeZero())
+		t.Add("a", 5)
+		t.Add("b", 3)
+		t.Add("c", 1)
+		t.Add("b", 1)
+		Expect(t.Size()).To(Equal(3))
+		Expect(t.List().Slice()).To(Equal([]sketch.Item[string]{
+			{Key: "a", Count: 5}, {Key: "b", Count: 4}, {Key: "c", Count: 1},
+		}))
+
+		// "d" replaces "c", which has the lowest count.
+		t.Add("d", 2)
+		_, found := t.Get("c")
+		Expect(found).To(BeFalse())
+		item, found := t.Get("d")
+		Expect(found).To(BeTrue())
+		Expect(item).To(Equal(sketch.Item[string]{Key: "d", Count: 3, Error: 1}))
+		Expect(t.Total()).To(Equal(uint64(12)))
+		Expect(t.List().Slice()).To(Equal([]sketch.Item[string]{
+			{Key: "a", Count: 5}, {Key: "b", Count: 4}, {Key: "d", Count: 3, Error: 1},
+		}))
+
+		t.Clear()
+		Expect(t.Size()).To(BeZero())
+		Expect(t.Total()).To(BeZero())
+		Expect(sketch.NewTopK[int](0).K()).To(Equal(1))
+	})
+
+	It("Heavy hitters", func() {
+		keys, counts := zipf(100000)
+		t := sketch.NewTopK[uint64](100)
+		for _, key := range keys {
+			t.Add(key, 1)
+		}
+		list := t.List()
+		Expect(list.Size()).To(Equal(100))
+		var sum uint64
+		list.Each(func(i int, item sketch.Item[uint64]) bool {
+			Expect(item.Count).To(BeNumerically(">=", counts[item.Key]))
+			Expect(item.Count - item.Error).To(BeNumerically("<=", counts[item.Key]))
+			if i > 0 {
+				Expect(item.Count).To(BeNumerically("<=", list.Index(i-1).Count))
+			}
+			sum += item.Count
+			return true
+		})
+		Expect(sum).To(Equal(uint64(len(keys))))
+		// Every key above total/k is tracked.
+		for key, count := range counts {
+			if count > uint64(len(keys)/100) {
+				_, found := t.Get(key)
+				Expect(found).To(BeTrue(), fmt.Sprint(key))
+			}
+		}
+		Expect(list.Index(0).Key).To(Equal(uint64(0)))
+	})
+
+	It("Safe", func() {
+		t := sketch.NewTopK[int](5, gods.WithSafe(true), gods.WithMetrics(true))
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					t.Add(i%3, 1)
+					t.List()
+				}
+			}()
+		}
+		wg.Wait()
+		Expect(t.Size()).To(Equal(3))
+		Expect(t.Total()).To(Equal(uint64(4000)))
+		Expect(t.Stats().Operations).To(HaveKeyWithValue("Add", uint64(4000)))
+	})
+})
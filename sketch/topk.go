@@ -0,0 +1,171 @@
+package sketch
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/internal/rwmutex"
+)
+
+// Item is a key tracked by a Top-K with its estimated count.
+type Item[T comparable] struct {
+	Key T
+	// Count is the estimated count of the key, which is not lower than the count added.
+	Count uint64
+	// Error is the most the count may be overestimated by, so the count added is at least Count-Error.
+	Error uint64
+}
+
+// counter is an item tracked by a Top-K, the `index` is its position in the heap.
+type counter[T comparable] struct {
+	Item[T]
+	index int
+}
+
+// TopK tracks the k most frequent keys of type T with the Space-Saving algorithm in memory of k items.
+//
+// Every key with a count above the total count divided by k is tracked, and a key added when
+// k keys are already tracked replaces the one with the lowest count, taking over its count as its error.
+type TopK[T comparable] struct {
+	mu       rwmutex.RWMutex
+	k        int
+	counters map[T]*counter[T]
+	heap     counterHeap[T]
+	total    uint64
+}
+
+// NewTopK creates and returns an empty Top-K tracking `k` keys, which is at least 1.
+// The parameter `options` is used to configure the Top-K, see gods.Option.
+// It is not concurrent-safe in default.
+func NewTopK[T comparable](k int, options ...gods.Option) *TopK[T] {
+	if k < 1 {
+		k = 1
+	}
+	return &TopK[T]{
+		mu:       rwmutex.CreateWithoutCOW("sketch.TopK", options),
+		k:        k,
+		counters: make(map[T]*counter[T], k),
+		heap:     make(counterHeap[T], 0, k),
+	}
+}
+
+// K returns the number of keys the Top-K tracks at most.
+func (t *TopK[T]) K() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.k
+}
+
+// Size returns the number of keys tracked.
+func (t *TopK[T]) Size() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.heap)
+}
+
+// Total returns the total count added to the Top-K.
+func (t *TopK[T]) Total() uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.total
+}
+
+// Add adds `count` occurrences of `key` to the Top-K.
+func (t *TopK[T]) Add(key T, count uint64) {
+	t.mu.Count("Add")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total = saturatingAdd(t.total, count)
+	if c, ok := t.counters[key]; ok {
+		c.Count = saturatingAdd(c.Count, count)
+		heap.Fix(&t.heap, c.index)
+		return
+	}
+	if len(t.heap) < t.k {
+		c := &counter[T]{Item: Item[T]{Key: key, Count: count}}
+		t.counters[key] = c
+		heap.Push(&t.heap, c)
+		return
+	}
+	// Replace the key with the lowest count, which is at the top of the heap.
+	c := t.heap[0]
+	delete(t.counters, c.Key)
+	c.Key, c.Count, c.Error = key, saturatingAdd(c.Count, count), c.Count
+	t.counters[key] = c
+	heap.Fix(&t.heap, 0)
+}
+
+// Get returns the item of `key`.
+// Note that if `key` is not tracked, the `found` is false.
+func (t *TopK[T]) Get(key T) (item Item[T], found bool) {
+	t.mu.Count("Get")
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if c, ok := t.counters[key]; ok {
+		return c.Item, true
+	}
+	return
+}
+
+// List returns the items tracked in descending order of their counts, then ascending order of their errors.
+func (t *TopK[T]) List() *array.Array[Item[T]] {
+	t.mu.Count("List")
+	t.mu.RLock()
+	items := make([]Item[T], len(t.heap))
+	for i, c := range t.heap {
+		items[i] = c.Item
+	}
+	t.mu.RUnlock()
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Error < items[j].Error
+	})
+	return array.NewFrom(items)
+}
+
+// Clear removes all keys of the Top-K.
+func (t *TopK[T]) Clear() {
+	t.mu.Count("Clear")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counters = make(map[T]*counter[T], t.k)
+	t.heap = make(counterHeap[T], 0, t.k)
+	t.total = 0
+}
+
+// Stats returns a snapshot of the metrics of the Top-K created with gods.WithMetrics(true).
+func (t *TopK[T]) Stats() gods.Stats {
+	return t.mu.Stats()
+}
+
+// counterHeap is a min-heap of the counters ordered by their counts, it implements heap.Interface.
+type counterHeap[T comparable] []*counter[T]
+
+func (h counterHeap[T]) Len() int { return len(h) }
+
+func (h counterHeap[T]) Less(i, j int) bool { return h[i].Count < h[j].Count }
+
+func (h counterHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *counterHeap[T]) Push(x any) {
+	c := x.(*counter[T])
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *counterHeap[T]) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	old[len(old)-1] = nil
+	c.index = -1
+	*h = old[:len(old)-1]
+	return c
+}
@@ -1,28 +1,38 @@
 package stack
 
 import (
+	"context"
+	"encoding/json"
+
+	"github.com/lazybabe/gods"
 	"github.com/lazybabe/gods/array"
+	"github.com/lazybabe/gods/internal/rwmutex"
 )
 
+var _ gods.Collection[int] = (*Stack[int])(nil)
+
+// Stack is a last-in-first-out stack of items of type T, which supports all the options, see gods.Option.
 type Stack[T comparable] struct {
 	data *array.Array[T]
 }
 
 // New creates and returns an empty stack.
-// The parameter `safe` is used to specify whether using array in concurrent-safety,
-// which is false in default.
-func New[T comparable](safe ...bool) *Stack[T] {
+// The parameter `options` is used to configure the stack, see gods.Option.
+// It also takes the former `safe` bool, which is deprecated in favor of gods.WithSafe.
+// It is not concurrent-safe in default.
+func New[T comparable](options ...any) *Stack[T] {
 	return &Stack[T]{
-		data: array.New[T](safe...),
+		data: array.New[T](rwmutex.Any(rwmutex.OptionsOf("stack.Stack", options))...),
 	}
 }
 
 // NewFrom creates and returns a stack, and push the elements of `data` at the top of the stack one by one.
-// The parameter `safe` is used to specify whether using array in concurrent-safety,
-// which is false in default.
-func NewFrom[T comparable](data []T, safe ...bool) *Stack[T] {
+// The parameter `options` is used to configure the stack, see gods.Option.
+// It also takes the former `safe` bool, which is deprecated in favor of gods.WithSafe.
+// It is not concurrent-safe in default.
+func NewFrom[T comparable](data []T, options ...any) *Stack[T] {
 	return &Stack[T]{
-		data: array.NewFrom(data, safe...),
+		data: array.NewFrom(data, rwmutex.Any(rwmutex.OptionsOf("stack.Stack", options))...),
 	}
 }
 
@@ -31,6 +41,11 @@ func (s *Stack[T]) Push(value T) {
 	s.data.PushRight(value)
 }
 
+// PushCtx is like Push, but it returns ctx.Err() if `ctx` is done before the stack is locked.
+func (s *Stack[T]) PushCtx(ctx context.Context, value T) error {
+	return s.data.PushRightCtx(ctx, value)
+}
+
 // Pop removes the stack's top element and returns it.
 // If the stack is empty it returns the zero value.
 func (s *Stack[T]) Pop() T {
@@ -38,6 +53,12 @@ func (s *Stack[T]) Pop() T {
 	return value
 }
 
+// PopCtx is like Pop, but it returns ctx.Err() if `ctx` is done before the stack is locked.
+func (s *Stack[T]) PopCtx(ctx context.Context) (T, error) {
+	value, _, err := s.data.PopRightCtx(ctx)
+	return value, err
+}
+
 // Peek returns the stack's top element but does not remove it.
 // If the stack is empty the zero value is returned.
 func (s *Stack[T]) Peek() (t T) {
@@ -56,7 +77,77 @@ func (s *Stack[T]) Clone() *Stack[T] {
 	}
 }
 
+// Stats returns a snapshot of the metrics of the stack created with gods.WithMetrics(true),
+// whose operations are the ones of its underlying array.
+func (s *Stack[T]) Stats() gods.Stats {
+	return s.data.Stats()
+}
+
 // IsEmpty returns true if the stack is empty, otherwise returns false.
 func (s *Stack[T]) IsEmpty() bool {
 	return s.data.Size() == 0
 }
+
+// Clear removes all elements of the stack.
+func (s *Stack[T]) Clear() {
+	s.data.Clear()
+}
+
+// Reset deletes all items of the stack like Clear, it implements gods.Container.
+func (s *Stack[T]) Reset() {
+	s.Clear()
+}
+
+// Copy returns a new stack like Clone, it implements gods.Container.
+func (s *Stack[T]) Copy() gods.Container[T] {
+	return s.Clone()
+}
+
+// Slice returns the elements of the stack from the bottom to the top.
+func (s *Stack[T]) Slice() []T {
+	return s.data.Slice()
+}
+
+// Contains checks whether `value` is in the stack.
+func (s *Stack[T]) Contains(value T) bool {
+	return s.data.Contains(value)
+}
+
+// Iterator returns an iterator over the elements of the stack from the top to the bottom,
+// which is the order they are popped, and they are the ones as of the moment Iterator is called.
+func (s *Stack[T]) Iterator() gods.Iterator[T] {
+	return &popOrder[T]{s.data.ReverseIterator()}
+}
+
+// String returns the elements of the stack from the bottom to the top as a string.
+func (s *Stack[T]) String() string {
+	return s.data.String()
+}
+
+// MarshalJSON implements the interface MarshalJSON for json.Marshal,
+// which encodes the elements from the bottom to the top.
+func (s *Stack[T]) MarshalJSON() ([]byte, error) {
+	if s.data == nil {
+		return json.Marshal([]T{})
+	}
+	return json.Marshal(s.data)
+}
+
+// UnmarshalJSON implements the interface UnmarshalJSON for json.Unmarshal,
+// which replaces all elements of the stack with the decoded ones from the bottom to the top.
+func (s *Stack[T]) UnmarshalJSON(b []byte) error {
+	if s.data == nil {
+		s.data = array.New[T]()
+	}
+	return json.Unmarshal(b, s.data)
+}
+
+// popOrder is an Iterator moving backwards over the elements from the top to the bottom.
+type popOrder[T any] struct {
+	gods.ReverseIterator[T]
+}
+
+// Next moves to the element below the current one, and reports whether there is one.
+func (it *popOrder[T]) Next() bool {
+	return it.Prev()
+}
@@ -1,11 +1,15 @@
 package stack_test
 
 import (
+	"context"
+	"math/rand"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/containertest"
 	"github.com/lazybabe/gods/stack"
 )
 
@@ -55,4 +59,48 @@ var _ = Describe("Stack", func() {
 		s.Push(1)
 		Expect(s.IsEmpty()).To(BeFalse())
 	})
+
+	It("Locker", func() {
+		s := stack.NewFrom([]int{1, 2, 3}, gods.WithLocker(gods.LockCopyOnWrite))
+		s.Push(4)
+		Expect(s.Peek()).To(Equal(4))
+		Expect(s.Clone().Pop()).To(Equal(4))
+		Expect(s.Size()).To(Equal(4))
+		Expect(stack.New[int](gods.WithLocker(gods.LockSpin)).IsEmpty()).To(BeTrue())
+	})
+
+	It("Options", func() {
+		var pushed []int
+		s := stack.New[int](gods.WithSafe(true), gods.WithHooks(gods.Hooks[int]{
+			OnInsert: func(v int) { pushed = append(pushed, v) },
+		}))
+		s.Push(1)
+		s.Push(2)
+		Expect(pushed).To(Equal([]int{1, 2}))
+	})
+
+	It("Stats", func() {
+		s := stack.New[int](gods.WithMetrics(true))
+		s.Push(1)
+		s.Pop()
+		Expect(s.Stats().Operations).To(Equal(map[string]uint64{"PushRight": 1, "PopRight": 1}))
+	})
+
+	It("Ctx", func() {
+		s := stack.New[int](gods.WithSafe(true))
+		ctx, cancel := context.WithCancel(context.Background())
+		Expect(s.PushCtx(ctx, 1)).To(Succeed())
+		Expect(s.PopCtx(ctx)).To(Equal(1))
+		Expect(s.PopCtx(ctx)).To(BeZero())
+		cancel()
+		Expect(s.PushCtx(ctx, 2)).To(Succeed())
+	})
+})
+
+var _ = containertest.Describe(containertest.Subject[string, *stack.Stack[string]]{
+	Name:     "Stack",
+	New:      func(safe bool) *stack.Stack[string] { return stack.New[string](gods.WithSafe(safe)) },
+	Add:      func(s *stack.Stack[string], item string) { s.Push(item) },
+	Generate: func(r *rand.Rand) string { return string(rune('a' + r.Intn(26))) },
+	Ordered:  true,
 })
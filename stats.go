@@ -0,0 +1,28 @@
+package gods
+
+import (
+	"time"
+)
+
+// Stats is a snapshot of the metrics of a container created with WithMetrics(true).
+// The metrics of a container created without are all zero.
+type Stats struct {
+	// ReadLocks and WriteLocks are the number of times the lock was acquired for reading and writing.
+	ReadLocks  uint64
+	WriteLocks uint64
+	// ReadWait and WriteWait are the total time spent waiting to acquire the lock.
+	ReadWait  time.Duration
+	WriteWait time.Duration
+	// ReadHold is the total time the lock was held by at least one reader,
+	// WriteHold is the total time the lock was held by writers.
+	ReadHold  time.Duration
+	WriteHold time.Duration
+	// Operations are the number of calls of every primitive operation of the container by name.
+	// Convenience methods count as the primitive operations they call.
+	Operations map[string]uint64
+}
+
+// StatsProvider is a container reporting its metrics.
+type StatsProvider interface {
+	Stats() Stats
+}
@@ -0,0 +1,199 @@
+// Package unionfind implements a disjoint-set structure, which partitions items into components
+// and merges them in nearly constant amortized time.
+package unionfind
+
+import (
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/internal/rwmutex"
+	"github.com/lazybabe/gods/set"
+)
+
+// UF is a disjoint-set structure of items of type T, which keeps every item in one component,
+// with path compression and union by rank.
+//
+// Every item starts in a component of its own, and components are merged by Union.
+type UF[T comparable] struct {
+	mu rwmutex.RWMutex
+	// Positions of the items in the slices below.
+	index map[T]int
+	// Items in the order they are added.
+	items []T
+	// Position of the parent of every item, which is the item itself for the root of a component.
+	parent []int
+	// Upper bound of the height of the tree of every root.
+	rank       []uint8
+	components int
+}
+
+// New creates and returns an empty structure.
+// The parameter `options` is used to configure the structure, see gods.Option.
+// It is not concurrent-safe in default.
+//
+// The structure supports gods.WithCapacity.
+func New[T comparable](options ...gods.Option) *UF[T] {
+	o := gods.NewOptions(options...)
+	o.Check("unionfind.UF", gods.FeatureCapacity)
+	return &UF[T]{
+		mu:     rwmutex.CreateWith(o),
+		index:  make(map[T]int, o.Capacity),
+		items:  make([]T, 0, o.Capacity),
+		parent: make([]int, 0, o.Capacity),
+		rank:   make([]uint8, 0, o.Capacity),
+	}
+}
+
+// Add adds one or multiple items to the structure, each in a component of its own,
+// unless it is already in the structure.
+func (u *UF[T]) Add(items ...T) {
+	u.mu.Count("Add")
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, item := range items {
+		u.add(item)
+	}
+}
+
+// Contains checks whether `item` is in the structure.
+func (u *UF[T]) Contains(item T) bool {
+	u.mu.Count("Contains")
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	_, ok := u.index[item]
+	return ok
+}
+
+// Find returns the representative item of the component of `item`,
+// which is the same for all the items in the component until it is merged.
+// Note that if `item` is not in the structure, the `found` is false.
+//
+// It compresses the path from `item` to the representative, so it locks the structure for writing.
+func (u *UF[T]) Find(item T) (root T, found bool) {
+	u.mu.Count("Find")
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	i, ok := u.index[item]
+	if !ok {
+		return
+	}
+	return u.items[u.find(i)], true
+}
+
+// Union merges the components of `a` and `b`, which are added to the structure first
+// if they are not in it, and reports whether they are merged.
+// It returns false if they are already in the same component.
+func (u *UF[T]) Union(a, b T) bool {
+	u.mu.Count("Union")
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	ra, rb := u.find(u.add(a)), u.find(u.add(b))
+	if ra == rb {
+		return false
+	}
+	// Attach the lower tree under the higher one, so the trees stay logarithmic in height.
+	switch {
+	case u.rank[ra] < u.rank[rb]:
+		u.parent[ra] = rb
+	case u.rank[ra] > u.rank[rb]:
+		u.parent[rb] = ra
+	default:
+		u.parent[rb] = ra
+		u.rank[ra]++
+	}
+	u.components--
+	return true
+}
+
+// Connected checks whether `a` and `b` are in the same component.
+// It returns false if any of them is not in the structure.
+func (u *UF[T]) Connected(a, b T) bool {
+	u.mu.Count("Connected")
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	i, ok := u.index[a]
+	if !ok {
+		return false
+	}
+	j, ok := u.index[b]
+	if !ok {
+		return false
+	}
+	return u.find(i) == u.find(j)
+}
+
+// Size returns the number of items in the structure.
+func (u *UF[T]) Size() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return len(u.items)
+}
+
+// ComponentCount returns the number of components.
+func (u *UF[T]) ComponentCount() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.components
+}
+
+// Components returns the components as sets, in the order their first items are added.
+// The parameter `options` is used to configure the sets, see gods.Option.
+func (u *UF[T]) Components(options ...gods.Option) []*set.Set[T] {
+	u.mu.Count("Components")
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	components := make([]*set.Set[T], 0, u.components)
+	// Positions of the components of the roots in `components`.
+	positions := make(map[int]int, u.components)
+	for i, item := range u.items {
+		root := u.find(i)
+		p, ok := positions[root]
+		if !ok {
+			p = len(components)
+			positions[root] = p
+			components = append(components, set.New[T](rwmutex.Any(options)...))
+		}
+		components[p].Add(item)
+	}
+	return components
+}
+
+// Clear removes all items of the structure.
+func (u *UF[T]) Clear() {
+	u.mu.Count("Clear")
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.index = make(map[T]int)
+	u.items, u.parent, u.rank = nil, nil, nil
+	u.components = 0
+}
+
+// Stats returns a snapshot of the metrics of the structure created with gods.WithMetrics(true).
+func (u *UF[T]) Stats() gods.Stats {
+	return u.mu.Stats()
+}
+
+// add adds `item` in a component of its own if it is not in the structure, and returns its position.
+func (u *UF[T]) add(item T) int {
+	if i, ok := u.index[item]; ok {
+		return i
+	}
+	i := len(u.items)
+	u.index[item] = i
+	u.items = append(u.items, item)
+	u.parent = append(u.parent, i)
+	u.rank = append(u.rank, 0)
+	u.components++
+	return i
+}
+
+// find returns the position of the root of the item at position `i`, and points every item
+// on the path to the root directly at it.
+func (u *UF[T]) find(i int) int {
+	root := i
+	for u.parent[root] != root {
+		root = u.parent[root]
+	}
+	for u.parent[i] != root {
+		u.parent[i], i = root, u.parent[i]
+	}
+	return root
+}
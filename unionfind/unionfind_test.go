@@ -0,0 +1,154 @@
+package unionfind_test
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lazybabe/gods"
+	"github.com/lazybabe/gods/set"
+	"github.com/lazybabe/gods/unionfind"
+)
+
+func TestUnionFind(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "UnionFind Suite")
+}
+
+// slices returns the items of the sets sorted.
+func slices(sets []*set.Set[int]) [][]int {
+	s := make([][]int, len(sets))
+	for i := range sets {
+		s[i] = set.NewTreeSetFrom(sets[i].Slice(), gods.Compare[int]).Slice()
+	}
+	return s
+}
+
+// mustFind returns `root` and checks that it is found.
+func mustFind(root int, found bool) int {
+	ExpectWithOffset(1, found).To(BeTrue())
+	return root
+}
+
+var _ = Describe("UF", func() {
+	It("Union, Find and Connected", func() {
+		u := unionfind.New[int]()
+		u.Add(1, 2, 3, 4, 5)
+		u.Add(1)
+		Expect(u.Size()).To(Equal(5))
+		Expect(u.ComponentCount()).To(Equal(5))
+		Expect(u.Contains(3)).To(BeTrue())
+		Expect(u.Contains(6)).To(BeFalse())
+
+		Expect(u.Union(1, 2)).To(BeTrue())
+		Expect(u.Union(3, 4)).To(BeTrue())
+		Expect(u.Union(2, 1)).To(BeFalse())
+		Expect(u.ComponentCount()).To(Equal(3))
+		Expect(u.Connected(1, 2)).To(BeTrue())
+		Expect(u.Connected(1, 3)).To(BeFalse())
+		Expect(u.Connected(1, 6)).To(BeFalse())
+		Expect(u.Connected(6, 6)).To(BeFalse())
+		Expect(u.Connected(5, 5)).To(BeTrue())
+
+		Expect(u.Union(2, 4)).To(BeTrue())
+		Expect(u.Connected(1, 3)).To(BeTrue())
+		root, found := u.Find(4)
+		Expect(found).To(BeTrue())
+		for _, i := range []int{1, 2, 3} {
+			Expect(mustFind(u.Find(i))).To(Equal(root))
+		}
+		_, found = u.Find(6)
+		Expect(found).To(BeFalse())
+
+		// Union adds the items not in the structure.
+		Expect(u.Union(6, 7)).To(BeTrue())
+		Expect(u.Size()).To(Equal(7))
+		Expect(u.ComponentCount()).To(Equal(3))
+		Expect(slices(u.Components())).To(Equal([][]int{{1, 2, 3, 4}, {5}, {6, 7}}))
+
+		u.Clear()
+		Expect(u.Size()).To(BeZero())
+		Expect(u.ComponentCount()).To(BeZero())
+		Expect(u.Components()).To(BeEmpty())
+		Expect(u.Union(1, 1)).To(BeFalse())
+		Expect(u.ComponentCount()).To(Equal(1))
+	})
+
+	It("Components", func() {
+		u := unionfind.New[string](gods.WithCapacity(4))
+		u.Union("b", "c")
+		u.Add("a")
+		u.Union("d", "b")
+		components := u.Components(gods.WithSafe(true))
+		Expect(components).To(HaveLen(2))
+		Expect(components[0].Equal(set.NewFrom([]string{"b", "c", "d"}))).To(BeTrue())
+		Expect(components[1].Equal(set.NewFrom([]string{"a"}))).To(BeTrue())
+	})
+
+	It("matches the components of a naive model after random unions", func() {
+		r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+		u := unionfind.New[int]()
+		// Label of the component of every item in the model.
+		label := make([]int, 1000)
+		for i := range label {
+			label[i] = i
+		}
+		u.Add(r.Perm(1000)...)
+		for n := 0; n < 900; n++ {
+			a, b := r.Intn(1000), r.Intn(1000)
+			Expect(u.Union(a, b)).To(Equal(label[a] != label[b]))
+			if from, to := label[b], label[a]; from != to {
+				for i := range label {
+					if label[i] == from {
+						label[i] = to
+					}
+				}
+			}
+			c, d := r.Intn(1000), r.Intn(1000)
+			Expect(u.Connected(c, d)).To(Equal(label[c] == label[d]))
+		}
+		labels := make(map[int]bool)
+		for _, l := range label {
+			labels[l] = true
+		}
+		Expect(u.ComponentCount()).To(Equal(len(labels)))
+		var total int
+		for _, c := range u.Components() {
+			total += c.Size()
+			var first int
+			c.Each(func(item int) bool {
+				first = item
+				return false
+			})
+			c.Each(func(item int) bool {
+				Expect(label[item]).To(Equal(label[first]))
+				return true
+			})
+		}
+		Expect(total).To(Equal(1000))
+	})
+
+	It("Safe", func() {
+		Expect(func() { unionfind.New[int](gods.WithLocker(gods.LockCopyOnWrite)) }).
+			To(PanicWith("gods: unionfind.UF does not support LockCopyOnWrite"))
+		u := unionfind.New[int](gods.WithSafe(true), gods.WithMetrics(true), gods.WithLocker(gods.LockSpin))
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					u.Union(i, i+1)
+					u.Connected(0, i)
+				}
+			}(w)
+		}
+		wg.Wait()
+		Expect(u.ComponentCount()).To(Equal(1))
+		Expect(u.Connected(0, 1000)).To(BeTrue())
+		Expect(u.Stats().Operations).To(HaveKeyWithValue("Union", uint64(4000)))
+	})
+})